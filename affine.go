@@ -0,0 +1,86 @@
+package gm
+
+import (
+	"errors"
+	"math"
+
+	"github.com/golang/geo/r2"
+)
+
+// An affine is an affine transformation of the plane, mapping (x, y) to (a*x + b*y + e, c*x + d*y + f).
+type affine struct {
+	a, b, c, d, e, f float64
+}
+
+// identity is the identity transformation.
+var identity = affine{a: 1, d: 1}
+
+var errInvalidTransform = errors.New("gm: output transformation is not finite and invertible")
+
+// isValid reports whether t is finite and invertible.
+func (t affine) isValid() bool {
+	for _, v := range []float64{t.a, t.b, t.c, t.d, t.e, t.f} {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return false
+		}
+	}
+	return t.a*t.d-t.b*t.c != 0
+}
+
+// apply returns the image of p under t. The projections of the poles, whose y coordinates are infinite,
+// map to points at infinity in the direction of the transformed y axis.
+func (t affine) apply(p r2.Point) r2.Point {
+	if t == identity {
+		return p
+	}
+	return r2.Point{
+		X: mul(t.a, p.X) + mul(t.b, p.Y) + t.e,
+		Y: mul(t.c, p.X) + mul(t.d, p.Y) + t.f,
+	}
+}
+
+// invert returns the point whose image under t is p.
+// A point with an infinite coordinate maps to (0, ±Inf) according to the sign of its inverse y coordinate.
+func (t affine) invert(p r2.Point) r2.Point {
+	if t == identity {
+		return p
+	}
+	det := t.a*t.d - t.b*t.c
+	if math.IsInf(p.X, 0) || math.IsInf(p.Y, 0) {
+		// Only the direction of a point at infinity is significant.
+		x, y := infSign(p.X), infSign(p.Y)
+		if (t.a*y-t.c*x)/det < 0 {
+			return r2.Point{Y: math.Inf(-1)}
+		}
+		return r2.Point{Y: math.Inf(1)}
+	}
+	x, y := p.X-t.e, p.Y-t.f
+	return r2.Point{
+		X: (t.d*x - t.b*y) / det,
+		Y: (t.a*y - t.c*x) / det,
+	}
+}
+
+// mul returns a*v, or 0 if a is 0, even if v is infinite.
+func mul(a, v float64) float64 {
+	if a == 0 {
+		return 0
+	}
+	return a * v
+}
+
+// infSign returns 1 if v is +Inf, -1 if v is -Inf, and 0 otherwise.
+func infSign(v float64) float64 {
+	switch {
+	case math.IsInf(v, 1):
+		return 1
+	case math.IsInf(v, -1):
+		return -1
+	}
+	return 0
+}
+
+// applyLinear returns the image of the vector v under the linear part of t, omitting the translation.
+func (t affine) applyLinear(v r2.Point) r2.Point {
+	return r2.Point{X: t.a*v.X + t.b*v.Y, Y: t.c*v.X + t.d*v.Y}
+}
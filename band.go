@@ -0,0 +1,73 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A Band is the region of the reference sphere within a given generalized latitude of the generalized equator:
+// the locations with |ψ| <= ψmax, which project to the horizontal strip of the map between the lines at ψmax
+// and -ψmax. It is bounded by two curves of constant generalized latitude, so it is the intersection of two caps.
+// It implements s2.Region, so that it can be used in s2 queries, such as to select the data that a projection
+// truncated by WithTruncation can display.
+type Band struct {
+	psiMax s1.Angle
+
+	// lower contains the locations with ψ >= -ψmax, and upper those with ψ <= ψmax.
+	lower, upper s2.Cap
+}
+
+// Band returns the region of locations whose generalized latitudes are between -psiMax and psiMax inclusive.
+// The band is empty if psiMax is negative, and it is the whole sphere if psiMax is at least π/2.
+func (gm *GeneralizedMercator) Band(psiMax s1.Angle) *Band {
+	switch {
+	case psiMax < 0:
+		return &Band{psiMax: psiMax, lower: s2.EmptyCap(), upper: s2.EmptyCap()}
+	case psiMax >= math.Pi/2:
+		return &Band{psiMax: psiMax, lower: s2.FullCap(), upper: s2.FullCap()}
+	}
+	return &Band{
+		psiMax: psiMax,
+		lower:  gm.parallelCap(-float64(psiMax)),
+		upper:  gm.parallelCap(float64(psiMax)).Complement(),
+	}
+}
+
+// PsiMax returns the greatest generalized latitude of the locations in b.
+func (b *Band) PsiMax() s1.Angle { return b.psiMax }
+
+// ContainsPoint reports whether b contains p.
+func (b *Band) ContainsPoint(p s2.Point) bool {
+	return b.lower.ContainsPoint(p) && b.upper.ContainsPoint(p)
+}
+
+// ContainsCell reports whether b contains the cell c.
+func (b *Band) ContainsCell(c s2.Cell) bool {
+	return b.lower.ContainsCell(c) && b.upper.ContainsCell(c)
+}
+
+// IntersectsCell reports whether b might intersect the cell c. It is conservative:
+// it may report an intersection for a cell that intersects both caps that bound b but not b itself.
+func (b *Band) IntersectsCell(c s2.Cell) bool {
+	return b.lower.IntersectsCell(c) && b.upper.IntersectsCell(c)
+}
+
+// CapBound returns a cap that contains b: the smaller of the two caps whose intersection is b.
+func (b *Band) CapBound() s2.Cap {
+	if b.upper.Radius() < b.lower.Radius() {
+		return b.upper
+	}
+	return b.lower
+}
+
+// RectBound returns a latitude-longitude rectangle that contains b.
+func (b *Band) RectBound() s2.Rect {
+	return b.lower.RectBound().Intersection(b.upper.RectBound())
+}
+
+// CellUnionBound returns a small collection of cells whose union contains b.
+func (b *Band) CellUnionBound() []s2.CellID {
+	return b.CapBound().CellUnionBound()
+}
@@ -0,0 +1,47 @@
+package gm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestBand(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, gm := range []*GeneralizedMercator{
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+		New(s2.LatLngFromDegrees(30, 40), s2.LatLngFromDegrees(-30, -140)),
+		New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150)),
+	} {
+		for _, psiMax := range []s1.Angle{-0.1, 0.3, 1.2, pi / 2} {
+			b := gm.Band(psiMax)
+			rect, c := b.RectBound(), b.CapBound()
+			covering := (&s2.RegionCoverer{MaxLevel: 10, MaxCells: 50}).Covering(b)
+			interior := (&s2.RegionCoverer{MaxLevel: 10, MaxCells: 50}).InteriorCovering(b)
+			for n := 0; n < 1000; n++ {
+				p := s2.Point{randomPoint(rnd).Normalize()}
+				_, psi := gm.generalized(p.Vector)
+				if math.Abs(math.Abs(psi)-float64(psiMax)) < 1e-9 {
+					continue
+				}
+				want := math.Abs(psi) <= float64(psiMax)
+				if got := b.ContainsPoint(p); got != want {
+					t.Errorf("ContainsPoint(%v, %v, %v): got %v, want %v", gm, psiMax, p, got, want)
+				}
+				switch {
+				case want && !rect.ContainsPoint(p):
+					t.Errorf("RectBound(%v, %v): got %v, not containing %v", gm, psiMax, rect, p)
+				case want && !c.ContainsPoint(p):
+					t.Errorf("CapBound(%v, %v): got %v, not containing %v", gm, psiMax, c, p)
+				case want && !covering.ContainsPoint(p):
+					t.Errorf("Covering(%v, %v): got %v, not containing %v", gm, psiMax, covering, p)
+				case !want && interior.ContainsPoint(p):
+					t.Errorf("InteriorCovering(%v, %v): got %v, containing %v", gm, psiMax, interior, p)
+				}
+			}
+		}
+	}
+}
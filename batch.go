@@ -0,0 +1,216 @@
+package gm
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// ProjectAppend appends the projections of the locations in src to dst and returns the extended slice.
+// If dst has sufficient capacity, ProjectAppend does not allocate.
+func (gm *GeneralizedMercator) ProjectAppend(dst []r2.Point, src []s2.LatLng) []r2.Point {
+	for _, ll := range src {
+		dst = append(dst, gm.Project(ll))
+	}
+	return dst
+}
+
+// UnprojectAppend appends the locations on the reference sphere of the projected points in src to dst
+// and returns the extended slice. If dst has sufficient capacity, UnprojectAppend does not allocate.
+func (gm *GeneralizedMercator) UnprojectAppend(dst []s2.LatLng, src []r2.Point) []s2.LatLng {
+	for _, p := range src {
+		dst = append(dst, gm.Unproject(p))
+	}
+	return dst
+}
+
+// UnprojectRow appends to dst the locations on the reference sphere of the projected points (x, y) for each x in xs,
+// in order, and returns the extended slice. It is equivalent to UnprojectAppend, but since the points share
+// a y coordinate, as the pixels in a row of a raster image do, it computes the quantities that depend only on y
+// once for the whole row. If dst has sufficient capacity, UnprojectRow does not allocate.
+func (gm *GeneralizedMercator) UnprojectRow(dst []s2.LatLng, y float64, xs []float64) []s2.LatLng {
+	row := gm.row(y)
+	for _, x := range xs {
+		dst = append(dst, row.unproject(x))
+	}
+	return dst
+}
+
+// A row unprojects projected points with a common y coordinate,
+// computing the quantities that depend only on y once for all of them.
+type row struct {
+	gm *GeneralizedMercator
+
+	// y is the common y coordinate, and ok reports whether it determines the generalized latitude of the points,
+	// which yy and pl describe. Otherwise, unproject falls back to Unproject.
+	y  float64
+	ok bool
+
+	// yy is y under the inverse output transformation, and pl is the parallel of the points.
+	yy float64
+	pl parallel
+}
+
+// row returns the row of projected points with y coordinate y.
+func (gm *GeneralizedMercator) row(y float64) row {
+	if gm.out.c != 0 || math.IsInf(y, 0) {
+		// The generalized latitude varies along the row, or the row is at a pole.
+		return row{gm: gm, y: y}
+	}
+	// With gm.out.c == 0, the inverse output transformation maps y to (y-f)/d independently of x.
+	yy := (y - gm.out.f) / gm.out.d
+	return row{gm: gm, y: y, ok: true, yy: yy, pl: gm.parallel(psiFromY(yy / (gm.radius * gm.k0)))}
+}
+
+// unproject returns the location on the reference sphere of the projected point (x, r.y).
+func (r row) unproject(x float64) s2.LatLng {
+	gm := r.gm
+	if !r.ok {
+		return gm.Unproject(r2.Point{X: x, Y: r.y})
+	}
+	xx := (x - gm.out.e - gm.out.b*r.yy) / gm.out.a
+	return gm.locationOn(r.pl, xx/(gm.radius*gm.k0)+gm.x0)
+}
+
+// ProjectVec projects the locations described by the parallel slices lat and lng, in radians,
+// storing the projected coordinates in the parallel slices outX and outY.
+// It panics if the slices do not all have the same length.
+func (gm *GeneralizedMercator) ProjectVec(lat, lng, outX, outY []float64) {
+	if len(lng) != len(lat) || len(outX) != len(lat) || len(outY) != len(lat) {
+		panic("mismatched slice lengths")
+	}
+	for n := range lat {
+		p := gm.Project(s2.LatLng{Lat: s1.Angle(lat[n]), Lng: s1.Angle(lng[n])})
+		outX[n], outY[n] = p.X, p.Y
+	}
+}
+
+// UnprojectVec unprojects the points described by the parallel slices x and y,
+// storing the latitudes and longitudes, in radians, in the parallel slices outLat and outLng.
+// It panics if the slices do not all have the same length.
+func (gm *GeneralizedMercator) UnprojectVec(x, y, outLat, outLng []float64) {
+	if len(y) != len(x) || len(outLat) != len(x) || len(outLng) != len(x) {
+		panic("mismatched slice lengths")
+	}
+	// Points are commonly arranged in rows of a raster, so consecutive points with the same y coordinate
+	// share the quantities that depend only on y.
+	var r row
+	for n := range x {
+		if n == 0 || y[n] != r.y {
+			r = gm.row(y[n])
+		}
+		ll := r.unproject(x[n])
+		outLat[n], outLng[n] = float64(ll.Lat), float64(ll.Lng)
+	}
+}
+
+// ProjectParallel stores the projections of the locations in src in the corresponding elements of dst,
+// dividing the work among workers goroutines, or runtime.GOMAXPROCS(0) of them if workers is not positive.
+// It returns ctx.Err() if ctx is done before the work is finished, in which case some elements of dst
+// may not have been written. ProjectParallel panics if dst and src do not have the same length.
+func (gm *GeneralizedMercator) ProjectParallel(ctx context.Context, dst []r2.Point, src []s2.LatLng, workers int) error {
+	if len(dst) != len(src) {
+		panic("mismatched slice lengths")
+	}
+	return runChunks(ctx, len(src), workers, func(lo, hi int) {
+		for n := lo; n < hi; n++ {
+			dst[n] = gm.Project(src[n])
+		}
+	})
+}
+
+// UnprojectParallel stores the locations on the reference sphere of the projected points in src in the corresponding
+// elements of dst, dividing the work among workers goroutines, or runtime.GOMAXPROCS(0) of them if workers is not positive.
+// It returns ctx.Err() if ctx is done before the work is finished, in which case some elements of dst
+// may not have been written. UnprojectParallel panics if dst and src do not have the same length.
+func (gm *GeneralizedMercator) UnprojectParallel(ctx context.Context, dst []s2.LatLng, src []r2.Point, workers int) error {
+	if len(dst) != len(src) {
+		panic("mismatched slice lengths")
+	}
+	return runChunks(ctx, len(src), workers, func(lo, hi int) {
+		for n := lo; n < hi; n++ {
+			dst[n] = gm.Unproject(src[n])
+		}
+	})
+}
+
+// chunkSize is the number of elements that a worker of ProjectParallel or UnprojectParallel processes
+// between checks for cancellation.
+const chunkSize = 4096
+
+// runChunks calls f for consecutive chunks [lo, hi) of the indices from 0 to n, distributing them among workers goroutines,
+// or runtime.GOMAXPROCS(0) of them if workers is not positive, until all are done or ctx is done.
+// It returns ctx.Err() if any chunk was skipped because ctx was done.
+func runChunks(ctx context.Context, n, workers int, f func(lo, hi int)) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunks := (n + chunkSize - 1) / chunkSize
+	if workers > chunks {
+		workers = chunks
+	}
+	var (
+		next int64
+		wg   sync.WaitGroup
+	)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				c := int(atomic.AddInt64(&next, 1) - 1)
+				if c >= chunks {
+					return
+				}
+				lo := c * chunkSize
+				hi := lo + chunkSize
+				if hi > n {
+					hi = n
+				}
+				f(lo, hi)
+			}
+		}()
+	}
+	wg.Wait()
+	if atomic.LoadInt64(&next) < int64(chunks) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// A LatLnger is a location that can report its latitude and longitude in radians.
+// Domain types that implement LatLnger can be projected by ProjectLatLnger without conversion to s2.LatLng.
+type LatLnger interface {
+	Lat() float64
+	Lng() float64
+}
+
+// ProjectLatLnger converts the location v to a projected 2D point.
+func (gm *GeneralizedMercator) ProjectLatLnger(v LatLnger) r2.Point {
+	return gm.Project(s2.LatLng{Lat: s1.Angle(v.Lat()), Lng: s1.Angle(v.Lng())})
+}
+
+// LatLngSource is an indexed collection of locations, such as a slice of a domain type,
+// whose latitudes and longitudes in radians can be read without building a slice of s2.LatLng.
+type LatLngSource interface {
+	// Len returns the number of locations in the collection.
+	Len() int
+	// LatLng returns the latitude and longitude in radians of the location with index n.
+	LatLng(n int) (lat, lng float64)
+}
+
+// ProjectSource appends the projections of the locations in src, in order, to dst and returns the extended slice.
+// If dst has sufficient capacity, ProjectSource does not allocate.
+func (gm *GeneralizedMercator) ProjectSource(dst []r2.Point, src LatLngSource) []r2.Point {
+	for n, l := 0, src.Len(); n < l; n++ {
+		lat, lng := src.LatLng(n)
+		dst = append(dst, gm.Project(s2.LatLng{Lat: s1.Angle(lat), Lng: s1.Angle(lng)}))
+	}
+	return dst
+}
@@ -0,0 +1,270 @@
+package gm
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestProjectAppend(t *testing.T) {
+	for _, test := range projTests {
+		src := make([]s2.LatLng, len(test.ps))
+		for n, p := range test.ps {
+			src[n] = p.s
+		}
+		prefix := r2.Point{X: 7, Y: 7}
+		got := test.gm.ProjectAppend([]r2.Point{prefix}, src)
+		if len(got) != len(src)+1 || got[0] != prefix {
+			t.Fatalf("ProjectAppend(%+v): got %v", test.gm, got)
+		}
+		for n, p := range test.ps {
+			if !ptApproxEqual(got[n+1], p.r) {
+				t.Errorf("ProjectAppend(%+v)[%d]: got %+v, want %+v", test.gm, n, got[n+1], p.r)
+			}
+		}
+	}
+}
+
+func TestUnprojectAppend(t *testing.T) {
+	for _, test := range projTests {
+		src := make([]r2.Point, len(test.ps))
+		for n, p := range test.ps {
+			src[n] = p.r
+		}
+		got := test.gm.UnprojectAppend(nil, src)
+		if len(got) != len(src) {
+			t.Fatalf("UnprojectAppend(%+v): got %v", test.gm, got)
+		}
+		for n, p := range test.ps {
+			if !llApproxEqual(got[n], p.s) {
+				t.Errorf("UnprojectAppend(%+v)[%d]: got %+v, want %+v", test.gm, n, got[n], p.s)
+			}
+		}
+	}
+}
+
+func TestAppendAllocs(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0))
+	lls := make([]s2.LatLng, 100)
+	for n := range lls {
+		lls[n] = s2.LatLngFromDegrees(float64(n)-50, float64(3*n)-150)
+	}
+	pts := make([]r2.Point, 0, len(lls))
+	out := make([]s2.LatLng, 0, len(lls))
+	if allocs := testing.AllocsPerRun(10, func() { pts = gm.ProjectAppend(pts[:0], lls) }); allocs != 0 {
+		t.Errorf("ProjectAppend: got %v allocations, want 0", allocs)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { out = gm.UnprojectAppend(out[:0], pts) }); allocs != 0 {
+		t.Errorf("UnprojectAppend: got %v allocations, want 0", allocs)
+	}
+}
+
+func TestUnprojectRow(t *testing.T) {
+	xs := make([]float64, 25)
+	for n := range xs {
+		xs[n] = 0.3*float64(n) - 3.6
+	}
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{
+			test.gm,
+			New(pos, neg, WithRadius(2), WithCentralLongitude(0.5), WithSeam(1)),
+			New(pos, neg, WithGeodeticLatitude(WGS84Flattening), WithAffine(2, 0.5, 0, 3, 1, -1)),
+			New(pos, neg, WithAffine(0, -1, 1, 0, 2, 3)),
+		} {
+			for _, y := range []float64{math.Inf(-1), -2, 0, 0.7, 3, math.Inf(1)} {
+				prefix := s2.LatLng{Lat: 1, Lng: 1}
+				got := gm.UnprojectRow([]s2.LatLng{prefix}, y, xs)
+				if len(got) != len(xs)+1 || got[0] != prefix {
+					t.Fatalf("UnprojectRow(%v, %v): got %v", gm, y, got)
+				}
+				for n, x := range xs {
+					if want := gm.Unproject(r2.Point{X: x, Y: y}); !llApproxEqual(got[n+1], want) {
+						t.Errorf("UnprojectRow(%v, %v)[%d]: got %v, want %v", gm, y, n, got[n+1], want)
+					}
+				}
+			}
+		}
+	}
+
+	gm := New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0))
+	dst := make([]s2.LatLng, 0, len(xs))
+	if allocs := testing.AllocsPerRun(10, func() { dst = gm.UnprojectRow(dst[:0], 0.5, xs) }); allocs != 0 {
+		t.Errorf("UnprojectRow: got %v allocations, want 0", allocs)
+	}
+}
+
+func BenchmarkUnprojectRow(b *testing.B) {
+	gm := New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0))
+	xs := make([]float64, 1024)
+	for n := range xs {
+		xs[n] = 2 * math.Pi * (float64(n)/1024 - 0.5)
+	}
+	dst := make([]s2.LatLng, 0, len(xs))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dst = gm.UnprojectRow(dst[:0], 0.5, xs)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0), WithRadius(2))
+	lls := make([]s2.LatLng, 3*chunkSize+100)
+	for n := range lls {
+		lls[n] = s2.LatLngFromDegrees(float64(n%179)-89, float64(n%359)-179)
+	}
+	for _, workers := range []int{0, 1, 3, 100} {
+		pts := make([]r2.Point, len(lls))
+		if err := gm.ProjectParallel(context.Background(), pts, lls, workers); err != nil {
+			t.Fatalf("ProjectParallel(%v workers): %v", workers, err)
+		}
+		for n, ll := range lls {
+			if want := gm.Project(ll); pts[n] != want {
+				t.Fatalf("ProjectParallel(%v workers)[%d]: got %v, want %v", workers, n, pts[n], want)
+			}
+		}
+		out := make([]s2.LatLng, len(pts))
+		if err := gm.UnprojectParallel(context.Background(), out, pts, workers); err != nil {
+			t.Fatalf("UnprojectParallel(%v workers): %v", workers, err)
+		}
+		for n, p := range pts {
+			if want := gm.Unproject(p); out[n] != want {
+				t.Fatalf("UnprojectParallel(%v workers)[%d]: got %v, want %v", workers, n, out[n], want)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := gm.ProjectParallel(ctx, make([]r2.Point, len(lls)), lls, 2); err != context.Canceled {
+		t.Errorf("ProjectParallel with a canceled context: got %v, want %v", err, context.Canceled)
+	}
+	if err := gm.ProjectParallel(ctx, nil, nil, 2); err != nil {
+		t.Errorf("ProjectParallel with no locations: got %v, want nil", err)
+	}
+}
+
+func TestProjectVec(t *testing.T) {
+	for _, test := range projTests {
+		n := len(test.ps)
+		lat, lng, x, y := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+		for m, p := range test.ps {
+			lat[m], lng[m] = float64(p.s.Lat), float64(p.s.Lng)
+		}
+		test.gm.ProjectVec(lat, lng, x, y)
+		for m, p := range test.ps {
+			if got := (r2.Point{X: x[m], Y: y[m]}); !ptApproxEqual(got, p.r) {
+				t.Errorf("ProjectVec(%+v)[%d]: got %+v, want %+v", test.gm, m, got, p.r)
+			}
+		}
+	}
+}
+
+func TestUnprojectVec(t *testing.T) {
+	for _, test := range projTests {
+		n := len(test.ps)
+		x, y, lat, lng := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+		for m, p := range test.ps {
+			x[m], y[m] = p.r.X, p.r.Y
+		}
+		test.gm.UnprojectVec(x, y, lat, lng)
+		for m, p := range test.ps {
+			if got := (s2.LatLng{Lat: s1.Angle(lat[m]), Lng: s1.Angle(lng[m])}); !llApproxEqual(got, p.s) {
+				t.Errorf("UnprojectVec(%+v)[%d]: got %+v, want %+v", test.gm, m, got, p.s)
+			}
+		}
+	}
+}
+
+func TestUnprojectVecRows(t *testing.T) {
+	var x, y []float64
+	for _, yy := range []float64{math.Inf(1), 2, 2, 0.5, math.Inf(-1)} {
+		for xx := -3.0; xx <= 3; xx += 0.5 {
+			x, y = append(x, xx), append(y, yy)
+		}
+	}
+	lat, lng := make([]float64, len(x)), make([]float64, len(x))
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{
+			test.gm,
+			New(pos, neg, WithGeodeticLatitude(WGS84Flattening), WithAffine(2, 0.5, 0, 3, 1, -1)),
+			New(pos, neg, WithAffine(0, -1, 1, 0, 2, 3)),
+		} {
+			gm.UnprojectVec(x, y, lat, lng)
+			for n := range x {
+				p := r2.Point{X: x[n], Y: y[n]}
+				if got, want := (s2.LatLng{Lat: s1.Angle(lat[n]), Lng: s1.Angle(lng[n])}), gm.Unproject(p); !llApproxEqual(got, want) {
+					t.Errorf("UnprojectVec(%v)[%d]: got %v, want %v", gm, n, got, want)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkUnprojectVec(b *testing.B) {
+	gm := New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0))
+	const w, h = 64, 64
+	x, y, lat, lng := make([]float64, w*h), make([]float64, w*h), make([]float64, w*h), make([]float64, w*h)
+	for n := range x {
+		x[n], y[n] = 2*math.Pi*(float64(n%w)/w-0.5), 4*(float64(n/w)/h-0.5)
+	}
+	b.Run("rows", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			gm.UnprojectVec(x, y, lat, lng)
+		}
+	})
+	// Unprojecting each point on its own is the baseline that sharing the work of each row improves on.
+	b.Run("points", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for n := range x {
+				ll := gm.Unproject(r2.Point{X: x[n], Y: y[n]})
+				lat[n], lng[n] = float64(ll.Lat), float64(ll.Lng)
+			}
+		}
+	})
+}
+
+// station is a domain type that implements LatLnger.
+type station struct {
+	name     string
+	lat, lng float64
+}
+
+func (s station) Lat() float64 { return s.lat }
+func (s station) Lng() float64 { return s.lng }
+
+// stations implements LatLngSource.
+type stations []station
+
+func (s stations) Len() int                        { return len(s) }
+func (s stations) LatLng(n int) (lat, lng float64) { return s[n].lat, s[n].lng }
+
+func TestProjectLatLnger(t *testing.T) {
+	for _, test := range projTests {
+		var src stations
+		for n, p := range test.ps {
+			src = append(src, station{string(rune('a' + n)), float64(p.s.Lat), float64(p.s.Lng)})
+		}
+		for n, s := range src {
+			if got, want := test.gm.ProjectLatLnger(s), test.gm.Project(test.ps[n].s); got != want {
+				t.Errorf("ProjectLatLnger(%+v, %v): got %v, want %v", test.gm, s, got, want)
+			}
+		}
+		got := test.gm.ProjectSource(nil, src)
+		if len(got) != len(src) {
+			t.Fatalf("ProjectSource(%+v): got %v", test.gm, got)
+		}
+		for n, p := range test.ps {
+			if want := test.gm.Project(p.s); got[n] != want {
+				t.Errorf("ProjectSource(%+v)[%d]: got %v, want %v", test.gm, n, got[n], want)
+			}
+		}
+	}
+}
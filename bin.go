@@ -0,0 +1,144 @@
+package gm
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r1"
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A BinShape is the shape of the bins of a Binner.
+type BinShape int
+
+const (
+	// SquareBins are squares whose sides are the bin size, aligned with the projected axes.
+	SquareBins BinShape = iota
+
+	// HexBins are regular hexagons with a vertex at the top, whose circumradius is the bin size.
+	HexBins
+)
+
+// A Bin is a bin of a Binner and the locations that have been added to it.
+type Bin struct {
+	// Col and Row identify the bin. For hexagonal bins, they are axial coordinates:
+	// each row of hexagons is offset by half a hexagon to the right of the row below it.
+	Col, Row int
+
+	// Count is the number of locations in the bin, and Weight is the sum of their weights.
+	Count  int
+	Weight float64
+}
+
+// A Binner aggregates locations on the reference sphere into the bins of a square or hexagonal grid
+// in the plane, for drawing density maps. Since the projection distorts area, the number of locations in a bin
+// reflects the density of the locations on the sphere only after dividing it by the true area of the bin,
+// which Area reports.
+//
+// The grid extends across the plane from the origin. Bins that straddle an edge of the map are counted
+// as separate bins at each edge, and their areas include only their parts within the map.
+type Binner struct {
+	gm    *GeneralizedMercator
+	shape BinShape
+	size  float64
+	bins  map[[2]int]*Bin
+}
+
+// NewBinner returns an empty Binner with bins of the given shape and size, in projected units.
+// It panics if size is not positive and finite.
+func (gm *GeneralizedMercator) NewBinner(shape BinShape, size float64) *Binner {
+	if !(size > 0) || math.IsInf(size, 1) {
+		panic("gm: invalid bin size")
+	}
+	return &Binner{gm: gm, shape: shape, size: size, bins: make(map[[2]int]*Bin)}
+}
+
+// Add adds p with the given weight to the bin containing its projection, and reports whether it did so.
+// A pole, which projects to infinity, is in no bin.
+func (b *Binner) Add(p s2.Point, weight float64) bool {
+	q := b.gm.ProjectPoint(p)
+	if !isFinite(q) {
+		return false
+	}
+	col, row := b.Locate(q)
+	bin := b.bins[[2]int{col, row}]
+	if bin == nil {
+		bin = &Bin{Col: col, Row: row}
+		b.bins[[2]int{col, row}] = bin
+	}
+	bin.Count++
+	bin.Weight += weight
+	return true
+}
+
+// Bins returns the bins to which locations have been added, in order of increasing Row and then Col.
+func (b *Binner) Bins() []Bin {
+	bins := make([]Bin, 0, len(b.bins))
+	for _, bin := range b.bins {
+		bins = append(bins, *bin)
+	}
+	sort.Slice(bins, func(i, j int) bool {
+		if bins[i].Row != bins[j].Row {
+			return bins[i].Row < bins[j].Row
+		}
+		return bins[i].Col < bins[j].Col
+	})
+	return bins
+}
+
+// Locate returns the column and row of the bin that contains the projected point p.
+func (b *Binner) Locate(p r2.Point) (col, row int) {
+	if b.shape == SquareBins {
+		return int(math.Floor(p.X / b.size)), int(math.Floor(p.Y / b.size))
+	}
+	// Convert to fractional axial coordinates and round to the nearest hexagon in cube coordinates.
+	q := (math.Sqrt(3)/3*p.X - p.Y/3) / b.size
+	r := 2.0 / 3 * p.Y / b.size
+	s := -q - r
+	rq, rr, rs := math.Round(q), math.Round(r), math.Round(s)
+	switch dq, dr, ds := math.Abs(rq-q), math.Abs(rr-r), math.Abs(rs-s); {
+	case dq > dr && dq > ds:
+		rq = -rr - rs
+	case dr > ds:
+		rr = -rq - rs
+	}
+	return int(rq), int(rr)
+}
+
+// Outline returns the vertices of the bin at col and row in the plane, counterclockwise.
+func (b *Binner) Outline(col, row int) []r2.Point {
+	if b.shape == SquareBins {
+		x, y := float64(col)*b.size, float64(row)*b.size
+		return []r2.Point{{X: x, Y: y}, {X: x + b.size, Y: y}, {X: x + b.size, Y: y + b.size}, {X: x, Y: y + b.size}}
+	}
+	c := r2.Point{X: b.size * math.Sqrt(3) * (float64(col) + float64(row)/2), Y: b.size * 1.5 * float64(row)}
+	pts := make([]r2.Point, 6)
+	for n := range pts {
+		sin, cos := math.Sincos(math.Pi/6 + float64(n)*math.Pi/3)
+		pts[n] = r2.Point{X: c.X + b.size*cos, Y: c.Y + b.size*sin}
+	}
+	return pts
+}
+
+// Area returns the true area on the reference sphere of the part of the bin at col and row within the map,
+// as by RingsArea with the given tolerance.
+func (b *Binner) Area(col, row int, tolerance s1.Angle) float64 {
+	gm := b.gm
+	ring := b.Outline(col, row)
+	e := math.Pi * gm.radius * gm.k0
+	for n, p := range ring {
+		p = gm.out.invert(p)
+		p.X -= gm.cut * gm.radius * gm.k0
+		ring[n] = p
+	}
+	ring = clipRing(ring, r2.Rect{X: r1.Interval{Lo: -e, Hi: e}, Y: r1.Interval{Lo: math.Inf(-1), Hi: math.Inf(1)}})
+	if len(ring) < 3 {
+		return 0
+	}
+	for n, p := range ring {
+		ring[n] = gm.fromCentered(p)
+	}
+	return math.Abs(gm.RingsArea([][]r2.Point{ring}, tolerance))
+}
@@ -0,0 +1,72 @@
+package gm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s2"
+)
+
+func TestBinnerLocate(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	rnd := rand.New(rand.NewSource(1))
+	for _, shape := range []BinShape{SquareBins, HexBins} {
+		b := gm.NewBinner(shape, 0.3)
+		for n := 0; n < 1000; n++ {
+			p := r2.Point{X: 6*rnd.Float64() - 3, Y: 6*rnd.Float64() - 3}
+			col, row := b.Locate(p)
+			if w := winding([][]r2.Point{b.Outline(col, row)}, p); w != 1 {
+				t.Errorf("Locate(%v, %v): got bin (%d, %d) with outline %v", shape, p, col, row, b.Outline(col, row))
+			}
+		}
+	}
+}
+
+func TestBinner(t *testing.T) {
+	const tolerance = 1e-7
+	rnd := rand.New(rand.NewSource(1))
+	for _, gm := range []*GeneralizedMercator{
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+		New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150), WithSeam(2), WithAffine(0, -2, 2, 0, 5, 1)),
+	} {
+		for _, shape := range []BinShape{SquareBins, HexBins} {
+			// The areas of the bins that cover the strip |y| <= maxY before any output transformation sum to its area.
+			b := gm.NewBinner(shape, 1)
+			var want float64
+			for n := 0; n < 20000; n++ {
+				p := s2.Point{randomPoint(rnd).Normalize()}
+				if !b.Add(p, 2) {
+					t.Errorf("Add(%v): got false", p)
+				}
+				want += 2
+			}
+			if b.Add(s2.Point{gm.pos}, 1) {
+				t.Errorf("Add(%v): got true for a pole", gm.pos)
+			}
+			var count int
+			var weight, area float64
+			for _, bin := range b.Bins() {
+				count += bin.Count
+				weight += bin.Weight
+				area += b.Area(bin.Col, bin.Row, tolerance)
+			}
+			if count != 20000 || weight != want {
+				t.Errorf("Bins(%v, %v): got count %d and weight %v, want 20000 and %v", gm, shape, count, weight, want)
+			}
+			// The bins with points cover nearly the whole sphere, since the points are uniformly distributed.
+			if area > 4*pi*(1+1e-6) || area < 3.9*pi {
+				t.Errorf("Bins(%v, %v): got total area %v, want about 4π", gm, shape, area)
+			}
+			// The density of uniformly distributed points in a bin, divided by its true area, is nearly uniform.
+			for _, bin := range b.Bins() {
+				if a := b.Area(bin.Col, bin.Row, tolerance); a > 0.2 {
+					if d := float64(bin.Count) / a / (20000 / (4 * pi)); math.Abs(d-1) > 0.25 {
+						t.Errorf("Bins(%v, %v): got density %v in bin (%d, %d) with area %v", gm, shape, d, bin.Col, bin.Row, a)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// Buffer returns the projection of the region of locations within distance of shape on the reference sphere,
+// measured along the sphere in the units of its radius, such as meters for a projection constructed
+// with WithRadius(6371008.8). Unlike a buffer of constant width in the plane, the projected buffer widens
+// away from the generalized equator as the scale of the projection increases.
+//
+// The buffer is returned as rings like those of ProjectPolygon, cut along the seam and with projected
+// y coordinates clamped to [-maxY, maxY], but the rings may overlap: the buffer consists of the points with
+// a positive winding number, which is the nonzero fill rule of most renderers and of RingsContainPoint.
+// It is the union of the caps of radius distance around the vertices of shape, the bands of locations
+// within distance of its edges that lie beside them, and the interior of shape if its dimension is 2.
+// The boundaries of the caps and bands are approximated to within maxErr, which must be positive,
+// measured as an angle on the reference sphere, and their projected edges deviate from the projections
+// of the corresponding geodesics by at most maxErr.
+//
+// Buffer panics if distance is negative or is not less than a quarter of the circumference of the reference sphere.
+func (gm *GeneralizedMercator) Buffer(shape s2.Shape, distance float64, maxErr s1.Angle, maxY float64) [][]r2.Point {
+	d := distance / gm.radius
+	if !(d >= 0 && d < math.Pi/2) {
+		panic("gm: invalid buffer distance")
+	}
+	var rings [][]r2.Point
+	if shape.Dimension() == 2 {
+		var chains [][]s2.Point
+		for c := 0; c < shape.NumChains(); c++ {
+			chain := shape.Chain(c)
+			if chain.Length == 0 {
+				continue
+			}
+			var ring []s2.Point
+			for e := 0; e < chain.Length; e++ {
+				edge := shape.ChainEdge(c, e)
+				ring = gm.densifyEdge(ring, edge.V0, edge.V1, maxErr)
+			}
+			chains = append(chains, ring)
+		}
+		if len(chains) > 0 || shape.ReferencePoint().Contained {
+			index := s2.NewShapeIndex()
+			index.Add(shape)
+			query := s2.NewContainsPointQuery(index, s2.VertexModelSemiOpen)
+			rings = append(rings, gm.projectRings(chains, query.Contains, maxY)...)
+		}
+	}
+	if d == 0 {
+		return gm.outRings(rings, 0)
+	}
+
+	// A regular polygon inscribed in a cap of radius d with n vertices is within d(1 - cos(π/n)) of its boundary.
+	n := 4
+	if r := 1 - float64(maxErr)/d; r > 0 {
+		n = int(math.Max(4, math.Ceil(math.Pi/math.Acos(r))))
+	}
+	for e := 0; e < shape.NumEdges(); e++ {
+		edge := shape.Edge(e)
+		c := s2.CapFromCenterAngle(edge.V0, s1.Angle(d))
+		rings = append(rings, gm.projectRings([][]s2.Point{gm.densifyRing(s2.RegularLoop(edge.V0, s1.Angle(d), n).Vertices(), maxErr)}, c.ContainsPoint, maxY)...)
+		if shape.Dimension() == 0 || edge.V0 == edge.V1 {
+			continue
+		}
+		if shape.Dimension() == 1 {
+			// The last vertex of each chain of a polyline begins no edge.
+			if ce := shape.ChainPosition(e); ce.Offset == shape.Chain(ce.ChainID).Length-1 {
+				c := s2.CapFromCenterAngle(edge.V1, s1.Angle(d))
+				rings = append(rings, gm.projectRings([][]s2.Point{gm.densifyRing(s2.RegularLoop(edge.V1, s1.Angle(d), n).Vertices(), maxErr)}, c.ContainsPoint, maxY)...)
+			}
+		}
+		ring, contains := edgeBand(edge.V0, edge.V1, d, maxErr)
+		rings = append(rings, gm.projectRings([][]s2.Point{gm.densifyRing(ring, maxErr)}, contains, maxY)...)
+	}
+	return gm.outRings(rings, 0)
+}
+
+// edgeBand returns a counterclockwise ring that approximates to within maxErr the boundary of the band
+// of locations within d of the geodesic edge from a to b whose nearest points on the edge's great circle
+// lie on the edge, and a function that reports whether a location is in the band.
+func edgeBand(a, b s2.Point, d float64, maxErr s1.Angle) ([]s2.Point, func(s2.Point) bool) {
+	n := s2.Point{a.PointCross(b).Normalize()}
+	sin, cos := math.Sincos(d)
+	// The sides of the band are the circles at distance d from the great circle on either side.
+	side := func(sign float64) func(t float64) s2.Point {
+		return func(t float64) s2.Point {
+			return s2.Point{s2.Interpolate(t, a, b).Mul(cos).Add(n.Mul(sign * sin)).Normalize()}
+		}
+	}
+	right := tessellate(side(-1), 0, 1, maxErr)
+	left := tessellate(side(1), 0, 1, maxErr)
+	ring := append([]s2.Point(nil), right...)
+	for k := len(left) - 1; k >= 0; k-- {
+		ring = append(ring, left[k])
+	}
+	contains := func(p s2.Point) bool {
+		if math.Abs(p.Dot(n.Vector)) > sin {
+			return false
+		}
+		q := p.Sub(n.Mul(p.Dot(n.Vector)))
+		return a.Cross(q).Dot(n.Vector) >= 0 && q.Cross(b.Vector).Dot(n.Vector) >= 0
+	}
+	return ring, contains
+}
@@ -0,0 +1,89 @@
+package gm
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestBuffer(t *testing.T) {
+	const (
+		maxErr = 1e-4
+		maxY   = 3
+	)
+	rnd := rand.New(rand.NewSource(1))
+	points := s2.PointVector{s2.PointFromLatLng(s2.LatLngFromDegrees(10, 20)), s2.PointFromLatLng(s2.LatLngFromDegrees(70, 175))}
+	line := polyline([2]float64{40, 160}, [2]float64{30, -160}, [2]float64{-20, -150})
+	polygon := s2.PolygonFromLoops([]*s2.Loop{rectLoop(-20, -40, 30, 60), rectLoop(0, 0, 10, 10)})
+	for _, test := range []struct {
+		gm       *GeneralizedMercator
+		shape    s2.Shape
+		distance float64
+		// dist returns the distance from p to the shape.
+		dist func(p s2.Point) s1.Angle
+	}{
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), &points, 0.3,
+			func(p s2.Point) s1.Angle { return minAngle(p.Distance(points[0]), p.Distance(points[1])) },
+		},
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithRadius(6371)), &line, 1000,
+			func(p s2.Point) s1.Angle { return distanceFromPolyline(p, line) },
+		},
+		{
+			New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150), WithAffine(-1, 0, 0, 1, 0, 0)), &line, 0.2,
+			func(p s2.Point) s1.Angle { return distanceFromPolyline(p, line) },
+		},
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), polygon, 0.05,
+			func(p s2.Point) s1.Angle {
+				if polygon.ContainsPoint(p) {
+					return 0
+				}
+				d := s1.InfAngle()
+				for _, l := range polygon.Loops() {
+					for n := 0; n < l.NumVertices(); n++ {
+						d = minAngle(d, s2.DistanceFromSegment(p, l.Vertex(n), l.Vertex(n+1)))
+					}
+				}
+				return d
+			},
+		},
+	} {
+		rings := test.gm.Buffer(test.shape, test.distance, maxErr, maxY)
+		d := s1.Angle(test.distance / test.gm.Radius())
+		for n := 0; n < 2000; n++ {
+			p := s2.Point{randomPoint(rnd).Normalize()}
+			q := test.gm.ProjectPoint(p)
+			if y := test.gm.out.invert(q).Y; y > maxY || y < -maxY {
+				continue
+			}
+			dist := test.dist(p)
+			if dist > d-4*maxErr && dist < d+4*maxErr {
+				continue
+			}
+			if got, want := test.gm.RingsContainPoint(rings, maxY, q), dist <= d; got != want {
+				t.Errorf("Buffer(%v, %v, %v): got %v for %v at distance %v, want %v", test.gm, test.shape, test.distance, got, p, dist, want)
+			}
+		}
+	}
+
+	// A buffer of zero distance around a polyline is empty.
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	if rings := gm.Buffer(&line, 0, maxErr, maxY); len(rings) != 0 {
+		t.Errorf("Buffer(%v, %v, 0): got %v, want no rings", gm, line, rings)
+	}
+	if rings := gm.Buffer(polygon, 0, maxErr, maxY); !gm.RingsContainPoint(rings, maxY, gm.Project(s2.LatLngFromDegrees(20, 20))) {
+		t.Errorf("Buffer(%v, %v, 0): got %v, want the polygon", gm, polygon, rings)
+	}
+}
+
+// minAngle returns the smaller of a and b.
+func minAngle(a, b s1.Angle) s1.Angle {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,140 @@
+package gm
+
+import (
+	"container/list"
+	"math"
+	"sync"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A CachingProjector projects and unprojects like the projection from which it was created, remembering the results
+// for the most recently used inputs, for workloads that repeatedly convert the same coordinates, such as the vertices
+// shared by the tiles of a map that is redrawn as it is panned. Inputs may be quantized, so that inputs that differ
+// by less than the quantum share a cache entry, at the cost of converting the nearest multiple of the quantum
+// in place of each input. It is safe for concurrent use.
+type CachingProjector struct {
+	gm *GeneralizedMercator
+
+	// angleQuantum and planeQuantum are the quanta of latitudes and longitudes and of projected coordinates,
+	// or 0 if they are not quantized.
+	angleQuantum, planeQuantum float64
+
+	mu                 sync.Mutex
+	project, unproject lru
+}
+
+// NewCachingProjector returns a CachingProjector that remembers the results of up to size calls each to Project
+// and Unproject. Latitudes and longitudes are rounded to the nearest multiple of angleQuantum, and projected
+// coordinates to the nearest multiple of planeQuantum, if it is positive. NewCachingProjector panics if size
+// is not positive.
+func (gm *GeneralizedMercator) NewCachingProjector(size int, angleQuantum s1.Angle, planeQuantum float64) *CachingProjector {
+	if size <= 0 {
+		panic("gm: invalid cache size")
+	}
+	return &CachingProjector{
+		gm:           gm,
+		angleQuantum: math.Max(0, float64(angleQuantum)),
+		planeQuantum: math.Max(0, planeQuantum),
+		project:      newLRU(size),
+		unproject:    newLRU(size),
+	}
+}
+
+// Project converts ll to a projected 2D point.
+func (c *CachingProjector) Project(ll s2.LatLng) r2.Point {
+	k := [2]float64{quantize(float64(ll.Lat), c.angleQuantum), quantize(float64(ll.Lng), c.angleQuantum)}
+	if math.IsNaN(k[0]) || math.IsNaN(k[1]) {
+		return c.gm.Project(ll)
+	}
+	c.mu.Lock()
+	v, ok := c.project.get(k)
+	c.mu.Unlock()
+	if ok {
+		return r2.Point{X: v[0], Y: v[1]}
+	}
+	p := c.gm.Project(s2.LatLng{Lat: s1.Angle(k[0]), Lng: s1.Angle(k[1])})
+	c.mu.Lock()
+	c.project.put(k, [2]float64{p.X, p.Y})
+	c.mu.Unlock()
+	return p
+}
+
+// Unproject converts a projected point p to a location on the reference sphere.
+func (c *CachingProjector) Unproject(p r2.Point) s2.LatLng {
+	k := [2]float64{quantize(p.X, c.planeQuantum), quantize(p.Y, c.planeQuantum)}
+	if math.IsNaN(k[0]) || math.IsNaN(k[1]) {
+		return c.gm.Unproject(p)
+	}
+	c.mu.Lock()
+	v, ok := c.unproject.get(k)
+	c.mu.Unlock()
+	if ok {
+		return s2.LatLng{Lat: s1.Angle(v[0]), Lng: s1.Angle(v[1])}
+	}
+	ll := c.gm.Unproject(r2.Point{X: k[0], Y: k[1]})
+	c.mu.Lock()
+	c.unproject.put(k, [2]float64{float64(ll.Lat), float64(ll.Lng)})
+	c.mu.Unlock()
+	return ll
+}
+
+// Len returns the numbers of results of Project and Unproject that c currently remembers.
+func (c *CachingProjector) Len() (project, unproject int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.project.order.Len(), c.unproject.order.Len()
+}
+
+// quantize returns the multiple of q nearest to v, or v if q is 0 or v is infinite.
+func quantize(v, q float64) float64 {
+	if q == 0 || math.IsInf(v, 0) {
+		return v
+	}
+	return math.Round(v/q) * q
+}
+
+// An lru is a map of limited size from pairs of coordinates to pairs of coordinates
+// that evicts the least recently used entry to make room for a new one.
+type lru struct {
+	size    int
+	entries map[[2]float64]*list.Element
+
+	// order holds the lruEntry of each key, most recently used first.
+	order *list.List
+}
+
+type lruEntry struct {
+	key, value [2]float64
+}
+
+func newLRU(size int) lru {
+	return lru{size: size, entries: make(map[[2]float64]*list.Element), order: list.New()}
+}
+
+// get returns the value of k and reports whether it is present, marking it as the most recently used.
+func (l *lru) get(k [2]float64) ([2]float64, bool) {
+	e, ok := l.entries[k]
+	if !ok {
+		return [2]float64{}, false
+	}
+	l.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+// put sets the value of k, marking it as the most recently used and evicting the least recently used entry if necessary.
+func (l *lru) put(k, v [2]float64) {
+	if e, ok := l.entries[k]; ok {
+		e.Value.(*lruEntry).value = v
+		l.order.MoveToFront(e)
+		return
+	}
+	if l.order.Len() >= l.size {
+		oldest := l.order.Back()
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+		l.order.Remove(oldest)
+	}
+	l.entries[k] = l.order.PushFront(&lruEntry{key: k, value: v})
+}
@@ -0,0 +1,100 @@
+package gm
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestCachingProjector(t *testing.T) {
+	for _, test := range projTests {
+		c := test.gm.NewCachingProjector(4, 0, 0)
+		for pass := 0; pass < 2; pass++ {
+			for _, p := range test.ps {
+				if got, want := c.Project(p.s), test.gm.Project(p.s); got != want {
+					t.Errorf("%v: cached Project(%v): got %v, want %v", test.gm, p.s, got, want)
+				}
+				if got, want := c.Unproject(p.r), test.gm.Unproject(p.r); got != want {
+					t.Errorf("%v: cached Unproject(%v): got %v, want %v", test.gm, p.r, got, want)
+				}
+			}
+		}
+		if np, nu := c.Len(); np != 4 || nu != 4 {
+			t.Errorf("%v: cache lengths: got %v, %v, want 4, 4", test.gm, np, nu)
+		}
+	}
+}
+
+func TestCachingProjectorQuantum(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0))
+	const q = 1e-6
+	c := gm.NewCachingProjector(16, q, q)
+	a, b := s2.LatLng{Lat: 0.5, Lng: 1}, s2.LatLng{Lat: 0.5 + q/4, Lng: 1 - q/4}
+	if pa, pb := c.Project(a), c.Project(b); pa != pb {
+		t.Errorf("cached Project(%v) and Project(%v): got %v and %v, want equal", a, b, pa, pb)
+	}
+	if np, _ := c.Len(); np != 1 {
+		t.Errorf("cache length after projecting nearby locations: got %v, want 1", np)
+	}
+	if got, want := c.Project(b), gm.Project(s2.LatLng{Lat: s1.Angle(quantize(0.5, q)), Lng: s1.Angle(quantize(1, q))}); got != want {
+		t.Errorf("cached Project(%v): got %v, want %v", b, got, want)
+	}
+	p := r2.Point{X: 1, Y: 2}
+	if la, lb := c.Unproject(p), c.Unproject(p.Add(r2.Point{X: q / 3, Y: -q / 3})); la != lb {
+		t.Errorf("cached Unproject of nearby points: got %v and %v, want equal", la, lb)
+	}
+
+	// Inputs that are NaN are converted without being remembered.
+	c.Project(s2.LatLng{Lat: s1.Angle(math.NaN())})
+	c.Unproject(r2.Point{X: math.NaN()})
+	if np, nu := c.Len(); np != 1 || nu != 1 {
+		t.Errorf("cache lengths after NaN inputs: got %v, %v, want 1, 1", np, nu)
+	}
+}
+
+func TestLRU(t *testing.T) {
+	l := newLRU(2)
+	k := func(n float64) [2]float64 { return [2]float64{n, -n} }
+	l.put(k(1), k(10))
+	l.put(k(2), k(20))
+	if v, ok := l.get(k(1)); !ok || v != k(10) {
+		t.Errorf("get(1): got %v, %v, want %v, true", v, ok, k(10))
+	}
+	// 2 is now the least recently used.
+	l.put(k(3), k(30))
+	if _, ok := l.get(k(2)); ok {
+		t.Error("get(2) after eviction: got ok == true")
+	}
+	for _, n := range []float64{1, 3} {
+		if v, ok := l.get(k(n)); !ok || v != k(10*n) {
+			t.Errorf("get(%v): got %v, %v, want %v, true", n, v, ok, k(10*n))
+		}
+	}
+	l.put(k(1), k(11))
+	if v, _ := l.get(k(1)); v != k(11) || l.order.Len() != 2 {
+		t.Errorf("get(1) after update: got %v with %v entries, want %v with 2", v, l.order.Len(), k(11))
+	}
+}
+
+func TestCachingProjectorConcurrent(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0))
+	c := gm.NewCachingProjector(8, 0, 0)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				ll := s2.LatLngFromDegrees(float64(n%16), float64(w))
+				if got, want := c.Project(ll), gm.Project(ll); got != want {
+					t.Errorf("cached Project(%v): got %v, want %v", ll, got, want)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,175 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// ProjectCap returns the projection of the boundary of c as one or more pieces, traversed counterclockwise around c
+// like the boundary of a loop, and split like ProjectPolyline wherever it crosses the seam. The pieces approximate
+// the projected curve to within maxErr, which must be positive, measured as an angle on the reference sphere.
+// If the boundary closes without crossing the seam, its single piece ends where it begins.
+//
+// A cap whose boundary is a curve of constant generalized latitude, such as the caps returned by ParallelCurve,
+// projects to a horizontal line across the map, which ProjectCap returns as a single piece from edge to edge.
+// This includes the caps centered on the poles if the poles are antipodal. If the boundary passes through a pole,
+// the pieces end and begin there at points with infinite y coordinates and the x coordinates of their neighbors.
+// ProjectCap returns nil if c is empty or full.
+func (gm *GeneralizedMercator) ProjectCap(c s2.Cap, maxErr s1.Angle) [][]r2.Point {
+	switch {
+	case c.IsEmpty() || c.IsFull():
+		return nil
+	case c.Radius() == 0:
+		return [][]r2.Point{{gm.ProjectPoint(c.Center())}}
+	}
+	if line, ok := gm.parallelCapLine(c); ok {
+		for n, p := range line {
+			line[n] = gm.fromCentered(p)
+		}
+		return [][]r2.Point{line}
+	}
+
+	center, r := c.Center(), float64(c.Radius())
+	// Begin the boundary at a pole if it passes through one, so that the pole separates pieces.
+	u := center.Ortho()
+	for _, pole := range []r3.Vector{gm.pos, gm.neg} {
+		if math.Abs(float64(center.Angle(pole))-r) < epsilon {
+			u = pole.Sub(center.Mul(pole.Dot(center.Vector))).Normalize()
+		}
+	}
+	v := center.Cross(u)
+	boundary := func(theta float64) s2.Point {
+		sin, cos := math.Sincos(theta)
+		return s2.Point{center.Mul(math.Cos(r)).Add(u.Mul(cos * math.Sin(r))).Add(v.Mul(sin * math.Sin(r))).Normalize()}
+	}
+	e := math.Pi * gm.radius * gm.k0
+	// unwrapped returns the centered projection of the point of the boundary at theta, with its x coordinate
+	// translated by a whole number of periods to be nearest to x.
+	unwrapped := func(theta, x float64) r2.Point {
+		p := gm.projectCentered(boundary(theta))
+		if isFinite(p) {
+			p.X = x + math.Remainder(p.X-x, 2*e)
+		}
+		return p
+	}
+	onBoundary := func(q r2.Point) bool {
+		return math.Abs(float64(center.Angle(gm.UnprojectPoint(gm.fromCentered(q)).Vector))-r) <= float64(maxErr)
+	}
+
+	// Sample the boundary adaptively, beginning with eight equal arcs.
+	const arcs = 8
+	thetas := []float64{0}
+	pts := []r2.Point{gm.projectCentered(boundary(0))}
+	var subdivide func(t0, t1 float64, p0, p1 r2.Point, depth int)
+	subdivide = func(t0, t1 float64, p0, p1 r2.Point, depth int) {
+		if depth == 0 {
+			return
+		}
+		if isFinite(p0) && isFinite(p1) {
+			mid := p0.Add(p1).Mul(0.5)
+			if onBoundary(mid) && onBoundary(p0.Add(mid).Mul(0.5)) && onBoundary(mid.Add(p1).Mul(0.5)) {
+				return
+			}
+		}
+		// A chord to a pole cannot approximate the boundary, so the arc is divided until the depth is exhausted.
+		tm := (t0 + t1) / 2
+		x := p0.X
+		if !isFinite(p0) {
+			x = p1.X
+		}
+		pm := unwrapped(tm, x)
+		subdivide(t0, tm, p0, pm, depth-1)
+		thetas, pts = append(thetas, tm), append(pts, pm)
+		subdivide(tm, t1, pm, p1, depth-1)
+	}
+	for n := 0; n < arcs; n++ {
+		t0, t1 := 2*math.Pi*float64(n)/arcs, 2*math.Pi*float64(n+1)/arcs
+		p0 := pts[len(pts)-1]
+		x := p0.X
+		if !isFinite(p0) {
+			x = 0
+		}
+		p1 := unwrapped(t1, x)
+		subdivide(t0, t1, p0, p1, maxSubdivisions)
+		thetas, pts = append(thetas, t1), append(pts, p1)
+	}
+
+	// Assemble the pieces, wrapping the samples into the map and splitting them at the seam and the poles.
+	var pieces [][]r2.Point
+	var piece []r2.Point
+	var shift float64 // the translation that brings the current samples into the map
+	pole := false     // whether the current piece begins at a pole
+	for n, p := range pts {
+		if !isFinite(p) {
+			if n > 0 {
+				pieces = append(pieces, append(piece, r2.Point{X: piece[len(piece)-1].X, Y: p.Y}))
+			}
+			piece, pole = []r2.Point{{Y: p.Y}}, true
+			continue
+		}
+		if n == 0 || (pole && len(piece) == 1) {
+			shift = -2 * e * math.Round(p.X/(2*e))
+			if pole {
+				piece[0].X = p.X + shift
+			}
+			piece = append(piece, r2.Point{X: p.X + shift, Y: p.Y})
+			continue
+		}
+		prev := pts[n-1]
+		// Split the piece where the boundary crosses an edge of the map.
+		for math.Abs(p.X+shift) > e {
+			dir := math.Copysign(1, p.X+shift)
+			edge := dir*e - shift
+			lo, hi := thetas[n-1], thetas[n]
+			for m := 0; m < 64 && hi-lo > 1e-16; m++ {
+				t := (lo + hi) / 2
+				if q := unwrapped(t, prev.X); (q.X-edge)*dir < 0 {
+					lo = t
+				} else {
+					hi = t
+				}
+			}
+			y := gm.projectCentered(boundary((lo + hi) / 2)).Y
+			pieces = append(pieces, append(piece, r2.Point{X: dir * e, Y: y}))
+			piece = []r2.Point{{X: -dir * e, Y: y}}
+			shift -= dir * 2 * e
+		}
+		piece = append(piece, r2.Point{X: p.X + shift, Y: p.Y})
+	}
+	if len(pieces) > 0 && isFinite(pts[0]) {
+		// The boundary was split but did not begin at a pole, so the last piece continues into the first.
+		pieces[0] = append(piece, pieces[0][1:]...)
+	} else if len(piece) > 1 {
+		pieces = append(pieces, piece)
+	}
+	for _, piece := range pieces {
+		for n, p := range piece {
+			piece[n] = gm.fromCentered(p)
+		}
+	}
+	return pieces
+}
+
+// parallelCapLine reports whether the boundary of c is a curve of constant generalized latitude, and if so,
+// returns its projection before the output transformation as a horizontal line across the map, directed so that c is on its left.
+func (gm *GeneralizedMercator) parallelCapLine(c s2.Cap) ([]r2.Point, bool) {
+	b := s2.Point{c.Center().Mul(math.Cos(float64(c.Radius()))).Add(c.Center().Ortho().Mul(math.Sin(float64(c.Radius()))))}
+	if gm.near(b.Vector, gm.pos) || gm.near(b.Vector, gm.neg) {
+		return nil, false
+	}
+	_, psi := gm.generalized(b.Vector)
+	p := gm.parallelCap(psi)
+	y := yFromPsi(psi) * gm.radius * gm.k0
+	e := math.Pi * gm.radius * gm.k0
+	switch {
+	case approxEqual(c.Center().Vector, p.Center().Vector) && math.Abs(float64(c.Radius()-p.Radius())) < 1e-12:
+		return []r2.Point{{X: -e, Y: y}, {X: e, Y: y}}, true
+	case approxEqual(c.Center().Vector, p.Center().Mul(-1)) && math.Abs(float64(c.Radius()+p.Radius())-math.Pi) < 1e-12:
+		return []r2.Point{{X: e, Y: y}, {X: -e, Y: y}}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,91 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestProjectCap(t *testing.T) {
+	const maxErr = 1e-6
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	capAt := func(lat, lng, r float64) s2.Cap {
+		return s2.CapFromCenterAngle(s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lng)), s1.Angle(r)*s1.Degree)
+	}
+	y60 := math.Log(math.Tan(pi/4 + pi/6))
+	for _, test := range []struct {
+		name string
+		c    s2.Cap
+		want [][]r2.Point
+	}{
+		{"empty", s2.EmptyCap(), nil},
+		{"full", s2.FullCap(), nil},
+		{"north pole", capAt(90, 0, 30), [][]r2.Point{{{X: -pi, Y: y60}, {X: pi, Y: y60}}}},
+		{"south pole", capAt(-90, 0, 30), [][]r2.Point{{{X: pi, Y: -y60}, {X: -pi, Y: -y60}}}},
+		{"complement", capAt(90, 0, 30).Complement(), [][]r2.Point{{{X: pi, Y: y60}, {X: -pi, Y: y60}}}},
+	} {
+		if got := mercator.ProjectCap(test.c, maxErr); !piecesApproxEqual(got, test.want) {
+			t.Errorf("ProjectCap(%v, %s): got %v, want %v", mercator, test.name, got, test.want)
+		}
+	}
+
+	for _, test := range []struct {
+		gm     *GeneralizedMercator
+		c      s2.Cap
+		pieces int
+		closed bool
+		inf    bool
+	}{
+		{mercator, capAt(10, 20, 5), 1, true, false},
+		{mercator, capAt(0, 180, 10), 2, false, false},
+		// The boundary passes through the north pole, where it turns from one meridian to the opposite one.
+		{mercator, capAt(60, 0, 30), 1, false, true},
+		// The boundary encircles the north pole, and so crosses the seam once.
+		{mercator, capAt(80, 20, 20), 1, false, false},
+		{New(s2.LatLngFromDegrees(0, -60), s2.LatLngFromDegrees(0, 60)), capAt(10, 20, 5), 1, true, false},
+		{New(s2.LatLngFromDegrees(-45, 0), s2.LatLngFromDegrees(-45, 180)), capAt(-45, 0, 20), 1, false, false},
+		{New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10), WithAffine(0, -1, 1, 0, 2, 3)), capAt(-20, 100, 40), 1, true, false},
+	} {
+		got := test.gm.ProjectCap(test.c, maxErr)
+		if len(got) != test.pieces {
+			t.Errorf("ProjectCap(%v, %v): got %d pieces, want %d", test.gm, test.c, len(got), test.pieces)
+			continue
+		}
+		center, r := test.c.Center(), test.c.Radius()
+		var inf bool
+		for _, piece := range got {
+			for n, p := range piece {
+				if !isFinite(p) {
+					inf = true
+					continue
+				}
+				if d := center.Angle(test.gm.UnprojectPoint(p).Vector) - r; math.Abs(float64(d)) > maxErr {
+					t.Errorf("ProjectCap(%v, %v): point %v is %v from the boundary", test.gm, test.c, p, d)
+				}
+				if n == 0 || !isFinite(piece[n-1]) {
+					continue
+				}
+				mid := piece[n-1].Add(p).Mul(0.5)
+				if d := center.Angle(test.gm.UnprojectPoint(mid).Vector) - r; math.Abs(float64(d)) > maxErr {
+					t.Errorf("ProjectCap(%v, %v): chord midpoint %v is %v from the boundary", test.gm, test.c, mid, d)
+				}
+			}
+		}
+		if inf != test.inf {
+			t.Errorf("ProjectCap(%v, %v): got infinite points %v, want %v", test.gm, test.c, inf, test.inf)
+		}
+		first, last := got[0][0], got[len(got)-1][len(got[len(got)-1])-1]
+		if closed := ptApproxEqual(first, last); closed != test.closed {
+			t.Errorf("ProjectCap(%v, %v): got boundary from %v to %v, want closed %v", test.gm, test.c, first, last, test.closed)
+		}
+	}
+
+	// The boundary is counterclockwise around the cap.
+	got := mercator.ProjectCap(capAt(10, 20, 5), maxErr)
+	if a := signedArea(got[0]); a <= 0 {
+		t.Errorf("ProjectCap(%v, %v): got signed area %v", mercator, capAt(10, 20, 5), a)
+	}
+}
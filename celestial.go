@@ -0,0 +1,82 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// RADec represents a point on the celestial sphere in equatorial coordinates: right ascension,
+// measured eastward along the celestial equator from the March equinox, and declination.
+type RADec struct {
+	RA, Dec s1.Angle
+}
+
+// RADecFromDegrees returns an RADec for the given right ascension and declination in degrees.
+// Right ascension in hours is converted to degrees by multiplying by 15.
+func RADecFromDegrees(ra, dec float64) RADec {
+	return RADec{RA: s1.Angle(ra) * s1.Degree, Dec: s1.Angle(dec) * s1.Degree}
+}
+
+// LatLng returns the location on the unit sphere with latitude rd.Dec and longitude rd.RA.
+func (rd RADec) LatLng() s2.LatLng {
+	return s2.LatLng{Lat: rd.Dec, Lng: s1.Angle(math.Remainder(float64(rd.RA), 2*math.Pi))}
+}
+
+// RADecFromLatLng returns the RADec corresponding to ll, with right ascension in the interval [0, 2π).
+func RADecFromLatLng(ll s2.LatLng) RADec {
+	ra := math.Mod(float64(ll.Lng), 2*math.Pi)
+	if ra < 0 {
+		ra += 2 * math.Pi
+	}
+	return RADec{RA: s1.Angle(ra), Dec: ll.Lat}
+}
+
+// The J2000 equatorial coordinates of the poles of two great circles commonly used as generalized equators.
+var (
+	// NorthEclipticPole is the north pole of the ecliptic, at declination 90° minus the obliquity of the ecliptic.
+	NorthEclipticPole = RADecFromDegrees(270, 66.560708888)
+
+	// NorthGalacticPole is the north pole of the galactic coordinate system.
+	NorthGalacticPole = RADecFromDegrees(192.85948, 27.12825)
+)
+
+// Celestial adapts a GeneralizedMercator to equatorial coordinates on the celestial sphere,
+// for star charts whose generalized equator is an arbitrary great circle, such as the ecliptic or the galactic plane.
+// The projection is of the celestial sphere as seen from outside; for a chart as seen from the Earth,
+// with east to the left, reflect the x axis with WithAffine(-1, 0, 0, 1, 0, 0).
+type Celestial struct {
+	*GeneralizedMercator
+}
+
+// NewCelestial returns a pointer to a Celestial with poles at pos and neg, configured by opts.
+// Options that interpret latitudes, such as WithGeodeticLatitude, have no meaning on the celestial sphere.
+// NewCelestial panics if pos and neg are equal or if any option is invalid.
+func NewCelestial(pos, neg RADec, opts ...Option) *Celestial {
+	return &Celestial{New(pos.LatLng(), neg.LatLng(), opts...)}
+}
+
+// NewCelestialPlane returns a pointer to a Celestial, configured by opts, whose generalized equator is the great circle
+// with north pole pole, such as NorthEclipticPole or NorthGalacticPole. NewCelestialPlane panics if any option is invalid.
+func NewCelestialPlane(pole RADec, opts ...Option) *Celestial {
+	ll := pole.LatLng()
+	return &Celestial{New(ll, s2.LatLngFromPoint(s2.Point{Vector: s2.PointFromLatLng(ll).Mul(-1)}), opts...)}
+}
+
+// Poles returns the positive and negative poles of the projection.
+func (c *Celestial) Poles() (pos, neg RADec) {
+	p, n := c.GeneralizedMercator.Poles()
+	return RADecFromLatLng(p), RADecFromLatLng(n)
+}
+
+// Project converts rd to a projected 2D point.
+func (c *Celestial) Project(rd RADec) r2.Point {
+	return c.GeneralizedMercator.Project(rd.LatLng())
+}
+
+// Unproject converts a projected 2D point to equatorial coordinates.
+func (c *Celestial) Unproject(p r2.Point) RADec {
+	return RADecFromLatLng(c.GeneralizedMercator.Unproject(p))
+}
@@ -0,0 +1,42 @@
+package gm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRADec(t *testing.T) {
+	for _, rd := range []RADec{
+		RADecFromDegrees(0, 0),
+		RADecFromDegrees(90, 45),
+		RADecFromDegrees(270, -30),
+		RADecFromDegrees(359.5, 89),
+	} {
+		if got := RADecFromLatLng(rd.LatLng()); math.Abs(float64(got.RA-rd.RA)) > 1e-14 || got.Dec != rd.Dec {
+			t.Errorf("RADecFromLatLng(LatLng(%v)): got %v", rd, got)
+		}
+	}
+}
+
+func TestCelestial(t *testing.T) {
+	c := NewCelestialPlane(NorthEclipticPole)
+	pos, neg := c.Poles()
+	if math.Abs(float64(pos.RA-NorthEclipticPole.RA)) > 1e-14 || math.Abs(float64(pos.Dec-NorthEclipticPole.Dec)) > 1e-14 {
+		t.Errorf("Poles(%v): got pos %v, want %v", c, pos, NorthEclipticPole)
+	}
+	if want := RADecFromDegrees(90, -66.560708888); math.Abs(float64(neg.RA-want.RA)) > 1e-14 || math.Abs(float64(neg.Dec-want.Dec)) > 1e-14 {
+		t.Errorf("Poles(%v): got neg %v, want %v", c, neg, want)
+	}
+	// The equinoxes are on the ecliptic.
+	for _, rd := range []RADec{RADecFromDegrees(0, 0), RADecFromDegrees(180, 0)} {
+		if got := c.Project(rd); math.Abs(got.Y) > 1e-14 {
+			t.Errorf("Project(%v, %v): got %v, want y == 0", c, rd, got)
+		}
+	}
+	for _, rd := range []RADec{RADecFromDegrees(10, 20), RADecFromDegrees(200, -40), RADecFromDegrees(300, 5)} {
+		p := c.Project(rd)
+		if got := c.Unproject(p); math.Abs(float64(got.RA-rd.RA)) > 1e-12 || math.Abs(float64(got.Dec-rd.Dec)) > 1e-12 {
+			t.Errorf("Unproject(%v, %v): got %v, want %v", c, p, got, rd)
+		}
+	}
+}
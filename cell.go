@@ -0,0 +1,142 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// ProjectCell projects the cell c as ProjectLoop projects a loop, returning one or more rings cut along the seam,
+// with vertices inserted along the cell's geodesic edges so that each projected edge deviates from the projection
+// of the geodesic by at most maxErr, which must be positive, measured as an angle on the reference sphere.
+// The rings are counterclockwise unless the output transformation reverses orientation. Projected y coordinates
+// are clamped to [-maxY, maxY], so that a cell that contains a pole projects to a band that extends to the top
+// or bottom of the strip |y| <= maxY.
+func (gm *GeneralizedMercator) ProjectCell(c s2.Cell, maxErr s1.Angle, maxY float64) [][]r2.Point {
+	vertices := make([]s2.Point, 4)
+	for k := range vertices {
+		vertices[k] = c.Vertex(k)
+	}
+	ring := gm.densifyRing(vertices, maxErr)
+	return gm.outRings(gm.projectRings([][]s2.Point{ring}, c.ContainsPoint, maxY), 0)
+}
+
+// densifyRing returns the vertices of the closed ring of geodesic edges through vertices, with vertices inserted
+// so that the projection of each edge is approximated to within maxErr by straight segments.
+func (gm *GeneralizedMercator) densifyRing(vertices []s2.Point, maxErr s1.Angle) []s2.Point {
+	var ring []s2.Point
+	for k, a := range vertices {
+		ring = gm.densifyEdge(ring, a, vertices[(k+1)%len(vertices)], maxErr)
+	}
+	return ring
+}
+
+// densifyEdge appends to dst a and the vertices inserted along the geodesic edge from a to b,
+// but not b, so that the projection of the edge is approximated to within maxErr by straight segments.
+func (gm *GeneralizedMercator) densifyEdge(dst []s2.Point, a, b s2.Point, maxErr s1.Angle) []s2.Point {
+	ts, _ := gm.flattenEdge(a, b, gm.EdgeCurve(a, b).Eval, maxErr)
+	for _, t := range ts[:len(ts)-1] {
+		dst = append(dst, s2.Interpolate(t, a, b))
+	}
+	return dst
+}
+
+// ProjectCellUnion projects the region covered by the cells of cu as ProjectCell projects a single cell,
+// but returns the rings of the merged outline of the region rather than the outline of each cell, so that
+// edges shared by adjacent cells are omitted. Shells project to counterclockwise rings and holes to clockwise rings,
+// unless the output transformation reverses orientation. ProjectCellUnion returns nil if cu is empty.
+func (gm *GeneralizedMercator) ProjectCellUnion(cu s2.CellUnion, maxErr s1.Angle, maxY float64) [][]r2.Point {
+	cu = append(s2.CellUnion(nil), cu...)
+	cu.Normalize()
+	if len(cu) == 0 {
+		return nil
+	}
+	var rings [][]s2.Point
+	for _, loop := range cellUnionBoundary(cu) {
+		rings = append(rings, gm.densifyRing(loop, maxErr))
+	}
+	return gm.outRings(gm.projectRings(rings, cu.ContainsPoint, maxY), 0)
+}
+
+// cellUnionBoundary returns the boundary of the region covered by the normalized union cu as closed loops
+// with the region on their left.
+func cellUnionBoundary(cu s2.CellUnion) [][]s2.Point {
+	// Collect the parts of the cells' edges across which the union does not continue.
+	type segment struct{ a, b s2.Point }
+	var segments []segment
+	var edge func(id s2.CellID, k int)
+	edge = func(id s2.CellID, k int) {
+		n := id.EdgeNeighbors()[k]
+		switch {
+		case cu.ContainsCellID(n):
+			return
+		case !cu.IntersectsCellID(n) || id.IsLeaf():
+			c := s2.CellFromCellID(id)
+			segments = append(segments, segment{c.Vertex(k), c.Vertex((k + 1) % 4)})
+			return
+		}
+		// The union contains part of the neighbor, so divide the edge between the two children along it,
+		// beginning with the one at vertex k.
+		v := s2.CellFromCellID(id).Vertex(k)
+		var along []s2.CellID
+		for _, ch := range id.Children() {
+			if !id.Contains(ch.EdgeNeighbors()[k]) {
+				along = append(along, ch)
+			}
+		}
+		if s2.CellFromCellID(along[1]).Vertex(k).Angle(v.Vector) < s2.CellFromCellID(along[0]).Vertex(k).Angle(v.Vector) {
+			along[0], along[1] = along[1], along[0]
+		}
+		edge(along[0], k)
+		edge(along[1], k)
+	}
+	for _, id := range cu {
+		for k := 0; k < 4; k++ {
+			edge(id, k)
+		}
+	}
+
+	// Chain the segments into loops. Segments meet at shared vertices, which are computed separately for each cell,
+	// so they are matched to within a small tolerance using a grid of buckets.
+	const tolerance = 1e-12
+	type key [3]int64
+	keyOf := func(p s2.Point) key {
+		return key{int64(math.Floor(p.X / tolerance)), int64(math.Floor(p.Y / tolerance)), int64(math.Floor(p.Z / tolerance))}
+	}
+	starts := make(map[key][]int)
+	for n, s := range segments {
+		k := keyOf(s.a)
+		starts[k] = append(starts[k], n)
+	}
+	used := make([]bool, len(segments))
+	next := func(p s2.Point) int {
+		k := keyOf(p)
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for dz := int64(-1); dz <= 1; dz++ {
+					for _, n := range starts[key{k[0] + dx, k[1] + dy, k[2] + dz}] {
+						if !used[n] && float64(segments[n].a.Angle(p.Vector)) <= tolerance {
+							return n
+						}
+					}
+				}
+			}
+		}
+		return -1
+	}
+	var loops [][]s2.Point
+	for n := range segments {
+		if used[n] {
+			continue
+		}
+		var loop []s2.Point
+		for m := n; m >= 0; m = next(segments[m].b) {
+			used[m] = true
+			loop = append(loop, segments[m].a)
+		}
+		loops = append(loops, loop)
+	}
+	return loops
+}
@@ -0,0 +1,185 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestProjectCell(t *testing.T) {
+	const (
+		maxErr = 1e-6
+		maxY   = 5
+	)
+	cellAt := func(lat, lng float64, level int) s2.Cell {
+		return s2.CellFromCellID(s2.CellIDFromLatLng(s2.LatLngFromDegrees(lat, lng)).Parent(level))
+	}
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, test := range []struct {
+		gm    *GeneralizedMercator
+		c     s2.Cell
+		rings int
+	}{
+		{mercator, cellAt(10, 20, 5), 1},
+		{mercator, s2.CellFromCellID(s2.CellIDFromFace(3)), 2},
+		// A cell with an edge along the seam.
+		{mercator, cellAt(0, 180, 3), 1},
+		{mercator, s2.CellFromCellID(s2.CellIDFromFace(2)), 1},
+		{mercator, cellAt(89.99, 0, 1), 1},
+		{New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10), WithAffine(0, -1, 1, 0, 2, 3)), cellAt(-20, 100, 4), 1},
+	} {
+		got := test.gm.ProjectCell(test.c, maxErr, maxY)
+		if len(got) != test.rings {
+			t.Errorf("ProjectCell(%v, %v): got %d rings, want %d", test.gm, test.c.ID(), len(got), test.rings)
+			continue
+		}
+		// Every point of the cell's boundary is near the projected rings, and points well inside or outside the cell
+		// have the corresponding winding numbers.
+		for k := 0; k < 4; k++ {
+			a, b := test.c.Vertex(k), test.c.Vertex((k+1)%4)
+			for f := 0.0; f <= 1; f += 1.0 / 64 {
+				p := test.gm.ProjectPoint(s2.Interpolate(f, a, b))
+				if !isFinite(p) || math.Abs(test.gm.out.invert(p).Y) > maxY-0.1 {
+					continue
+				}
+				if d := distanceToRings(got, p, test.gm.WrapDistance()); d > 1e-4 {
+					t.Errorf("ProjectCell(%v, %v): boundary point %v is %v from the rings", test.gm, test.c.ID(), p, d)
+					break
+				}
+			}
+		}
+		center := test.gm.ProjectPoint(test.c.Center())
+		// The center of a cell that straddles the seam may lie on the edge of the map, where the winding number is ambiguous.
+		if q := test.gm.out.invert(center); isFinite(center) && math.Abs(q.Y) < maxY && math.Abs(math.Abs(q.X)-pi) > 1e-6 {
+			if w := winding(got, center); w != 1 && w != -1 {
+				t.Errorf("ProjectCell(%v, %v): got winding number %d around the center", test.gm, test.c.ID(), w)
+			}
+		}
+		for _, ring := range got {
+			for _, p := range ring {
+				if q := test.gm.out.invert(p); math.Abs(q.Y) > maxY+1e-12 {
+					t.Errorf("ProjectCell(%v, %v): got point %v outside the strip", test.gm, test.c.ID(), p)
+				}
+			}
+		}
+	}
+	// The projected edges are nearly the projections of the geodesic edges.
+	c := cellAt(40, -100, 2)
+	got := mercator.ProjectCell(c, maxErr, maxY)
+	for _, ring := range got {
+		for n := range ring {
+			mid := ring[n].Add(ring[(n+1)%len(ring)]).Mul(0.5)
+			var d s1.Angle = math.Pi
+			for k := 0; k < 4; k++ {
+				if e := s2.DistanceFromSegment(mercator.UnprojectPoint(mid), c.Vertex(k), c.Vertex((k+1)%4)); e < d {
+					d = e
+				}
+			}
+			if d > maxErr {
+				t.Errorf("ProjectCell(%v, %v): edge midpoint %v is %v from the cell boundary", mercator, c.ID(), mid, d)
+			}
+		}
+	}
+}
+
+// distanceToRings returns the distance from p to the nearest edge of rings, allowing for translation by the period w.
+func distanceToRings(rings [][]r2.Point, p, w r2.Point) float64 {
+	d := math.Inf(1)
+	for _, ring := range rings {
+		for n := range ring {
+			a, b := ring[n], ring[(n+1)%len(ring)]
+			for k := -1.0; k <= 1; k++ {
+				q := p.Add(w.Mul(k))
+				ab := b.Sub(a)
+				f := 0.0
+				if l := ab.Dot(ab); l > 0 {
+					f = math.Max(0, math.Min(1, q.Sub(a).Dot(ab)/l))
+				}
+				d = math.Min(d, q.Sub(a.Add(ab.Mul(f))).Norm())
+			}
+		}
+	}
+	return d
+}
+
+func TestProjectCellUnion(t *testing.T) {
+	const (
+		maxErr = 1e-6
+		maxY   = 5
+	)
+	id := s2.CellIDFromLatLng(s2.LatLngFromDegrees(20, 30)).Parent(6)
+	// A ring of cells around id, with one of them replaced by its children and one of those by three of its children,
+	// leaving a second hole where the fourth would be.
+	ring := id.AllNeighbors(6)
+	children := ring[0].Children()
+	ring = append(ring[1:], children[1:]...)
+	grandchildren := children[0].Children()
+	ring = append(ring, grandchildren[:3]...)
+	seam := s2.CellIDFromLatLng(s2.LatLngFromDegrees(-30, 180)).Parent(4)
+	for _, test := range []struct {
+		name  string
+		cu    s2.CellUnion
+		loops int
+	}{
+		{"one cell", s2.CellUnion{id}, 1},
+		{"ring", s2.CellUnion(ring), 3},
+		{"across the seam", s2.CellUnion{seam, seam.EdgeNeighbors()[0], seam.EdgeNeighbors()[1]}, 1},
+		{"faces", s2.CellUnion{s2.CellIDFromFace(0), s2.CellIDFromFace(1), s2.CellIDFromFace(2)}, 1},
+	} {
+		cu := append(s2.CellUnion(nil), test.cu...)
+		cu.Normalize()
+		loops := cellUnionBoundary(cu)
+		if len(loops) != test.loops {
+			t.Errorf("cellUnionBoundary(%s): got %d loops, want %d", test.name, len(loops), test.loops)
+		}
+		var ls []*s2.Loop
+		for _, l := range loops {
+			ls = append(ls, s2.LoopFromPoints(l))
+		}
+		p := s2.PolygonFromOrientedLoops(ls)
+		for _, gm := range []*GeneralizedMercator{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+			New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10)),
+		} {
+			rings := gm.ProjectCellUnion(test.cu, maxErr, maxY)
+			bound := cu.CapBound()
+			for lat := -85.0; lat <= 85; lat += 1.3 {
+				for lng := -180.0; lng < 180; lng += 1.7 {
+					q := s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lng))
+					if !bound.ContainsPoint(q) || nearRings(q, loops, 1e-3) {
+						continue
+					}
+					want := cu.ContainsPoint(q)
+					if got := p.ContainsPoint(q); got != want {
+						t.Errorf("cellUnionBoundary(%s): polygon contains %v: got %v, want %v", test.name, s2.LatLngFromPoint(q), got, want)
+					}
+					pq := gm.ProjectPoint(q)
+					if math.Abs(pq.Y) > maxY-0.1 || math.Abs(math.Abs(pq.X)-pi) < 1e-3 {
+						continue
+					}
+					w := 0
+					if want {
+						w = 1
+					}
+					if got := winding(rings, pq); got != w {
+						t.Errorf("ProjectCellUnion(%v, %s): got winding number %d around %v, want %d", gm, test.name, got, s2.LatLngFromPoint(q), w)
+					}
+				}
+			}
+		}
+	}
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	if got := gm.ProjectCellUnion(nil, maxErr, maxY); got != nil {
+		t.Errorf("ProjectCellUnion(%v, empty): got %v, want nil", gm, got)
+	}
+	var all s2.CellUnion
+	for f := 0; f < 6; f++ {
+		all = append(all, s2.CellIDFromFace(f))
+	}
+	if got := gm.ProjectCellUnion(all, maxErr, maxY); len(got) != 1 || winding(got, r2.Point{}) != 1 {
+		t.Errorf("ProjectCellUnion(%v, full): got %v, want the strip", gm, got)
+	}
+}
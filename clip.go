@@ -0,0 +1,238 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s2"
+)
+
+// ClipPolyline projects line and clips it to the finite viewport rectangle, returning the visible parts as polylines.
+// Unlike ProjectPolyline, ClipPolyline does not split the line at the seam. Instead it treats the map as repeating
+// with period WrapDistance, so that a line crossing the seam continues onto the adjoining copy of the map,
+// and a viewport extending past an edge of the map shows the parts of the line on the adjoining copy.
+// Each visible part of each copy of the line is returned as a separate polyline.
+// An edge to a pole continues to infinity along the projection of its other endpoint's generalized meridian.
+func (gm *GeneralizedMercator) ClipPolyline(line s2.Polyline, viewport r2.Rect) [][]r2.Point {
+	if len(line) == 0 || viewport.IsEmpty() {
+		return nil
+	}
+	pts := gm.unwrapped(line, gm.viewportY(viewport))
+	period := 2 * math.Pi * gm.radius * gm.k0
+	xlo, xhi := gm.viewportX(viewport)
+	lmin, lmax := math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		lmin, lmax = math.Min(lmin, p.X), math.Max(lmax, p.X)
+	}
+	var pieces [][]r2.Point
+	for k := math.Ceil((xlo - lmax) / period); k <= math.Floor((xhi-lmin)/period); k++ {
+		shifted := make([]r2.Point, len(pts))
+		for n, p := range pts {
+			shifted[n] = gm.fromCentered(r2.Point{X: p.X + k*period, Y: p.Y})
+		}
+		pieces = append(pieces, clipPolyline(shifted, viewport)...)
+	}
+	return pieces
+}
+
+// ClipPolygon projects the interior of p and clips it to the finite viewport rectangle, returning the visible part
+// as a set of rings oriented as in ProjectPolygon. As in ClipPolyline, the map repeats with period WrapDistance,
+// and the viewport may extend past its edges. A polygon that contains a pole projects to a band that extends
+// to infinity, and ClipPolygon fills the part of the band within the viewport.
+func (gm *GeneralizedMercator) ClipPolygon(p *s2.Polygon, viewport r2.Rect) [][]r2.Point {
+	if viewport.IsEmpty() {
+		return nil
+	}
+	rings := gm.polygonRings(p, gm.viewportY(viewport))
+	period := 2 * math.Pi * gm.radius * gm.k0
+	e := math.Pi * gm.radius * gm.k0
+	xlo, xhi := gm.viewportX(viewport)
+	var clipped [][]r2.Point
+	for k := math.Ceil((xlo - e) / period); k <= math.Floor((xhi+e)/period); k++ {
+		shifted := make([][]r2.Point, len(rings))
+		for n, ring := range rings {
+			shifted[n] = append([]r2.Point(nil), ring...)
+		}
+		for _, ring := range gm.outRings(shifted, k*period) {
+			if ring = clipRing(ring, viewport); len(ring) >= 3 {
+				clipped = append(clipped, ring)
+			}
+		}
+	}
+	return clipped
+}
+
+// unwrapped projects the vertices of line before the output transformation, with x coordinates measured
+// from the middle of the map and adjusted by multiples of the period to make each nearest to its predecessor.
+// Each vertex at a pole is replaced by points at y == ±maxY above or below its neighbors.
+func (gm *GeneralizedMercator) unwrapped(line s2.Polyline, maxY float64) []r2.Point {
+	period := 2 * math.Pi * gm.radius * gm.k0
+	var pts []r2.Point
+	for n, v := range line {
+		p := gm.projectCentered(v)
+		if !math.IsInf(p.Y, 0) {
+			if len(pts) > 0 {
+				prev := pts[len(pts)-1].X
+				p.X += period * math.Round((prev-p.X)/period)
+			}
+			pts = append(pts, p)
+			continue
+		}
+		y := math.Copysign(maxY, p.Y)
+		if len(pts) > 0 {
+			pts = append(pts, r2.Point{X: pts[len(pts)-1].X, Y: y})
+		}
+		// The line continues from the pole along the meridian of the next vertex that is not a pole.
+		for _, w := range line[n+1:] {
+			if q := gm.projectCentered(w); !math.IsInf(q.Y, 0) {
+				if len(pts) > 0 {
+					q.X += period * math.Round((pts[len(pts)-1].X-q.X)/period)
+				}
+				pts = append(pts, r2.Point{X: q.X, Y: y})
+				break
+			}
+		}
+	}
+	if len(pts) == 0 {
+		// Every vertex is a pole.
+		for _, v := range line {
+			pts = append(pts, r2.Point{Y: math.Copysign(maxY, gm.projectCentered(v).Y)})
+		}
+	}
+	return pts
+}
+
+// viewportCorners returns the corners of viewport before the output transformation,
+// with x coordinates measured from the middle of the map.
+func (gm *GeneralizedMercator) viewportCorners(viewport r2.Rect) [4]r2.Point {
+	c := viewport.Vertices()
+	for n := range c {
+		c[n] = gm.out.invert(c[n])
+		c[n].X -= gm.cut * gm.radius * gm.k0
+	}
+	return c
+}
+
+// viewportX returns the least and greatest x coordinates of viewport before the output transformation,
+// measured from the middle of the map.
+func (gm *GeneralizedMercator) viewportX(viewport r2.Rect) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, c := range gm.viewportCorners(viewport) {
+		lo, hi = math.Min(lo, c.X), math.Max(hi, c.X)
+	}
+	return lo, hi
+}
+
+// viewportY returns a y coordinate beyond which points lie outside viewport after the output transformation.
+func (gm *GeneralizedMercator) viewportY(viewport r2.Rect) float64 {
+	var y float64
+	for _, c := range gm.viewportCorners(viewport) {
+		y = math.Max(y, math.Abs(c.Y))
+	}
+	return 2*y + 1
+}
+
+// clipPolyline returns the parts of the polyline pts inside r.
+func clipPolyline(pts []r2.Point, r r2.Rect) [][]r2.Point {
+	if len(pts) == 1 {
+		if r.ContainsPoint(pts[0]) {
+			return [][]r2.Point{{pts[0]}}
+		}
+		return nil
+	}
+	var pieces [][]r2.Point
+	var piece []r2.Point
+	for n := 1; n < len(pts); n++ {
+		a, b, ok := clipSegment(pts[n-1], pts[n], r)
+		if !ok {
+			continue
+		}
+		if piece == nil || piece[len(piece)-1] != a {
+			if piece != nil {
+				pieces = append(pieces, piece)
+			}
+			piece = []r2.Point{a}
+		}
+		piece = append(piece, b)
+	}
+	if piece != nil {
+		pieces = append(pieces, piece)
+	}
+	return pieces
+}
+
+// clipSegment returns the part of the segment from a to b inside r, and reports whether it is nonempty.
+// It uses the Liang–Barsky algorithm.
+func clipSegment(a, b r2.Point, r r2.Rect) (r2.Point, r2.Point, bool) {
+	d := b.Sub(a)
+	t0, t1 := 0.0, 1.0
+	for _, c := range [4][2]float64{
+		{-d.X, a.X - r.X.Lo},
+		{d.X, r.X.Hi - a.X},
+		{-d.Y, a.Y - r.Y.Lo},
+		{d.Y, r.Y.Hi - a.Y},
+	} {
+		p, q := c[0], c[1]
+		switch {
+		case p == 0:
+			if q < 0 {
+				return r2.Point{}, r2.Point{}, false
+			}
+		case p < 0:
+			t0 = math.Max(t0, q/p)
+		default:
+			t1 = math.Min(t1, q/p)
+		}
+	}
+	if t0 > t1 {
+		return r2.Point{}, r2.Point{}, false
+	}
+	ca, cb := a, b
+	if t0 > 0 {
+		ca = a.Add(d.Mul(t0))
+	}
+	if t1 < 1 {
+		cb = a.Add(d.Mul(t1))
+	}
+	return ca, cb, true
+}
+
+// clipRing returns the part of the closed ring inside r, using the Sutherland–Hodgman algorithm.
+// If the ring enters and leaves r more than once, the result includes edges along the boundary of r
+// joining the visible parts.
+func clipRing(ring []r2.Point, r r2.Rect) []r2.Point {
+	for _, edge := range []struct {
+		inside func(p r2.Point) bool
+		cross  func(a, b r2.Point) r2.Point
+	}{
+		{func(p r2.Point) bool { return p.X >= r.X.Lo }, func(a, b r2.Point) r2.Point { return atX(a, b, r.X.Lo) }},
+		{func(p r2.Point) bool { return p.X <= r.X.Hi }, func(a, b r2.Point) r2.Point { return atX(a, b, r.X.Hi) }},
+		{func(p r2.Point) bool { return p.Y >= r.Y.Lo }, func(a, b r2.Point) r2.Point { return atY(a, b, r.Y.Lo) }},
+		{func(p r2.Point) bool { return p.Y <= r.Y.Hi }, func(a, b r2.Point) r2.Point { return atY(a, b, r.Y.Hi) }},
+	} {
+		var out []r2.Point
+		for n, b := range ring {
+			a := ring[(n+len(ring)-1)%len(ring)]
+			switch ina, inb := edge.inside(a), edge.inside(b); {
+			case ina && inb:
+				out = append(out, b)
+			case ina:
+				out = append(out, edge.cross(a, b))
+			case inb:
+				out = append(out, edge.cross(a, b), b)
+			}
+		}
+		ring = out
+	}
+	return ring
+}
+
+// atX returns the point on the line through a and b with the given x coordinate.
+func atX(a, b r2.Point, x float64) r2.Point {
+	return r2.Point{X: x, Y: a.Y + (b.Y-a.Y)*(x-a.X)/(b.X-a.X)}
+}
+
+// atY returns the point on the line through a and b with the given y coordinate.
+func atY(a, b r2.Point, y float64) r2.Point {
+	return r2.Point{X: a.X + (b.X-a.X)*(y-a.Y)/(b.Y-a.Y), Y: y}
+}
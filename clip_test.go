@@ -0,0 +1,144 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r1"
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s2"
+)
+
+func rect(x0, y0, x1, y1 float64) r2.Rect {
+	return r2.Rect{X: r1.Interval{Lo: x0, Hi: x1}, Y: r1.Interval{Lo: y0, Hi: y1}}
+}
+
+func TestClipPolyline(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	y80 := math.Asinh(math.Tan(80 * pi / 180))
+	for _, test := range []struct {
+		gm       *GeneralizedMercator
+		line     s2.Polyline
+		viewport r2.Rect
+		want     [][]r2.Point
+	}{
+		{mercator, nil, rect(-1, -1, 1, 1), nil},
+		{
+			mercator,
+			polyline([2]float64{0, -10}, [2]float64{0, 10}),
+			rect(-1, -1, 1, 1),
+			[][]r2.Point{{{X: -10 * pi / 180}, {X: 10 * pi / 180}}},
+		},
+		{
+			mercator,
+			polyline([2]float64{0, -10}, [2]float64{0, 10}),
+			rect(-0.1, -1, 0.1, 1),
+			[][]r2.Point{{{X: -0.1}, {X: 0.1}}},
+		},
+		{mercator, polyline([2]float64{0, -10}, [2]float64{0, 10}), rect(-1, 0.5, 1, 1), nil},
+		{
+			// The line continues across the seam.
+			mercator,
+			polyline([2]float64{0, 170}, [2]float64{0, -170}),
+			rect(3, -1, 3.3, 1),
+			[][]r2.Point{{{X: 3}, {X: 3.3}}},
+		},
+		{
+			mercator,
+			polyline([2]float64{0, 170}, [2]float64{0, -170}),
+			rect(-3.3, -1, -3, 1),
+			[][]r2.Point{{{X: -3.3}, {X: -3}}},
+		},
+		{
+			// Both copies of the line are visible.
+			mercator,
+			polyline([2]float64{0, 170}, [2]float64{0, -170}),
+			rect(-4, -1, 4, 1),
+			[][]r2.Point{
+				{{X: -190 * pi / 180}, {X: -170 * pi / 180}},
+				{{X: 170 * pi / 180}, {X: 190 * pi / 180}},
+			},
+		},
+		{
+			// The line leaves and reenters the viewport.
+			mercator,
+			polyline([2]float64{0, -20}, [2]float64{50, 0}, [2]float64{0, 20}),
+			rect(-1, -0.5, 1, 0.5),
+			[][]r2.Point{
+				{{X: -20 * pi / 180}, {X: -20*pi/180 + 0.5/yFromPsi(50*pi/180)*20*pi/180, Y: 0.5}},
+				{{X: 20*pi/180 - 0.5/yFromPsi(50*pi/180)*20*pi/180, Y: 0.5}, {X: 20 * pi / 180}},
+			},
+		},
+		{
+			// An edge to a pole follows the meridian of its other endpoint.
+			mercator,
+			polyline([2]float64{80, 10}, [2]float64{90, 0}, [2]float64{80, 20}),
+			rect(-1, -10, 1, 10),
+			[][]r2.Point{
+				{{X: 10 * pi / 180, Y: y80}, {X: 10 * pi / 180, Y: 10}},
+				{{X: 20 * pi / 180, Y: 10}, {X: 20 * pi / 180, Y: y80}},
+			},
+		},
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithFalseOrigin(100, 5)),
+			polyline([2]float64{0, 170}, [2]float64{0, -170}),
+			rect(103, 4, 103.3, 6),
+			[][]r2.Point{{{X: 103, Y: 5}, {X: 103.3, Y: 5}}},
+		},
+		{
+			// The seam of the rotated map is horizontal.
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithAffine(0, -1, 1, 0, 0, 0)),
+			polyline([2]float64{0, 170}, [2]float64{0, -170}),
+			rect(-1, 3, 1, 3.3),
+			[][]r2.Point{{{Y: 3}, {Y: 3.3}}},
+		},
+	} {
+		got := test.gm.ClipPolyline(test.line, test.viewport)
+		if !piecesApproxEqual(got, test.want) {
+			t.Errorf("ClipPolyline(%v, %v, %v): got %v, want %v", test.gm, test.line, test.viewport, got, test.want)
+		}
+	}
+}
+
+func TestClipPolygon(t *testing.T) {
+	var circle [][2]float64
+	for lng := 0.0; lng < 360; lng += 2 {
+		circle = append(circle, [2]float64{60, lng})
+	}
+	north := s2.PolygonFromLoops([]*s2.Loop{loop(circle...)})
+	south := s2.PolygonFromLoops([]*s2.Loop{inverted(loop(circle...))})
+	box := s2.PolygonFromLoops([]*s2.Loop{rectLoop(0, 170, 10, 190)})
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	y60, y10 := yFromPsi(pi/3), yFromPsi(pi/18)
+	for _, test := range []struct {
+		gm       *GeneralizedMercator
+		p        *s2.Polygon
+		viewport r2.Rect
+		area     float64
+	}{
+		// A polygon containing a pole fills the viewport up to the edge of its band.
+		{mercator, north, rect(-1, 0, 1, 5), 2 * (5 - y60)},
+		{mercator, north, rect(-4, 0, 4, 5), 8 * (5 - y60)},
+		{mercator, north, rect(-1, -5, 1, 1), 0},
+		// The complement of the cap contains the other pole and the whole seam.
+		{mercator, south, rect(-4, -5, 4, 5), 8 * (5 + y60)},
+		{mercator, box, rect(3, -1, 3.3, 1), 0.3 * y10},
+		{mercator, box, rect(-3.3, -1, -3, 1), 0.3 * y10},
+		{mercator, box, rect(-1, -1, 1, 1), 0},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithAffine(0, -1, 1, 0, 0, 0)), north, rect(-5, -1, 0, 1), 2 * (5 - y60)},
+	} {
+		got := test.gm.ClipPolygon(test.p, test.viewport)
+		var area float64
+		for _, ring := range got {
+			area += signedArea(ring)
+			for _, p := range ring {
+				if !test.viewport.ExpandedByMargin(1e-12).ContainsPoint(p) {
+					t.Errorf("ClipPolygon(%v, %v): point %v is outside the viewport", test.gm, test.viewport, p)
+				}
+			}
+		}
+		if math.Abs(area-test.area) > 1e-9 {
+			t.Errorf("ClipPolygon(%v, %v): got area %v, want %v", test.gm, test.viewport, area, test.area)
+		}
+	}
+}
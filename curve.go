@@ -0,0 +1,97 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A Curve is the projection of a geodesic edge, a resolution-independent primitive that can be evaluated
+// and flattened to any precision. Unlike the polylines of ProjectPolyline, a Curve is continuous:
+// if the edge crosses the seam, the Curve continues past the edge of the map onto its adjoining copy.
+type Curve struct {
+	gm   *GeneralizedMercator
+	a, b s2.Point
+
+	// tc is the parameter at which the edge crosses the seam, or 2 if it does not,
+	// and shift is the period added to the projections of the points beyond the crossing.
+	tc    float64
+	shift r2.Point
+}
+
+// EdgeCurve returns the Curve that is the projection of the geodesic edge from a to b.
+func (gm *GeneralizedMercator) EdgeCurve(a, b s2.Point) *Curve {
+	c := &Curve{gm: gm, a: a, b: b, tc: 2}
+	if p, ok := gm.seamCrossing(a, b); ok {
+		c.tc = float64(a.Distance(p) / a.Distance(b))
+		c.shift = gm.WrapDistance()
+		if sin, _, _ := gm.seamSide(a); sin < 0 {
+			c.shift = c.shift.Mul(-1)
+		}
+	}
+	return c
+}
+
+// Eval returns the point of the curve with parameter t in [0, 1]: the projection of the point the fraction t
+// of the way along the edge, adjusted by the period of the projection beyond any crossing of the seam.
+// Eval(0) is the projection of the start of the edge.
+func (c *Curve) Eval(t float64) r2.Point {
+	p := c.gm.ProjectPoint(s2.Interpolate(t, c.a, c.b))
+	if t > c.tc {
+		p = p.Add(c.shift)
+	}
+	return p
+}
+
+// Flatten returns points along the curve, beginning with Eval(0) and ending with Eval(1), chosen adaptively
+// as by ProjectEdge so that the polyline through them deviates from the curve by at most maxErr,
+// which must be positive, measured as an angle on the reference sphere.
+func (c *Curve) Flatten(maxErr s1.Angle) []r2.Point {
+	_, pts := c.gm.flattenEdge(c.a, c.b, c.Eval, maxErr)
+	return pts
+}
+
+// Bounds returns the smallest rectangle containing the curve. If the edge ends at a pole, the rectangle is unbounded.
+func (c *Curve) Bounds() r2.Rect {
+	ts, pts := c.gm.flattenEdge(c.a, c.b, c.Eval, 1e-6)
+	r := r2.RectFromPoints(pts...)
+	// Each extreme coordinate of the curve is near a vertex of the flattened polyline that is extreme
+	// among its neighbors, and is located by maximizing over the adjoining parameter intervals.
+	coord := []func(r2.Point) float64{
+		func(p r2.Point) float64 { return p.X },
+		func(p r2.Point) float64 { return -p.X },
+		func(p r2.Point) float64 { return p.Y },
+		func(p r2.Point) float64 { return -p.Y },
+	}
+	for n := 1; n < len(pts)-1; n++ {
+		for _, f := range coord {
+			if f(pts[n]) >= f(pts[n-1]) && f(pts[n]) >= f(pts[n+1]) {
+				t := goldenMax(func(t float64) float64 { return f(c.Eval(t)) }, ts[n-1], ts[n+1])
+				r = r.AddPoint(c.Eval(t))
+			}
+		}
+	}
+	return r
+}
+
+// goldenMax returns the argument in [lo, hi] that maximizes f, which must be unimodal on the interval,
+// using golden-section search.
+func goldenMax(f func(float64) float64, lo, hi float64) float64 {
+	r := (math.Sqrt(5) - 1) / 2
+	a, b := hi-r*(hi-lo), lo+r*(hi-lo)
+	fa, fb := f(a), f(b)
+	for hi-lo > 1e-15 {
+		if fa < fb {
+			lo, a, fa = a, b, fb
+			b = lo + r*(hi-lo)
+			fb = f(b)
+		} else {
+			hi, b, fb = b, a, fa
+			a = hi - r*(hi-lo)
+			fa = f(a)
+		}
+	}
+	return (lo + hi) / 2
+}
@@ -0,0 +1,70 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s2"
+)
+
+func TestCurve(t *testing.T) {
+	const maxErr = 1e-5
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{New(pos, neg), New(pos, neg, WithAffine(0, -1, 1, 0, 2, 3))} {
+			for _, e := range [][2]s2.LatLng{
+				{s2.LatLngFromDegrees(10, 20), s2.LatLngFromDegrees(-15, 60)},
+				{s2.LatLngFromDegrees(20, 150), s2.LatLngFromDegrees(-10, -140)},
+				{s2.LatLngFromDegrees(70, -30), s2.LatLngFromDegrees(65, 40)},
+			} {
+				a, b := s2.PointFromLatLng(e[0]), s2.PointFromLatLng(e[1])
+				c := gm.EdgeCurve(a, b)
+				if got, want := c.Eval(0), gm.ProjectPoint(a); !ptApproxEqual(got, want) {
+					t.Errorf("EdgeCurve(%v, %v, %v).Eval(0): got %v, want %v", gm, e[0], e[1], got, want)
+				}
+				if got, want := c.Eval(1), gm.WrapDestination(c.Eval(0), gm.ProjectPoint(b)); !ptApproxEqual(got, want) {
+					t.Errorf("EdgeCurve(%v, %v, %v).Eval(1): got %v, want %v", gm, e[0], e[1], got, want)
+				}
+				// The curve is continuous.
+				const steps = 1000
+				for n := 1; n <= steps; n++ {
+					p, q := c.Eval(float64(n-1)/steps), c.Eval(float64(n)/steps)
+					if d := q.Sub(p).Norm(); d > 0.05 {
+						t.Errorf("EdgeCurve(%v, %v, %v): jump of %v at %v", gm, e[0], e[1], d, float64(n)/steps)
+					}
+				}
+				pts := c.Flatten(maxErr)
+				for n := 1; n < len(pts); n++ {
+					for f := 0.0; f <= 1; f += 0.125 {
+						p := pts[n-1].Mul(1 - f).Add(pts[n].Mul(f))
+						if d := s2.DistanceFromSegment(gm.UnprojectPoint(p), a, b); d > 1.01*maxErr {
+							t.Errorf("EdgeCurve(%v, %v, %v).Flatten: point %v is %v from the edge", gm, e[0], e[1], p, d)
+						}
+					}
+				}
+				// The bounds contain the curve and are attained by it.
+				r := c.Bounds()
+				tight := r2.EmptyRect()
+				for n := 0; n <= 10000; n++ {
+					p := c.Eval(float64(n) / 10000)
+					if !r.ExpandedByMargin(1e-12).ContainsPoint(p) {
+						t.Errorf("EdgeCurve(%v, %v, %v).Bounds(): %v does not contain %v", gm, e[0], e[1], r, p)
+						break
+					}
+					tight = tight.AddPoint(p)
+				}
+				if !tight.ExpandedByMargin(1e-6).Contains(r) {
+					t.Errorf("EdgeCurve(%v, %v, %v).Bounds(): got %v, want about %v", gm, e[0], e[1], r, tight)
+				}
+			}
+		}
+	}
+
+	// The bounds of an edge to a pole are unbounded.
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	c := gm.EdgeCurve(s2.PointFromLatLng(s2.LatLngFromDegrees(10, 20)), s2.PointFromLatLng(s2.LatLngFromDegrees(90, 0)))
+	if r := c.Bounds(); !math.IsInf(r.Y.Hi, 1) {
+		t.Errorf("EdgeCurve(%v, to pole).Bounds(): got %v", gm, r)
+	}
+}
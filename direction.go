@@ -0,0 +1,104 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// MeridianDirection returns the unit vector in the plane that points along the generalized meridian through ll
+// toward the projection of Pos: the direction in which to draw a label or arrow that follows the meridian.
+// Since the generalized meridians project to vertical lines before any output transformation, the direction
+// is the same at every location: it is the transformed y axis, which is (0, 1) if there is no output transformation.
+// MeridianDirection returns the zero vector at the poles, where the direction is undefined.
+func (gm *GeneralizedMercator) MeridianDirection(ll s2.LatLng) r2.Point {
+	P := gm.pointFromLatLng(ll).Vector
+	if gm.near(P, gm.pos) || gm.near(P, gm.neg) {
+		return r2.Point{}
+	}
+	return gm.out.applyLinear(r2.Point{Y: 1}).Normalize()
+}
+
+// A Compass describes the directions from a location toward the poles of a projection,
+// as produced by GeneralizedMercator.Compass.
+type Compass struct {
+	// PosBearing and NegBearing are the initial bearings of the geodesics from the location toward Pos and Neg,
+	// measured clockwise from geographic north, in the interval (-π, π].
+	PosBearing, NegBearing s1.Angle
+
+	// PosDirection and NegDirection are the unit vectors in the plane along the projections of those geodesics
+	// at the projection of the location.
+	PosDirection, NegDirection r2.Point
+}
+
+// Compass returns the directions from ll toward the poles of the projection, on the reference sphere and in the plane,
+// for drawing a compass rose on an oblique map. The geodesic toward a pole is undefined at the pole itself
+// and at its antipode; the corresponding bearing and direction are then zero, as are both directions at either pole.
+// At a geographic pole, bearings are measured from the meridian of ll.Lng.
+func (gm *GeneralizedMercator) Compass(ll s2.LatLng) Compass {
+	P := gm.pointFromLatLng(ll).Vector
+	east, north := localFrame(P, ll.Lng)
+	var c Compass
+	atPole := gm.near(P, gm.pos) || gm.near(P, gm.neg)
+	for _, q := range []struct {
+		pole      r3.Vector
+		bearing   *s1.Angle
+		direction *r2.Point
+	}{
+		{gm.pos, &c.PosBearing, &c.PosDirection},
+		{gm.neg, &c.NegBearing, &c.NegDirection},
+	} {
+		if gm.near(P, q.pole) || gm.near(P, q.pole.Mul(-1)) {
+			continue
+		}
+		// The geodesic toward the pole leaves P along the component of the pole orthogonal to P.
+		t := q.pole.Sub(P.Mul(P.Dot(q.pole))).Normalize()
+		b := math.Atan2(t.Dot(east), t.Dot(north))
+		if b == -math.Pi {
+			// A bearing due south is π, whatever the sign of the rounding error in its east component.
+			b = math.Pi
+		}
+		*q.bearing = s1.Angle(b)
+		if !atPole {
+			*q.direction = gm.tangentDirection(P, t)
+		}
+	}
+	return c
+}
+
+// ProjectTangent returns the projected vector corresponding to the tangent vector v at ll, whose components
+// are its eastward and northward parts: if a location moves from ll with velocity v on the reference sphere,
+// its projection moves with the returned velocity in the plane. The components of v are measured in the units
+// of the radius of the reference sphere, and the result in projected units, including any output transformation.
+// Its direction is generally rotated from that of v, by an angle that depends on ll unless the poles are
+// the geographic poles, and its length is scaled by the scale factor in that direction, as by ScaleFactors.
+// With WithGeodeticLatitude, v is a tangent vector of the reference sphere at the point corresponding to ll.
+// At a geographic pole, east is the direction of increasing longitude at ll.Lng. ProjectTangent returns
+// a vector with NaN coordinates at the poles of the projection, where the scale is infinite.
+func (gm *GeneralizedMercator) ProjectTangent(ll s2.LatLng, v r2.Point) r2.Point {
+	P := gm.pointFromLatLng(ll).Vector
+	if gm.near(P, gm.pos) || gm.near(P, gm.neg) {
+		return r2.Point{X: math.NaN(), Y: math.NaN()}
+	}
+	east, north := localFrame(P, ll.Lng)
+	t := east.Mul(v.X).Add(north.Mul(v.Y))
+	gx, gy := gm.gradients(P)
+	return gm.out.applyLinear(r2.Point{X: gx.Dot(t), Y: gy.Dot(t)}.Mul(gm.k0))
+}
+
+// localFrame returns the unit vectors pointing east and north at P, whose longitude is lng.
+func localFrame(P r3.Vector, lng s1.Angle) (east, north r3.Vector) {
+	sin, cos := math.Sincos(float64(lng))
+	east = r3.Vector{X: -sin, Y: cos}
+	return east, P.Cross(east)
+}
+
+// tangentDirection returns the unit vector in the plane along the projection of the tangent vector t at P,
+// which must not be a pole.
+func (gm *GeneralizedMercator) tangentDirection(P, t r3.Vector) r2.Point {
+	gx, gy := gm.gradients(P)
+	return gm.out.applyLinear(r2.Point{X: gx.Dot(t), Y: gy.Dot(t)}).Normalize()
+}
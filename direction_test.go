@@ -0,0 +1,107 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestMeridianDirection(t *testing.T) {
+	for _, test := range []struct {
+		gm   *GeneralizedMercator
+		want r2.Point
+	}{
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), r2.Point{Y: 1}},
+		{New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150), WithScaleFactor(2)), r2.Point{Y: 1}},
+		{New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150), WithAffine(0, -2, 2, 0, 5, 1)), r2.Point{X: -1}},
+	} {
+		for _, ll := range []s2.LatLng{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(45, 10), s2.LatLngFromDegrees(-70, 120)} {
+			got := test.gm.MeridianDirection(ll)
+			if !ptApproxEqual(got, test.want) {
+				t.Errorf("MeridianDirection(%v, %v): got %v, want %v", test.gm, ll, got, test.want)
+			}
+			// A small step toward Pos along the meridian projects in that direction.
+			x, psi := test.gm.ToGeneralized(ll)
+			p, q := test.gm.Project(ll), test.gm.Project(test.gm.FromGeneralized(x, psi+1e-7))
+			if d := q.Sub(p).Normalize(); d.Sub(got).Norm() > 1e-6 {
+				t.Errorf("MeridianDirection(%v, %v): got %v, but the meridian projects toward %v", test.gm, ll, got, d)
+			}
+		}
+		pos, _ := test.gm.Poles()
+		if got := test.gm.MeridianDirection(pos); got != (r2.Point{}) {
+			t.Errorf("MeridianDirection(%v, %v): got %v, want the zero vector", test.gm, pos, got)
+		}
+	}
+}
+
+func TestCompass(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	if got, want := mercator.Compass(s2.LatLngFromDegrees(10, 20)), (Compass{0, pi, r2.Point{Y: 1}, r2.Point{Y: -1}}); math.Abs(float64(got.PosBearing-want.PosBearing)) > 1e-15 ||
+		math.Abs(float64(got.NegBearing-want.NegBearing)) > 1e-15 || got.PosDirection.Sub(want.PosDirection).Norm() > 1e-15 || got.NegDirection.Sub(want.NegDirection).Norm() > 1e-15 {
+		t.Errorf("Compass(%v): got %v, want %v", mercator, got, want)
+	}
+	equatorial := New(s2.LatLngFromDegrees(0, 90), s2.LatLngFromDegrees(0, -90))
+	if got := equatorial.Compass(s2.LatLngFromDegrees(0, 0)); math.Abs(float64(got.PosBearing)-pi/2) > 1e-15 || math.Abs(float64(got.NegBearing)+pi/2) > 1e-15 {
+		t.Errorf("Compass(%v): got %v, want bearings of 90° and -90°", equatorial, got)
+	}
+
+	for _, gm := range []*GeneralizedMercator{
+		New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150)),
+		New(s2.LatLngFromDegrees(30, 40), s2.LatLngFromDegrees(-30, -140), WithAffine(0, -2, 2, 1, 5, 1)),
+	} {
+		pos, neg := gm.Poles()
+		for _, ll := range []s2.LatLng{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(45, 10), s2.LatLngFromDegrees(-70, 120)} {
+			c := gm.Compass(ll)
+			P := gm.pointFromLatLng(ll)
+			for _, test := range []struct {
+				pole      s2.LatLng
+				bearing   s1.Angle
+				direction r2.Point
+			}{
+				{pos, c.PosBearing, c.PosDirection},
+				{neg, c.NegBearing, c.NegDirection},
+			} {
+				// A small step along the geodesic toward the pole projects in the reported direction.
+				Q := s2.InterpolateAtDistance(1e-7, P, gm.pointFromLatLng(test.pole))
+				if d := gm.ProjectPoint(Q).Sub(gm.ProjectPoint(P)).Normalize(); d.Sub(test.direction).Norm() > 1e-6 {
+					t.Errorf("Compass(%v, %v): got direction %v toward %v, but the geodesic projects toward %v", gm, ll, test.direction, test.pole, d)
+				}
+				// The bearing agrees with the change in latitude and longitude along the geodesic.
+				q := s2.LatLngFromPoint(Q)
+				p := s2.LatLngFromPoint(P)
+				bearing := math.Atan2(float64(q.Lng-p.Lng)*math.Cos(float64(p.Lat)), float64(q.Lat-p.Lat))
+				if math.Abs(bearing-float64(test.bearing)) > 1e-6 {
+					t.Errorf("Compass(%v, %v): got bearing %v toward %v, want %v", gm, ll, test.bearing, test.pole, s1.Angle(bearing))
+				}
+			}
+		}
+		if got := gm.Compass(pos); got.PosDirection != (r2.Point{}) || got.NegDirection != (r2.Point{}) || got.PosBearing != 0 {
+			t.Errorf("Compass(%v, %v): got %v, want zero directions and bearing toward Pos", gm, pos, got)
+		}
+	}
+}
+
+func TestProjectTangent(t *testing.T) {
+	for _, test := range scaleTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{test.gm, New(pos, neg, WithRadius(3), WithScaleFactor(0.9), WithAffine(0, -2, 2, 1, 5, 1))} {
+			for _, ll := range test.lls {
+				// The Jacobian of Project with respect to displacement on the unit sphere, divided by the radius,
+				// maps the tangent vector.
+				e, n := numericJacobian(gm, ll)
+				for _, v := range []r2.Point{{X: 1}, {Y: 1}, {X: 3, Y: -2}} {
+					want := e.Mul(v.X).Add(n.Mul(v.Y)).Mul(1 / gm.Radius())
+					if got := gm.ProjectTangent(ll, v); got.Sub(want).Norm() > 1e-6*want.Norm() {
+						t.Errorf("ProjectTangent(%v, %v, %v): got %v, want %v", gm, ll, v, got, want)
+					}
+				}
+			}
+			if got := gm.ProjectTangent(pos, r2.Point{X: 1}); !math.IsNaN(got.X) || !math.IsNaN(got.Y) {
+				t.Errorf("ProjectTangent(%v, %v): got %v, want NaN", gm, pos, got)
+			}
+		}
+	}
+}
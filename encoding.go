@@ -0,0 +1,351 @@
+package gm
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A param is an optional parameter of a GeneralizedMercator, as recorded in its encodings.
+type param struct {
+	// key identifies the parameter in the text encoding, and name is the name of the corresponding Option,
+	// or "" if the parameter is set by an Option that takes more than one value or none.
+	key, name string
+
+	// value returns the value of the parameter and whether it differs from the default.
+	value func(*GeneralizedMercator) (v float64, set bool)
+
+	// option returns the Option that sets the parameter to v.
+	option func(v float64) Option
+}
+
+// params lists the optional parameters of a GeneralizedMercator in the order in which they are encoded.
+var params = []param{
+	{"r", "WithRadius", func(gm *GeneralizedMercator) (float64, bool) { return gm.radius, gm.radius != 1 }, WithRadius},
+	{"k0", "WithScaleFactor", func(gm *GeneralizedMercator) (float64, bool) { return gm.k0, gm.k0 != 1 }, WithScaleFactor},
+	{"f", "WithGeodeticLatitude", func(gm *GeneralizedMercator) (float64, bool) { return gm.flattening, gm.flattening != 0 }, WithGeodeticLatitude},
+	{"x0", "WithCentralLongitude", func(gm *GeneralizedMercator) (float64, bool) { return gm.x0, gm.x0 != 0 }, WithCentralLongitude},
+	{"s", "WithSeam", func(gm *GeneralizedMercator) (float64, bool) { return gm.cut + math.Pi, gm.cut != 0 }, WithSeam},
+	{"ss", "WithSeamSide", func(gm *GeneralizedMercator) (float64, bool) { return float64(gm.side), gm.side != SeamAsComputed }, func(v float64) Option { return WithSeamSide(SeamSide(v)) }},
+	{"t", "WithTruncation", func(gm *GeneralizedMercator) (float64, bool) { return gm.psiMax, gm.psiMax != 0 }, func(v float64) Option { return WithTruncation(s1.Angle(v)) }},
+	{"ep", "WithExtendedPrecision", func(gm *GeneralizedMercator) (float64, bool) { return gm.extended, gm.extended != 0 }, func(v float64) Option { return WithExtendedPrecision(s1.Angle(v)) }},
+	{"tol", "WithTolerance", func(gm *GeneralizedMercator) (float64, bool) { return gm.tol, gm.tol != DefaultTolerance }, WithTolerance},
+	{"llp", "WithLatLngPolicy", func(gm *GeneralizedMercator) (float64, bool) { return float64(gm.policy), gm.policy != LatLngAsGiven }, func(v float64) Option { return WithLatLngPolicy(LatLngPolicy(v)) }},
+	{"xp", "", func(gm *GeneralizedMercator) (float64, bool) { return boolParam(gm.exact), gm.exact }, func(v float64) Option { return withExactPoles(v != 0) }},
+	outParam("m11", func(t *affine) *float64 { return &t.a }),
+	outParam("m12", func(t *affine) *float64 { return &t.b }),
+	outParam("m21", func(t *affine) *float64 { return &t.c }),
+	outParam("m22", func(t *affine) *float64 { return &t.d }),
+	outParam("fe", func(t *affine) *float64 { return &t.e }),
+	outParam("fn", func(t *affine) *float64 { return &t.f }),
+}
+
+// boolParam returns 1 if b is true and 0 otherwise, the value of a param that is set by an Option without arguments.
+func boolParam(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// options returns the Options that set the parameters of a new projection to those of gm.
+func (gm *GeneralizedMercator) options() []Option {
+	opts := make([]Option, len(params))
+	for n, p := range params {
+		v, _ := p.value(gm)
+		opts[n] = p.option(v)
+	}
+	return opts
+}
+
+// outParam returns the param for the coefficient of the output transformation selected by field.
+// The transformation is validated when the projection is constructed.
+func outParam(key string, field func(*affine) *float64) param {
+	def := *field(&identity)
+	return param{
+		key: key,
+		value: func(gm *GeneralizedMercator) (float64, bool) {
+			v := *field(&gm.out)
+			return v, v != def
+		},
+		option: func(v float64) Option {
+			return func(gm *GeneralizedMercator) error {
+				*field(&gm.out) = v
+				return nil
+			}
+		},
+	}
+}
+
+// binaryVersion is the version number of the binary encoding produced by MarshalBinary.
+// It must be incremented whenever the layout of the encoding changes.
+//
+// The encoding is the version byte followed by the components of Pos and Neg as little-endian IEEE 754 float64 values
+// and then, for each param that differs from its default, in the order of params, the length in bytes of its key,
+// the key, and its value as a float64. Since the params are identified by their keys, adding a param does not change
+// the layout, nor the encoding of projections that leave the param at its default.
+const binaryVersion = 1
+
+var errInvalidBinary = errors.New("gm: invalid binary encoding")
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (gm *GeneralizedMercator) MarshalBinary() ([]byte, error) {
+	b := []byte{binaryVersion}
+	for _, v := range []float64{gm.pos.X, gm.pos.Y, gm.pos.Z, gm.neg.X, gm.neg.Y, gm.neg.Z} {
+		b = appendFloat64(b, v)
+	}
+	for _, p := range params {
+		if v, ok := p.value(gm); ok {
+			b = append(b, byte(len(p.key)))
+			b = append(b, p.key...)
+			b = appendFloat64(b, v)
+		}
+	}
+	return b, nil
+}
+
+// appendFloat64 appends v to b as a little-endian IEEE 754 float64 value.
+func appendFloat64(b []byte, v float64) []byte {
+	// Adding zero normalizes negative zero so that equal projections have identical encodings.
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v+0))
+}
+
+// readFloat64 returns the little-endian IEEE 754 float64 value at the beginning of b.
+func readFloat64(b []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (gm *GeneralizedMercator) UnmarshalBinary(data []byte) error {
+	if len(data) < 1+6*8 || data[0] != binaryVersion {
+		return errInvalidBinary
+	}
+	var f [6]float64
+	for n := range f {
+		f[n] = readFloat64(data[1+8*n:])
+	}
+	pos, neg := r3.Vector{X: f[0], Y: f[1], Z: f[2]}, r3.Vector{X: f[3], Y: f[4], Z: f[5]}
+	if !isUnit(pos) || !isUnit(neg) {
+		return errInvalidBinary
+	}
+	var opts []Option
+	seen := make(map[string]bool)
+	for rest := data[1+6*8:]; len(rest) > 0; {
+		n := int(rest[0])
+		if len(rest) < 1+n+8 {
+			return errInvalidBinary
+		}
+		key := string(rest[1 : 1+n])
+		opt, ok := paramOption(key, readFloat64(rest[1+n:]))
+		if !ok || seen[key] {
+			return errInvalidBinary
+		}
+		seen[key] = true
+		opts = append(opts, opt)
+		rest = rest[1+n+8:]
+	}
+	g, err := newGM(pos, neg, opts...)
+	if err != nil {
+		return err
+	}
+	*gm = *g
+	return nil
+}
+
+// paramOption returns the Option that sets the param identified by key to v,
+// and reports whether there is such a param.
+func paramOption(key string, v float64) (Option, bool) {
+	for _, p := range params {
+		if p.key == key {
+			return p.option(v), true
+		}
+	}
+	return nil, false
+}
+
+// isUnit reports whether v is a finite vector of approximately unit length.
+func isUnit(v r3.Vector) bool {
+	return math.Abs(v.Norm2()-1) < 1e-14
+}
+
+// Key returns a deterministic identifier for the projection, suitable for use as a map key or in a file name.
+// Projections that are Equal have the same Key. The Key is derived from the binary encoding,
+// so it changes only with the version of the encoding.
+func (gm *GeneralizedMercator) Key() string {
+	b, _ := gm.MarshalBinary()
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16])
+}
+
+// String returns a human-readable description of the projection's poles in degrees
+// and any parameters that differ from their defaults.
+func (gm *GeneralizedMercator) String() string {
+	pos, neg := gm.Poles()
+	s := fmt.Sprintf("GeneralizedMercator{pos: %v, neg: %v", pos, neg)
+	for _, p := range params {
+		if v, ok := p.value(gm); ok {
+			s += fmt.Sprintf(", %s: %v", p.key, v)
+		}
+	}
+	return s + "}"
+}
+
+// GoString returns Go syntax that constructs an equivalent projection.
+func (gm *GeneralizedMercator) GoString() string {
+	pos, neg := gm.Poles()
+	s := fmt.Sprintf("gm.New(%s, %s", goLatLng(pos), goLatLng(neg))
+	for _, p := range params {
+		if v, ok := p.value(gm); ok && p.name != "" {
+			s += fmt.Sprintf(", gm.%s(%s)", p.name, goFloat(v))
+		}
+	}
+	if gm.exact {
+		s += ", gm.WithExactPoles()"
+	}
+	switch t := gm.out; {
+	case t == identity:
+	case t.a == 1 && t.b == 0 && t.c == 0 && t.d == 1:
+		s += fmt.Sprintf(", gm.WithFalseOrigin(%s, %s)", goFloat(t.e), goFloat(t.f))
+	default:
+		s += fmt.Sprintf(", gm.WithAffine(%s, %s, %s, %s, %s, %s)",
+			goFloat(t.a), goFloat(t.b), goFloat(t.c), goFloat(t.d), goFloat(t.e), goFloat(t.f))
+	}
+	return s + ")"
+}
+
+// goLatLng returns Go syntax that constructs ll.
+func goLatLng(ll s2.LatLng) string {
+	return fmt.Sprintf("s2.LatLngFromDegrees(%s, %s)", goFloat(ll.Lat.Degrees()), goFloat(ll.Lng.Degrees()))
+}
+
+// goFloat returns Go syntax for v.
+func goFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// textPrefix begins the text encoding of a GeneralizedMercator.
+const textPrefix = "gm:"
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The text form of a GeneralizedMercator consists of the prefix "gm:" followed by the latitude and longitude
+// of the positive and negative poles in degrees, separated by a comma within each pole
+// and a semicolon between the poles, as in "gm:48.2,16.4;-33.9,151.2".
+// Each parameter that differs from its default follows as a further semicolon-separated key=value pair,
+// as in "gm:48.2,16.4;-33.9,151.2;r=6378137".
+func (gm *GeneralizedMercator) MarshalText() ([]byte, error) {
+	pos, neg := gm.Poles()
+	b := []byte(textPrefix)
+	b = strconv.AppendFloat(b, pos.Lat.Degrees(), 'f', -1, 64)
+	b = append(b, ',')
+	b = strconv.AppendFloat(b, pos.Lng.Degrees(), 'f', -1, 64)
+	b = append(b, ';')
+	b = strconv.AppendFloat(b, neg.Lat.Degrees(), 'f', -1, 64)
+	b = append(b, ',')
+	b = strconv.AppendFloat(b, neg.Lng.Degrees(), 'f', -1, 64)
+	for _, p := range params {
+		if v, ok := p.value(gm); ok {
+			b = append(b, ';')
+			b = append(b, p.key...)
+			b = append(b, '=')
+			b = strconv.AppendFloat(b, v, 'f', -1, 64)
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It accepts the format produced by MarshalText.
+func (gm *GeneralizedMercator) UnmarshalText(text []byte) error {
+	g, err := ParseGM(string(text))
+	if err != nil {
+		return err
+	}
+	*gm = *g
+	return nil
+}
+
+// ParseGM parses the text form of a GeneralizedMercator produced by MarshalText.
+// The Unicode minus sign U+2212 is accepted in place of a hyphen-minus.
+func ParseGM(s string) (*GeneralizedMercator, error) {
+	rest, ok := strings.CutPrefix(s, textPrefix)
+	if !ok {
+		return nil, fmt.Errorf("gm: parsing %q: missing prefix %q", s, textPrefix)
+	}
+	fields := strings.Split(strings.ReplaceAll(rest, "\u2212", "-"), ";")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("gm: parsing %q: want two poles separated by ';'", s)
+	}
+	pos, err := parseLatLng(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("gm: parsing %q: %v", s, err)
+	}
+	neg, err := parseLatLng(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("gm: parsing %q: %v", s, err)
+	}
+	opts, err := parseParams(fields[2:])
+	if err != nil {
+		return nil, fmt.Errorf("gm: parsing %q: %v", s, err)
+	}
+	gm, err := newFromLatLngs(pos, neg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("gm: parsing %q: %v", s, err)
+	}
+	return gm, nil
+}
+
+// parseLatLng parses a latitude and longitude in degrees separated by a comma.
+func parseLatLng(s string) (s2.LatLng, error) {
+	lat, lng, ok := strings.Cut(s, ",")
+	if !ok {
+		return s2.LatLng{}, fmt.Errorf("want latitude and longitude separated by ',' in %q", s)
+	}
+	la, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil {
+		return s2.LatLng{}, err
+	}
+	ln, err := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+	if err != nil {
+		return s2.LatLng{}, err
+	}
+	ll := s2.LatLngFromDegrees(la, ln)
+	if !ll.IsValid() {
+		return s2.LatLng{}, fmt.Errorf("invalid location %v", ll)
+	}
+	return ll, nil
+}
+
+// parseParams parses key=value parameter fields into the corresponding Options.
+func parseParams(fields []string) ([]Option, error) {
+	var opts []Option
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		key, val, ok := strings.Cut(strings.TrimSpace(f), "=")
+		if !ok {
+			return nil, fmt.Errorf("want key=value parameter in %q", f)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate parameter %q", key)
+		}
+		seen[key] = true
+		v, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, err
+		}
+		opt, ok := paramOption(key, v)
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %q", key)
+		}
+		opts = append(opts, opt)
+	}
+	return opts, nil
+}
@@ -0,0 +1,255 @@
+package gm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+var encodingTests = []*GeneralizedMercator{
+	New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+	New(s2.LatLngFromDegrees(0, 45), s2.LatLngFromDegrees(0, -135)),
+	New(s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30)),
+	New(s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2)),
+	New(s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2), WithRadius(6378137)),
+	New(s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2), WithRadius(6378137), WithGeodeticLatitude(WGS84Flattening)),
+	New(s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2), WithCentralPoint(s2.LatLngFromDegrees(40.7, -74))),
+	NewWebMercator(),
+	NewCentered(s2.LatLngFromDegrees(45, -100), 30*s1.Degree, WithScaleFactor(0.9996)),
+	New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithFalseOrigin(500000, -10000000)),
+	New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10), WithAffine(0.5, -0.25, 0.25, 0.5, 100, 200)),
+	New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeam(1)),
+	New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10), WithSeamSide(SeamNegative)),
+	New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10), WithExtendedPrecision(1e-3)),
+	New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10), WithTolerance(1e-12)),
+	New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(0, 90), WithExactPoles()),
+	New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10), WithLatLngPolicy(LatLngRejected)),
+}
+
+func TestBinary(t *testing.T) {
+	for _, gm := range encodingTests {
+		b, err := gm.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(%+v): %v", gm, err)
+		}
+		got := new(GeneralizedMercator)
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Fatalf("UnmarshalBinary(%v): %v", b, err)
+		}
+		if *got != *gm {
+			t.Errorf("UnmarshalBinary(%v): got %+v, want %+v", b, got, gm)
+		}
+	}
+}
+
+// binaryGolden pairs projections with their binary encodings. A change to the encoding must increment binaryVersion.
+var binaryGolden = []struct {
+	gm  *GeneralizedMercator
+	hex string
+}{
+	{
+		New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10)),
+		"01ab4c58e87ab6eb3f0000000000000000ffffffffffffdf3f1a1c818c8b83df3f8a730b7e1a3ab63faa4c58e87ab6ebbf",
+	},
+	{
+		New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10), WithRadius(6378137), WithAffine(0.5, -0.25, 0.25, 0.5, 100, 200)),
+		"01ab4c58e87ab6eb3f0000000000000000ffffffffffffdf3f1a1c818c8b83df3f8a730b7e1a3ab63faa4c58e87ab6ebbf" +
+			"017200000040a6545841" +
+			"036d3131000000000000e03f036d3132000000000000d0bf036d3231000000000000d03f036d3232000000000000e03f" +
+			"026665000000000000594002666e0000000000006940",
+	},
+}
+
+func TestBinaryGolden(t *testing.T) {
+	for _, test := range binaryGolden {
+		if b, _ := test.gm.MarshalBinary(); hex.EncodeToString(b) != test.hex {
+			t.Errorf("MarshalBinary(%+v): got %x, want %s", test.gm, b, test.hex)
+		}
+		b, err := hex.DecodeString(test.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := new(GeneralizedMercator)
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Errorf("UnmarshalBinary(%s): %v", test.hex, err)
+		} else if *got != *test.gm {
+			t.Errorf("UnmarshalBinary(%s): got %+v, want %+v", test.hex, got, test.gm)
+		}
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	valid, _ := encodingTests[0].MarshalBinary()
+	coincident, _ := encodingTests[0].MarshalBinary()
+	copy(coincident[25:], coincident[1:25])
+	param := func(key string, v float64) []byte {
+		return binary.LittleEndian.AppendUint64(append([]byte{byte(len(key))}, key...), math.Float64bits(v))
+	}
+	for _, b := range [][]byte{
+		nil,
+		valid[:len(valid)-1],
+		append([]byte{0}, valid[1:]...),
+		append([]byte{binaryVersion + 1}, valid[1:]...),
+		append(append([]byte{}, valid[:9]...), make([]byte, 40)...),
+		coincident,
+		append(append([]byte{}, valid...), param("q", 1)...),
+		append(append(append([]byte{}, valid...), param("r", 2)...), param("r", 3)...),
+		append(append([]byte{}, valid...), param("r", 2)[:9]...),
+	} {
+		if err := new(GeneralizedMercator).UnmarshalBinary(b); err == nil {
+			t.Errorf("UnmarshalBinary(%v): got nil error", b)
+		}
+	}
+}
+
+func TestGob(t *testing.T) {
+	for _, gm := range encodingTests {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(gm); err != nil {
+			t.Fatalf("Encode(%+v): %v", gm, err)
+		}
+		got := new(GeneralizedMercator)
+		if err := gob.NewDecoder(&buf).Decode(got); err != nil {
+			t.Fatalf("Decode(%+v): %v", gm, err)
+		}
+		if *got != *gm {
+			t.Errorf("gob round trip: got %+v, want %+v", got, gm)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	for _, gm := range encodingTests {
+		b, err := gm.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%+v): %v", gm, err)
+		}
+		got := new(GeneralizedMercator)
+		if err := got.UnmarshalText(b); err != nil {
+			t.Fatalf("UnmarshalText(%q): %v", b, err)
+		}
+		if !got.ApproxEqual(gm, 1e-14) || got.radius != gm.radius || got.flattening != gm.flattening || got.x0 != gm.x0 {
+			t.Errorf("UnmarshalText(%q): got %+v, want %+v", b, got, gm)
+		}
+	}
+}
+
+func TestParseGM(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		p, n s2.LatLng
+		opts []Option
+	}{
+		{"gm:90,0;-90,0", s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), nil},
+		{"gm:48.2,16.4;-33.9,151.2", s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2), nil},
+		{"gm:48.2,16.4;\u221233.9,151.2", s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2), nil},
+		{"gm: 60, 30; -60, 30", s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30), nil},
+		{"gm:90,0;-90,0;r=6378137", s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), []Option{WithRadius(6378137)}},
+	} {
+		got, err := ParseGM(test.s)
+		if err != nil {
+			t.Errorf("ParseGM(%q): %v", test.s, err)
+			continue
+		}
+		if want := New(test.p, test.n, test.opts...); !gmApproxEqual(got, want) || got.radius != want.radius {
+			t.Errorf("ParseGM(%q): got %+v, want %+v", test.s, got, want)
+		}
+	}
+	for _, s := range []string{
+		"",
+		"48.2,16.4;-33.9,151.2",
+		"gm:48.2,16.4",
+		"gm:48.2;-33.9,151.2",
+		"gm:48.2,x;-33.9,151.2",
+		"gm:100,0;-90,0",
+		"gm:10,20;10,20",
+		"gm:90,0;-90,0;r",
+		"gm:90,0;-90,0;r=-1",
+		"gm:90,0;-90,0;r=2;r=3",
+		"gm:90,0;-90,0;q=1",
+	} {
+		if _, err := ParseGM(s); err == nil {
+			t.Errorf("ParseGM(%q): got nil error", s)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	keys := make(map[string]*GeneralizedMercator)
+	for _, gm := range encodingTests {
+		k := gm.Key()
+		if other, ok := keys[k]; ok {
+			t.Errorf("Key(%+v) == Key(%+v) == %q", gm, other, k)
+		}
+		keys[k] = gm
+		b, _ := gm.MarshalBinary()
+		g := new(GeneralizedMercator)
+		if err := g.UnmarshalBinary(b); err != nil {
+			t.Fatalf("UnmarshalBinary(%v): %v", b, err)
+		}
+		if got := g.Key(); got != k {
+			t.Errorf("Key(%+v) after binary round trip: got %q, want %q", gm, got, k)
+		}
+		if strings.Trim(k, "0123456789abcdef") != "" {
+			t.Errorf("Key(%+v): %q contains characters other than lowercase hexadecimal digits", gm, k)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2))
+	if got, want := gm.String(), "GeneralizedMercator{pos: [48.2000000, 16.4000000], neg: [-33.9000000, 151.2000000]}"; got != want {
+		t.Errorf("String: got %q, want %q", got, want)
+	}
+	if got, want := fmt.Sprintf("%v", gm), gm.String(); got != want {
+		t.Errorf("%%v: got %q, want %q", got, want)
+	}
+	gm = New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithRadius(6378137))
+	if got, want := gm.String(), "GeneralizedMercator{pos: [90.0000000, 0.0000000], neg: [-90.0000000, 0.0000000], r: 6.378137e+06}"; got != want {
+		t.Errorf("String: got %q, want %q", got, want)
+	}
+}
+
+func TestGoString(t *testing.T) {
+	for _, test := range []struct {
+		gm   *GeneralizedMercator
+		want string
+	}{
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+			"gm.New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))",
+		},
+		{
+			New(s2.LatLngFromDegrees(0, 90), s2.LatLngFromDegrees(0, -90)),
+			"gm.New(s2.LatLngFromDegrees(0, 90), s2.LatLngFromDegrees(0, -90))",
+		},
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithRadius(6378137)),
+			"gm.New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), gm.WithRadius(6378137))",
+		},
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithFalseOrigin(500000, 0)),
+			"gm.New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), gm.WithFalseOrigin(500000, 0))",
+		},
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithAffine(0, -1, 1, 0, 2, 3)),
+			"gm.New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), gm.WithAffine(0, -1, 1, 0, 2, 3))",
+		},
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithExactPoles()),
+			"gm.New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), gm.WithExactPoles())",
+		},
+	} {
+		if got := fmt.Sprintf("%#v", test.gm); got != test.want {
+			t.Errorf("GoString: got %q, want %q", got, test.want)
+		}
+	}
+}
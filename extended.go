@@ -0,0 +1,204 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s2"
+)
+
+/*
+Near a pole, the coordinates of a point along the i' and j axes of the rotated basis are small, and so is the distance
+from the pole that they determine, but the dot products with the basis vectors from which they follow sum terms
+near 1 in magnitude. Their absolute error is that of float64 values near 1, about 1e-16, so their relative error,
+and that of the distance from the pole, grows as the distance shrinks. Since the projected y coordinate is approximately
+ln(2/δ) at a distance δ from the pole, and the scale of the projection is approximately 1/δ, a relative error ε in δ
+displaces the projected point by ε in y, which is about 1e-16/δ: at the Earth's radius, a meter at δ == 1e-10.
+The basis vectors are themselves rounded, which displaces the pole of the computed basis from the pole itself
+by a comparable distance and has the same effect.
+
+The extended-precision path takes the dot products with the small displacement of the point from the nearer pole,
+whose own coordinates are known exactly, and evaluates them and the rotation in double-double arithmetic, in which
+each value is the unevaluated sum of two float64 values, carrying about 106 bits of precision, so that the small
+coordinates are accurate to the precision of float64 values of their own magnitude. It computes y from their ratio
+to the large one, tan(ψ), rather than from ψ itself, whose complement is lost to rounding near π/2.
+*/
+
+// A dd is a double-double number: the unevaluated sum hi + lo of two float64 values with |lo| at most half an ulp of hi.
+// The operations follow Dekker and Knuth, with exact products computed by fused multiply-add.
+type dd struct {
+	hi, lo float64
+}
+
+// twoSum returns a + b exactly.
+func twoSum(a, b float64) dd {
+	s := a + b
+	v := s - a
+	return dd{s, (a - (s - v)) + (b - v)}
+}
+
+// quickTwoSum returns a + b exactly, provided that |a| >= |b| or a == 0.
+func quickTwoSum(a, b float64) dd {
+	s := a + b
+	return dd{s, b - (s - a)}
+}
+
+// twoProd returns a * b exactly.
+func twoProd(a, b float64) dd {
+	p := a * b
+	return dd{p, math.FMA(a, b, -p)}
+}
+
+func (x dd) add(y dd) dd {
+	s, t := twoSum(x.hi, y.hi), twoSum(x.lo, y.lo)
+	s = quickTwoSum(s.hi, s.lo+t.hi)
+	return quickTwoSum(s.hi, s.lo+t.lo)
+}
+
+func (x dd) sub(y dd) dd {
+	return x.add(dd{-y.hi, -y.lo})
+}
+
+func (x dd) mul(y dd) dd {
+	p := twoProd(x.hi, y.hi)
+	return quickTwoSum(p.hi, p.lo+x.hi*y.lo+x.lo*y.hi)
+}
+
+// mulFloat returns x * y for a float64 y.
+func (x dd) mulFloat(y float64) dd {
+	p := twoProd(x.hi, y)
+	return quickTwoSum(p.hi, p.lo+x.lo*y)
+}
+
+func (x dd) div(y dd) dd {
+	// Long division, correcting the quotient of the high parts by those of the remainders.
+	q1 := x.hi / y.hi
+	r := x.sub(y.mulFloat(q1))
+	q2 := r.hi / y.hi
+	r = r.sub(y.mulFloat(q2))
+	return quickTwoSum(q1, q2).add(dd{r.hi / y.hi, 0})
+}
+
+// divFloat returns x / y for a float64 y.
+func (x dd) divFloat(y float64) dd {
+	q := x.hi / y
+	p := twoProd(q, y)
+	return quickTwoSum(q, (x.hi-p.hi-p.lo+x.lo)/y)
+}
+
+func (x dd) sqrt() dd {
+	if x.hi <= 0 {
+		return dd{math.Sqrt(x.hi), 0}
+	}
+	// One Newton step from the float64 square root doubles its precision.
+	s := math.Sqrt(x.hi)
+	r := x.sub(twoProd(s, s))
+	return quickTwoSum(s, r.hi/(2*s))
+}
+
+// ddPiOver2 is π/2 to double-double precision.
+var ddPiOver2 = dd{1.5707963267948966, 6.123233995736766e-17}
+
+// ddSincos returns the sine and cosine of x to double-double precision. The reduction of x modulo π/2
+// loses precision in proportion to the magnitude of x, which is negligible for latitudes and longitudes.
+func ddSincos(x float64) (sin, cos dd) {
+	k := math.Round(x / ddPiOver2.hi)
+	r := dd{x, 0}.sub(ddPiOver2.mulFloat(k))
+
+	// Sum the Taylor series of the sine and cosine of the reduced argument, |r| <= π/4,
+	// until their terms are negligible.
+	r2 := r.mul(r)
+	s, c := r, dd{1, 0}
+	st, ct := r, dd{1, 0}
+	for n := 2.0; math.Abs(ct.hi) > 1e-35; n += 2 {
+		ct = ct.mul(r2).divFloat(-(n - 1) * n)
+		st = st.mul(r2).divFloat(-n * (n + 1))
+		c, s = c.add(ct), s.add(st)
+	}
+
+	switch int(k) & 3 {
+	case 1:
+		return c, dd{-s.hi, -s.lo}
+	case 2:
+		return dd{-s.hi, -s.lo}, dd{-c.hi, -c.lo}
+	case 3:
+		return dd{-c.hi, -c.lo}, s
+	}
+	return s, c
+}
+
+// A ddVector is a vector with double-double components.
+type ddVector struct {
+	X, Y, Z dd
+}
+
+// dot returns the dot product of v with the float64 vector u.
+func (v ddVector) dot(u r3.Vector) dd {
+	return v.X.mulFloat(u.X).add(v.Y.mulFloat(u.Y)).add(v.Z.mulFloat(u.Z))
+}
+
+func (v ddVector) sub(u ddVector) ddVector {
+	return ddVector{v.X.sub(u.X), v.Y.sub(u.Y), v.Z.sub(u.Z)}
+}
+
+// normalize returns the unit vector in the direction of v.
+func (v ddVector) normalize() ddVector {
+	n := v.X.mul(v.X).add(v.Y.mul(v.Y)).add(v.Z.mul(v.Z)).sqrt()
+	return ddVector{v.X.div(n), v.Y.div(n), v.Z.div(n)}
+}
+
+// nearPole reports whether P is within the extended-precision angle of either pole, but not approximately equal to one,
+// so that the projection operations compute its projection with projectExtended.
+func (gm *GeneralizedMercator) nearPole(P r3.Vector) bool {
+	if gm.extended == 0 || gm.near(P, gm.pos) || gm.near(P, gm.neg) {
+		return false
+	}
+	return float64(P.Angle(gm.pos)) < gm.extended || float64(P.Angle(gm.neg)) < gm.extended
+}
+
+// extendedPoint returns the point on the reference sphere corresponding to ll, to double-double precision.
+// It need not be unit length.
+func (gm *GeneralizedMercator) extendedPoint(ll s2.LatLng) ddVector {
+	sinLat, cosLat := ddSincos(float64(ll.Lat))
+	sinLng, cosLng := ddSincos(float64(ll.Lng))
+	if gm.flattening != 0 {
+		// As in pointLat, the direction (cos(φ), (1-f)² sin(φ)) has the geocentric latitude of the point.
+		sinLat = sinLat.mulFloat((1 - gm.flattening) * (1 - gm.flattening))
+	}
+	return ddVector{cosLat.mul(cosLng), cosLat.mul(sinLng), sinLat}
+}
+
+// projectExtended is like projectPoint, but computes the projection of P, which need not be unit length,
+// in double-double arithmetic.
+func (gm *GeneralizedMercator) projectExtended(P ddVector) r2.Point {
+	// Dot products with P itself would place the pole where the rounded basis vectors put it, up to about 1e-16
+	// from the pole itself. Instead, P is measured from the nearer pole, whose coordinates in the basis are
+	// (1/d, 0, ±sqrt(1 - 1/d²)) by construction, by a small displacement whose coordinates are accurate
+	// to the precision of their own magnitude. Poles that are approximately antipodal are taken to be exact antipodes.
+	P = P.normalize()
+	pole, neg := gm.pos, gm.neg
+	if gm.dinv == 0 {
+		neg = gm.pos.Mul(-1)
+	}
+	c0 := dd{1, 0}.sub(twoProd(gm.dinv, gm.dinv)).sqrt()
+	if P.dot(neg).hi > P.dot(pole).hi {
+		pole, c0 = neg, dd{-c0.hi, -c0.lo}
+	}
+	D := P.sub(ddVector{dd{pole.X, 0}, dd{pole.Y, 0}, dd{pole.Z, 0}}.normalize())
+	a, b, c := D.dot(gm.i).add(dd{gm.dinv, 0}), D.dot(gm.j), D.dot(gm.k).add(c0)
+
+	// The coordinates u, b, w of P in the rotated basis follow as in rotated.
+	u, w := a, c
+	if gm.dinv != 0 {
+		cos, sin := dd{1, 0}.sub(a.mulFloat(gm.dinv)), c.mulFloat(gm.dinv)
+		r := cos.mul(cos).add(sin.mul(sin)).sqrt()
+		u, w = a.mul(cos).sub(c.mul(sin)).div(r), c.mul(cos).add(a.mul(sin)).div(r)
+	}
+	// Once computed, u and b are accurate to float64 precision, and so is tan(ψ) == w/hypot(b, u).
+	rho := math.Hypot(b.hi, u.hi)
+	if rho == 0 {
+		return gm.toPlane(0, math.Copysign(math.Pi/2, w.hi))
+	}
+	return gm.toPlaneY(math.Atan2(b.hi, u.hi), math.Asinh(w.hi/rho))
+}
@@ -0,0 +1,155 @@
+package gm
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestDDSincos(t *testing.T) {
+	for _, x := range []float64{0, 1e-10, 0.5, 1, pi / 2, -pi / 2, 2, -3, pi, 6} {
+		sin, cos := ddSincos(x)
+		if got, want := sin.hi, math.Sin(x); math.Abs(got-want) > 2e-16 {
+			t.Errorf("ddSincos(%v): got sin %v, want %v", x, got, want)
+		}
+		if got, want := cos.hi, math.Cos(x); math.Abs(got-want) > 2e-16 {
+			t.Errorf("ddSincos(%v): got cos %v, want %v", x, got, want)
+		}
+		if got := sin.mul(sin).add(cos.mul(cos)).sub(dd{1, 0}); math.Abs(got.hi) > 1e-30 {
+			t.Errorf("ddSincos(%v): sin² + cos² - 1 == %v", x, got.hi)
+		}
+		sin2, _ := ddSincos(2 * x)
+		if got := sin2.sub(sin.mul(cos).mulFloat(2)); math.Abs(got.hi) > 1e-30 {
+			t.Errorf("ddSincos(%v): sin(2x) - 2sin(x)cos(x) == %v", x, got.hi)
+		}
+	}
+	// The cosine of the float64 nearest π/2 and the sine of that nearest π are their differences from π/2 and π,
+	// which math.Cos and math.Sin compute with errors of several ulps.
+	if _, cos := ddSincos(pi / 2); math.Abs(cos.hi-6.123233995736766e-17) > 1e-32 {
+		t.Errorf("ddSincos(π/2): got cos %v", cos.hi)
+	}
+	if sin, _ := ddSincos(pi); math.Abs(sin.hi-1.2246467991473532e-16) > 1e-32 {
+		t.Errorf("ddSincos(π): got sin %v", sin.hi)
+	}
+}
+
+// bigProject returns the projection before scaling of the direction P, computed as by projectExtended
+// in math/big arithmetic with enough precision to be exact to well beyond float64 precision.
+func bigProject(gm *GeneralizedMercator, P [3]*big.Float) r2.Point {
+	const prec = 512
+	f := func(v float64) *big.Float { return new(big.Float).SetPrec(prec).SetFloat64(v) }
+	add := func(a, b *big.Float) *big.Float { return new(big.Float).SetPrec(prec).Add(a, b) }
+	sub := func(a, b *big.Float) *big.Float { return new(big.Float).SetPrec(prec).Sub(a, b) }
+	mul := func(a, b *big.Float) *big.Float { return new(big.Float).SetPrec(prec).Mul(a, b) }
+	quo := func(a, b *big.Float) *big.Float { return new(big.Float).SetPrec(prec).Quo(a, b) }
+	sqrt := func(a *big.Float) *big.Float { return new(big.Float).SetPrec(prec).Sqrt(a) }
+	dot := func(v r3.Vector) *big.Float {
+		return add(add(mul(P[0], f(v.X)), mul(P[1], f(v.Y))), mul(P[2], f(v.Z)))
+	}
+
+	normalize := func(P [3]*big.Float) [3]*big.Float {
+		n := sqrt(add(add(mul(P[0], P[0]), mul(P[1], P[1])), mul(P[2], P[2])))
+		return [3]*big.Float{quo(P[0], n), quo(P[1], n), quo(P[2], n)}
+	}
+	P = normalize(P)
+	// Measure P from the nearer pole, whose coordinates in the basis are (1/d, 0, ±sqrt(1 - 1/d²)).
+	pole, neg := gm.pos, gm.neg
+	if gm.dinv == 0 {
+		neg = gm.pos.Mul(-1)
+	}
+	c0 := sqrt(sub(f(1), mul(f(gm.dinv), f(gm.dinv))))
+	if dot(neg).Cmp(dot(pole)) > 0 {
+		pole, c0 = neg, c0.Neg(c0)
+	}
+	Q := normalize([3]*big.Float{f(pole.X), f(pole.Y), f(pole.Z)})
+	for k := range P {
+		P[k] = sub(P[k], Q[k])
+	}
+	a, b, c := add(dot(gm.i), f(gm.dinv)), dot(gm.j), add(dot(gm.k), c0)
+	u, w := a, c
+	if gm.dinv != 0 {
+		cos, sin := sub(f(1), mul(a, f(gm.dinv))), mul(c, f(gm.dinv))
+		r := sqrt(add(mul(cos, cos), mul(sin, sin)))
+		u, w = quo(sub(mul(a, cos), mul(c, sin)), r), quo(add(mul(c, cos), mul(a, sin)), r)
+	}
+	uf, _ := u.Float64()
+	bf, _ := b.Float64()
+	tan, _ := quo(w, sqrt(add(mul(u, u), mul(b, b)))).Float64()
+	return r2.Point{X: math.Atan2(bf, uf), Y: math.Asinh(tan)}
+}
+
+func TestProjectExtended(t *testing.T) {
+	for _, test := range []struct {
+		pos, neg s2.LatLng
+		opts     []Option
+	}{
+		{s2.LatLngFromDegrees(37.7, -122.4), s2.LatLngFromDegrees(-37.7, 57.6), nil},
+		{s2.LatLngFromDegrees(37.7, -122.4), s2.LatLngFromDegrees(-10, 60), nil},
+		{s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2), []Option{WithGeodeticLatitude(WGS84Flattening)}},
+	} {
+		gm := New(test.pos, test.neg, append(test.opts, WithExtendedPrecision(1e-3))...)
+		plain := New(test.pos, test.neg, test.opts...)
+		var maxPlain float64
+		for _, pole := range []r3.Vector{gm.pos, gm.neg} {
+			o1 := pole.Ortho()
+			o2 := pole.Cross(o1)
+			for _, delta := range []float64{1e-4, 1e-8, 1e-12} {
+				for n := 0; n < 8; n++ {
+					sin, cos := math.Sincos(float64(n) * pi / 4)
+					P := s2.Point{pole.Mul(math.Cos(delta)).Add(o1.Mul(cos * math.Sin(delta))).Add(o2.Mul(sin * math.Sin(delta))).Normalize()}
+					want := bigProject(gm, [3]*big.Float{big.NewFloat(P.X), big.NewFloat(P.Y), big.NewFloat(P.Z)})
+					got := gm.ProjectPoint(P)
+					if math.Abs(got.X-want.X) > 1e-15 || math.Abs(got.Y-want.Y) > 4e-15 {
+						t.Errorf("ProjectPoint(%v, %v): got %v, want %v", gm, P, got, want)
+					}
+					maxPlain = math.Max(maxPlain, plain.ProjectPoint(P).Sub(want).Norm())
+
+					ll := gm.latLngFromPoint(P)
+					e := gm.extendedPoint(ll)
+					want = bigProject(gm, [3]*big.Float{
+						new(big.Float).SetPrec(106).Add(big.NewFloat(e.X.hi), big.NewFloat(e.X.lo)),
+						new(big.Float).SetPrec(106).Add(big.NewFloat(e.Y.hi), big.NewFloat(e.Y.lo)),
+						new(big.Float).SetPrec(106).Add(big.NewFloat(e.Z.hi), big.NewFloat(e.Z.lo)),
+					})
+					if got := gm.Project(ll); math.Abs(got.X-want.X) > 1e-15 || math.Abs(got.Y-want.Y) > 4e-15 {
+						t.Errorf("Project(%v, %v): got %v, want %v", gm, ll, got, want)
+					}
+				}
+			}
+		}
+		// Without extended precision, the error near the poles is many orders of magnitude greater.
+		if maxPlain < 1e-8 {
+			t.Errorf("%v: greatest error without extended precision %v", gm, maxPlain)
+		}
+
+		// Far from the poles, and at the poles, extended precision has no effect.
+		for _, ll := range []s2.LatLng{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(-20, 100), test.pos, test.neg} {
+			if got, want := gm.Project(ll), plain.Project(ll); got != want {
+				t.Errorf("Project(%v, %v): got %v, want %v", gm, ll, got, want)
+			}
+		}
+	}
+}
+
+func TestWithExtendedPrecision(t *testing.T) {
+	for _, near := range []float64{-1, 4, math.NaN()} {
+		if _, err := TryNew(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithExtendedPrecision(s1.Angle(near))); err == nil {
+			t.Errorf("WithExtendedPrecision(%v): got nil error", near)
+		}
+	}
+}
+
+func BenchmarkProjectExtended(b *testing.B) {
+	gm := New(s2.LatLngFromDegrees(37.7, -122.4), s2.LatLngFromDegrees(-10, 60), WithExtendedPrecision(1e-3))
+	pos, _ := gm.Poles()
+	ll := s2.LatLng{Lat: pos.Lat + 1e-9, Lng: pos.Lng}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		gm.Project(ll)
+	}
+}
@@ -0,0 +1,303 @@
+/*
+Package fit chooses generalized Mercator projections suited to a set of locations.
+
+Fit searches for the pair of poles whose projection minimizes the scale distortion at a set of sample locations,
+such as the vertices of a shipping lane or a country outline. Because a uniform change of scale does not affect
+the shape of a map, distortion is measured by the variation of the logarithm of the principal scale factors
+across the samples rather than by their deviation from 1.
+*/
+package fit
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/dkmccandless/gm"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// An Objective is a measure of the scale distortion of a projection over a set of samples.
+type Objective int
+
+const (
+	// MaxDistortion is half the difference between the logarithms of the greatest and least principal scale factors
+	// among all samples: the maximum deviation of the log scale from its optimal uniform value.
+	MaxDistortion Objective = iota
+
+	// RMSDistortion is the root mean square deviation of the logarithms of the principal scale factors
+	// at all samples from their mean.
+	RMSDistortion
+)
+
+// Distortion returns the scale distortion of g over samples according to obj.
+// It returns +Inf if any sample is at a pole of g, and 0 if there are no samples.
+func Distortion(g *gm.GeneralizedMercator, samples []s2.LatLng, obj Objective) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var (
+		lo, hi     = math.Inf(1), math.Inf(-1)
+		sum, sumSq float64
+	)
+	for _, ll := range samples {
+		max, min := g.ScaleFactors(ll)
+		if math.IsInf(max, 1) {
+			return math.Inf(1)
+		}
+		for _, s := range []float64{math.Log(max), math.Log(min)} {
+			lo, hi = math.Min(lo, s), math.Max(hi, s)
+			sum += s
+			sumSq += s * s
+		}
+	}
+	switch obj {
+	case MaxDistortion:
+		return (hi - lo) / 2
+	case RMSDistortion:
+		n := float64(2 * len(samples))
+		mean := sum / n
+		return math.Sqrt(math.Max(0, sumSq/n-mean*mean))
+	default:
+		panic("unknown objective")
+	}
+}
+
+// maxEvals is the maximum number of objective evaluations performed by Fit.
+const maxEvals = 4000
+
+// Fit returns a projection, configured by opts, whose poles minimize the distortion of samples according to obj.
+// The search begins with the antipodal poles whose projective equator best fits the samples in the least-squares sense
+// and refines both poles independently, so the result need not have antipodal poles.
+// Fit returns an error if there are fewer than two distinct samples, or if the projection cannot be constructed
+// with opts, as when an option is invalid.
+func Fit(samples []s2.LatLng, obj Objective, opts ...gm.Option) (*gm.GeneralizedMercator, error) {
+	if len(samples) < 2 {
+		return nil, errors.New("fit: too few samples")
+	}
+	axis, ok := bestAxis(samples, nil)
+	if !ok {
+		return nil, errors.New("fit: indistinct samples")
+	}
+
+	pos := s2.LatLngFromPoint(s2.Point{Vector: axis})
+	neg := s2.LatLngFromPoint(s2.Point{Vector: axis.Mul(-1)})
+	cost := func(v [4]float64) float64 {
+		p := s2.LatLng{Lat: s1.Angle(v[0]), Lng: s1.Angle(v[1])}.Normalized()
+		n := s2.LatLng{Lat: s1.Angle(v[2]), Lng: s1.Angle(v[3])}.Normalized()
+		if s2.PointFromLatLng(p).Angle(s2.PointFromLatLng(n).Vector) < 1e-6 {
+			return math.Inf(1)
+		}
+		g, err := gm.TryNew(p, n, opts...)
+		if err != nil {
+			return math.Inf(1)
+		}
+		return Distortion(g, samples, obj)
+	}
+	v := minimize(cost, [4]float64{float64(pos.Lat), float64(pos.Lng), float64(neg.Lat), float64(neg.Lng)}, 0.1)
+	pos = s2.LatLng{Lat: s1.Angle(v[0]), Lng: s1.Angle(v[1])}.Normalized()
+	neg = s2.LatLng{Lat: s1.Angle(v[2]), Lng: s1.Angle(v[3])}.Normalized()
+	return gm.TryNew(pos, neg, opts...)
+}
+
+// ForRegion returns a conformal projection, configured by opts, suited to mapping the region r:
+// its poles are antipodes placed such that the projective equator is the great circle that best fits r,
+// oriented so that the positive pole is in the northern hemisphere where possible,
+// and the center of r's bounding cap projects onto the central line x = 0.
+// If r is too small to determine a great circle, the projective equator runs east-west through its center.
+// ForRegion returns an error if r is empty.
+func ForRegion(r s2.Region, opts ...gm.Option) (*gm.GeneralizedMercator, error) {
+	bound := r.CapBound()
+	if bound.IsEmpty() {
+		return nil, errors.New("fit: empty region")
+	}
+	center := s2.LatLngFromPoint(bound.Center())
+
+	// Sample r on a grid over its bounding rectangle, weighted by area, or failing that, at the vertices of a covering.
+	var (
+		samples []s2.LatLng
+		weights []float64
+		rect    = r.RectBound()
+	)
+	const n = 32
+	for i := 0; i <= n; i++ {
+		for j := 0; j <= n; j++ {
+			ll := s2.LatLng{
+				Lat: s1.Angle(rect.Lat.Lo + float64(i)/n*rect.Lat.Length()),
+				Lng: s1.Angle(rect.Lng.Lo + float64(j)/n*rect.Lng.Length()),
+			}
+			if r.ContainsPoint(s2.PointFromLatLng(ll)) {
+				samples = append(samples, ll)
+				weights = append(weights, math.Cos(float64(ll.Lat)))
+			}
+		}
+	}
+	if len(samples) < 3 {
+		samples, weights = nil, nil
+		coverer := &s2.RegionCoverer{MaxLevel: 30, MaxCells: 64}
+		for _, id := range coverer.Covering(r) {
+			cell := s2.CellFromCellID(id)
+			samples = append(samples, s2.LatLngFromPoint(cell.Center()))
+			for k := 0; k < 4; k++ {
+				samples = append(samples, s2.LatLngFromPoint(cell.Vertex(k)))
+			}
+		}
+	}
+	axis, ok := bestAxis(samples, weights)
+	if !ok || bound.Radius() < 1e-9 {
+		return gm.NewCentered(center, 90*s1.Degree, opts...), nil
+	}
+	if axis.Z < 0 || axis.Z == 0 && (axis.Y < 0 || axis.Y == 0 && axis.X < 0) {
+		axis = axis.Mul(-1)
+	}
+	opts = append([]gm.Option{gm.WithCentralPoint(center)}, opts...)
+	return gm.NewFromPoints(s2.Point{Vector: axis}, s2.Point{Vector: axis.Mul(-1)}, opts...), nil
+}
+
+// bestAxis returns the unit vector k minimizing the weighted sum of squares of (P·k) over the samples P:
+// the pole of the great circle that best fits them. If weights is nil, each sample has weight 1.
+// bestAxis reports false if the samples do not determine a great circle.
+func bestAxis(samples []s2.LatLng, weights []float64) (r3.Vector, bool) {
+	var m [3][3]float64
+	for n, ll := range samples {
+		w := 1.0
+		if weights != nil {
+			w = weights[n]
+		}
+		p := s2.PointFromLatLng(ll)
+		v := [3]float64{p.X, p.Y, p.Z}
+		for i := range v {
+			for j := range v {
+				m[i][j] += w * v[i] * v[j]
+			}
+		}
+	}
+	vals, vecs := eigen(m)
+	// If the two smallest eigenvalues are equal, the samples are concentrated at a single point or its antipode.
+	if vals[1]-vals[0] < 1e-12*vals[2] {
+		return r3.Vector{}, false
+	}
+	return r3.Vector{X: vecs[0][0], Y: vecs[0][1], Z: vecs[0][2]}, true
+}
+
+// eigen returns the eigenvalues of the symmetric matrix m in increasing order and the corresponding unit eigenvectors,
+// computed by the cyclic Jacobi method.
+func eigen(m [3][3]float64) (vals [3]float64, vecs [3][3]float64) {
+	v := [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	for sweep := 0; sweep < 50; sweep++ {
+		off := m[0][1]*m[0][1] + m[0][2]*m[0][2] + m[1][2]*m[1][2]
+		if off < 1e-30 {
+			break
+		}
+		for p := 0; p < 2; p++ {
+			for q := p + 1; q < 3; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < 3; k++ {
+					mkp, mkq := m[k][p], m[k][q]
+					m[k][p], m[k][q] = c*mkp-s*mkq, s*mkp+c*mkq
+				}
+				for k := 0; k < 3; k++ {
+					mpk, mqk := m[p][k], m[q][k]
+					m[p][k], m[q][k] = c*mpk-s*mqk, s*mpk+c*mqk
+				}
+				for k := 0; k < 3; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p], v[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+	idx := []int{0, 1, 2}
+	sort.Slice(idx, func(a, b int) bool { return m[idx[a]][idx[a]] < m[idx[b]][idx[b]] })
+	for n, i := range idx {
+		vals[n] = m[i][i]
+		vecs[n] = [3]float64{v[0][i], v[1][i], v[2][i]}
+	}
+	return vals, vecs
+}
+
+// minimize returns an approximate local minimum of f near x0, found by the Nelder-Mead simplex method
+// with an initial simplex of the given size.
+func minimize(f func([4]float64) float64, x0 [4]float64, size float64) [4]float64 {
+	const n = len(x0)
+	var (
+		pts  [n + 1][4]float64
+		vals [n + 1]float64
+	)
+	for i := range pts {
+		pts[i] = x0
+		if i > 0 {
+			pts[i][i-1] += size
+		}
+		vals[i] = f(pts[i])
+	}
+	// along returns the point c + t*(p - c).
+	along := func(c, p [4]float64, t float64) (q [4]float64) {
+		for i := range q {
+			q[i] = c[i] + t*(p[i]-c[i])
+		}
+		return q
+	}
+	for evals := n + 1; evals < maxEvals; {
+		// Order the vertices from best to worst.
+		for i := 1; i <= n; i++ {
+			for j := i; j > 0 && vals[j] < vals[j-1]; j-- {
+				pts[j], pts[j-1] = pts[j-1], pts[j]
+				vals[j], vals[j-1] = vals[j-1], vals[j]
+			}
+		}
+		if vals[n]-vals[0] <= 1e-12*(1+math.Abs(vals[0])) && !math.IsInf(vals[n], 1) {
+			break
+		}
+		var c [4]float64
+		for _, p := range pts[:n] {
+			for i := range c {
+				c[i] += p[i] / float64(n)
+			}
+		}
+		r := along(c, pts[n], -1)
+		fr := f(r)
+		evals++
+		switch {
+		case fr < vals[0]:
+			e := along(c, pts[n], -2)
+			if fe := f(e); fe < fr {
+				pts[n], vals[n] = e, fe
+			} else {
+				pts[n], vals[n] = r, fr
+			}
+			evals++
+		case fr < vals[n-1]:
+			pts[n], vals[n] = r, fr
+		default:
+			k := along(c, pts[n], 0.5)
+			if fk := f(k); fk < vals[n] {
+				pts[n], vals[n] = k, fk
+				evals++
+				break
+			}
+			evals++
+			// Shrink the simplex toward the best vertex.
+			for i := 1; i <= n; i++ {
+				pts[i] = along(pts[0], pts[i], 0.5)
+				vals[i] = f(pts[i])
+			}
+			evals += n
+		}
+	}
+	best := 0
+	for i := range vals {
+		if vals[i] < vals[best] {
+			best = i
+		}
+	}
+	return pts[best]
+}
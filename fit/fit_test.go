@@ -0,0 +1,134 @@
+package fit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dkmccandless/gm"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestDistortion(t *testing.T) {
+	mercator := gm.New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	samples := []s2.LatLng{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(60, 0)}
+	// The scale factors are 1 on the Equator and 2 at 60°.
+	if got, want := Distortion(mercator, samples, MaxDistortion), math.Log(2)/2; math.Abs(got-want) > 1e-12 {
+		t.Errorf("Distortion(MaxDistortion): got %v, want %v", got, want)
+	}
+	if got, want := Distortion(mercator, samples, RMSDistortion), math.Log(2)/2; math.Abs(got-want) > 1e-12 {
+		t.Errorf("Distortion(RMSDistortion): got %v, want %v", got, want)
+	}
+	if got := Distortion(mercator, append(samples, s2.LatLngFromDegrees(90, 0)), MaxDistortion); !math.IsInf(got, 1) {
+		t.Errorf("Distortion with a sample at a pole: got %v, want +Inf", got)
+	}
+}
+
+func TestFit(t *testing.T) {
+	// Samples along a great circle can be mapped without distortion.
+	a, b := s2.PointFromLatLng(s2.LatLngFromDegrees(40.6, -73.8)), s2.PointFromLatLng(s2.LatLngFromDegrees(51.5, -0.5))
+	var route []s2.LatLng
+	for f := 0.0; f <= 1; f += 0.1 {
+		route = append(route, s2.LatLngFromPoint(s2.Interpolate(f, a, b)))
+	}
+	for _, obj := range []Objective{MaxDistortion, RMSDistortion} {
+		g, err := Fit(route, obj)
+		if err != nil {
+			t.Fatalf("Fit(route, %v): %v", obj, err)
+		}
+		if d := Distortion(g, route, obj); d > 1e-6 {
+			t.Errorf("Fit(route, %v): got %v with distortion %v, want 0", obj, g, d)
+		}
+	}
+
+	// Fitting never does worse than the best-fitting antipodal poles from which the search begins.
+	var region []s2.LatLng
+	for lat := 30.0; lat <= 50; lat += 5 {
+		for lng := -10.0; lng <= 30; lng += 5 {
+			region = append(region, s2.LatLngFromDegrees(lat, lng))
+		}
+	}
+	axis, _ := bestAxis(region, nil)
+	start := gm.NewFromPoints(s2.Point{Vector: axis}, s2.Point{Vector: axis.Mul(-1)})
+	for _, obj := range []Objective{MaxDistortion, RMSDistortion} {
+		g, err := Fit(region, obj)
+		if err != nil {
+			t.Fatalf("Fit(region, %v): %v", obj, err)
+		}
+		if got, init := Distortion(g, region, obj), Distortion(start, region, obj); got > init {
+			t.Errorf("Fit(region, %v): got distortion %v, want at most %v", obj, got, init)
+		}
+	}
+
+	for _, samples := range [][]s2.LatLng{
+		nil,
+		{s2.LatLngFromDegrees(10, 10)},
+		{s2.LatLngFromDegrees(10, 10), s2.LatLngFromDegrees(10, 10)},
+	} {
+		if _, err := Fit(samples, MaxDistortion); err == nil {
+			t.Errorf("Fit(%v): got nil error", samples)
+		}
+	}
+
+	// An invalid option is reported as an error rather than a panic.
+	if g, err := Fit(route, MaxDistortion, gm.WithRadius(-1)); err == nil {
+		t.Errorf("Fit(route, WithRadius(-1)): got %v, nil error", g)
+	}
+}
+
+func TestEigen(t *testing.T) {
+	m := [3][3]float64{{4, 1, 0}, {1, 3, 1}, {0, 1, 2}}
+	vals, vecs := eigen(m)
+	for n := range vals {
+		for i := 0; i < 3; i++ {
+			var mv float64
+			for j := 0; j < 3; j++ {
+				mv += m[i][j] * vecs[n][j]
+			}
+			if math.Abs(mv-vals[n]*vecs[n][i]) > 1e-12 {
+				t.Errorf("eigen: eigenpair %d: (Mv)[%d] = %v, want %v", n, i, mv, vals[n]*vecs[n][i])
+			}
+		}
+	}
+	if !(vals[0] <= vals[1] && vals[1] <= vals[2]) {
+		t.Errorf("eigen: got eigenvalues %v, want increasing order", vals)
+	}
+}
+
+func TestForRegion(t *testing.T) {
+	for _, test := range []struct {
+		r       s2.Region
+		maxAbsY float64
+	}{
+		// A band along the Equator yields the Mercator projection.
+		{s2.RectFromLatLng(s2.LatLngFromDegrees(-5, -60)).AddPoint(s2.LatLngFromDegrees(5, 60)), 0.1},
+		// A band along a meridian yields a transverse projection.
+		{s2.RectFromLatLng(s2.LatLngFromDegrees(-60, 18)).AddPoint(s2.LatLngFromDegrees(60, 22)), 0.1},
+		{s2.CapFromCenterAngle(s2.PointFromLatLng(s2.LatLngFromDegrees(48.2, 16.4)), 10*s1.Degree), 0.2},
+		{s2.PointFromLatLng(s2.LatLngFromDegrees(-33.9, 151.2)), 1e-9},
+	} {
+		g, err := ForRegion(test.r)
+		if err != nil {
+			t.Fatalf("ForRegion(%v): %v", test.r, err)
+		}
+		bound := test.r.CapBound()
+		if p := g.ProjectPoint(bound.Center()); math.Abs(p.X) > 1e-9 || math.Abs(p.Y) > test.maxAbsY {
+			t.Errorf("ForRegion(%v): center projects to %v, want near the origin", test.r, p)
+		}
+		if _, ok := test.r.(s2.Point); ok {
+			continue
+		}
+		for _, ll := range []s2.LatLng{s2.LatLngFromPoint(bound.Center())} {
+			if max, min := g.ScaleFactors(ll); math.Abs(max-min) > 1e-9 {
+				t.Errorf("ForRegion(%v): got scale factors %v, %v at %v, want a conformal projection", test.r, max, min, ll)
+			}
+		}
+	}
+	band := s2.RectFromLatLng(s2.LatLngFromDegrees(-5, -60)).AddPoint(s2.LatLngFromDegrees(5, 60))
+	if g, _ := ForRegion(band); !g.ApproxEqual(gm.New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), 1e-6) {
+		t.Errorf("ForRegion(%v): got %v, want the Mercator projection", band, g)
+	}
+	if _, err := ForRegion(s2.EmptyCap()); err == nil {
+		t.Errorf("ForRegion(EmptyCap): got nil error")
+	}
+}
@@ -0,0 +1,61 @@
+package gm
+
+import (
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// The float32 forms of the projection operations are for graphics pipelines, such as vertex buffers,
+// that store coordinates in single precision. They compute in double precision and round only their results.
+
+// Project32 converts the location at latitude lat and longitude lng, in radians, to a projected 2D point.
+func (gm *GeneralizedMercator) Project32(lat, lng float32) (x, y float32) {
+	p := gm.Project(s2.LatLng{Lat: s1.Angle(lat), Lng: s1.Angle(lng)})
+	return float32(p.X), float32(p.Y)
+}
+
+// Unproject32 converts the projected point (x, y) to the latitude and longitude, in radians,
+// of a location on the reference sphere.
+func (gm *GeneralizedMercator) Unproject32(x, y float32) (lat, lng float32) {
+	ll := gm.Unproject(r2.Point{X: float64(x), Y: float64(y)})
+	return float32(ll.Lat), float32(ll.Lng)
+}
+
+// ProjectVec32 is like ProjectVec, but for float32 coordinates.
+// It panics if the slices do not all have the same length.
+func (gm *GeneralizedMercator) ProjectVec32(lat, lng, outX, outY []float32) {
+	if len(lng) != len(lat) || len(outX) != len(lat) || len(outY) != len(lat) {
+		panic("mismatched slice lengths")
+	}
+	for n := range lat {
+		outX[n], outY[n] = gm.Project32(lat[n], lng[n])
+	}
+}
+
+// UnprojectVec32 is like UnprojectVec, but for float32 coordinates, and shares per-row work in the same way.
+// It panics if the slices do not all have the same length.
+func (gm *GeneralizedMercator) UnprojectVec32(x, y, outLat, outLng []float32) {
+	if len(y) != len(x) || len(outLat) != len(x) || len(outLng) != len(x) {
+		panic("mismatched slice lengths")
+	}
+	var r row
+	for n := range x {
+		if n == 0 || float64(y[n]) != r.y {
+			r = gm.row(float64(y[n]))
+		}
+		ll := r.unproject(float64(x[n]))
+		outLat[n], outLng[n] = float32(ll.Lat), float32(ll.Lng)
+	}
+}
+
+// ProjectAppend32 appends the projections of the locations in src to dst as interleaved x and y coordinates,
+// the layout of a vertex buffer of 2D positions, and returns the extended slice.
+// If dst has sufficient capacity, ProjectAppend32 does not allocate.
+func (gm *GeneralizedMercator) ProjectAppend32(dst []float32, src []s2.LatLng) []float32 {
+	for _, ll := range src {
+		p := gm.Project(ll)
+		dst = append(dst, float32(p.X), float32(p.Y))
+	}
+	return dst
+}
@@ -0,0 +1,67 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestFloat32(t *testing.T) {
+	for _, test := range projTests {
+		n := len(test.ps)
+		lat, lng, x, y := make([]float32, n), make([]float32, n), make([]float32, n), make([]float32, n)
+		src := make([]s2.LatLng, n)
+		for m, p := range test.ps {
+			lat[m], lng[m] = float32(p.s.Lat), float32(p.s.Lng)
+			src[m] = s2.LatLng{Lat: s1.Angle(lat[m]), Lng: s1.Angle(lng[m])}
+		}
+		test.gm.ProjectVec32(lat, lng, x, y)
+		buf := test.gm.ProjectAppend32(nil, src)
+		if len(buf) != 2*n {
+			t.Fatalf("ProjectAppend32(%+v): got %v", test.gm, buf)
+		}
+		for m, ll := range src {
+			want := test.gm.Project(ll)
+			wx, wy := float32(want.X), float32(want.Y)
+			if gx, gy := test.gm.Project32(lat[m], lng[m]); gx != wx || gy != wy {
+				t.Errorf("Project32(%+v, %v, %v): got %v, %v, want %v, %v", test.gm, lat[m], lng[m], gx, gy, wx, wy)
+			}
+			if x[m] != wx || y[m] != wy {
+				t.Errorf("ProjectVec32(%+v)[%d]: got %v, %v, want %v, %v", test.gm, m, x[m], y[m], wx, wy)
+			}
+			if buf[2*m] != wx || buf[2*m+1] != wy {
+				t.Errorf("ProjectAppend32(%+v)[%d]: got %v, %v, want %v, %v", test.gm, m, buf[2*m], buf[2*m+1], wx, wy)
+			}
+		}
+
+		outLat, outLng := make([]float32, n), make([]float32, n)
+		test.gm.UnprojectVec32(x, y, outLat, outLng)
+		for m := range x {
+			want := test.gm.Unproject(r2.Point{X: float64(x[m]), Y: float64(y[m])})
+			if gLat, gLng := test.gm.Unproject32(x[m], y[m]); gLat != float32(want.Lat) || gLng != float32(want.Lng) {
+				t.Errorf("Unproject32(%+v, %v, %v): got %v, %v, want %v", test.gm, x[m], y[m], gLat, gLng, want)
+			}
+			// The vector form may differ from Unproject by the rounding of its per-row computations.
+			got := s2.LatLng{Lat: s1.Angle(outLat[m]), Lng: s1.Angle(outLng[m])}
+			if s2.PointFromLatLng(got).Distance(s2.PointFromLatLng(want)) > 1e-6 {
+				t.Errorf("UnprojectVec32(%+v)[%d]: got %v, want %v", test.gm, m, got, want)
+			}
+		}
+	}
+}
+
+func TestFloat32RoundTrip(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0))
+	for lat := float32(-1.5); lat <= 1.5; lat += 0.25 {
+		for lng := float32(-3); lng <= 3; lng += 0.5 {
+			x, y := gm.Project32(lat, lng)
+			gotLat, gotLng := gm.Unproject32(x, y)
+			if d := s2.PointFromLatLng(s2.LatLng{Lat: s1.Angle(gotLat), Lng: s1.Angle(gotLng)}).Distance(s2.PointFromLatLng(s2.LatLng{Lat: s1.Angle(lat), Lng: s1.Angle(lng)})); d > 1e-5 || math.IsNaN(float64(d)) {
+				t.Errorf("Unproject32(Project32(%v, %v)): got %v, %v, %v away", lat, lng, gotLat, gotLng, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r1"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A GeneralizedRect is a rectangle in generalized coordinates: the set of locations whose projective longitudes,
+// measured from the central line as by ToGeneralized, are in X and whose generalized latitudes are in Psi.
+// It is the analogue of s2.Rect for a GeneralizedMercator projection, whose projection is a rectangle in the plane
+// before any output transformation. Like the longitude interval of an s2.Rect, X may wrap around from π to -π.
+type GeneralizedRect struct {
+	X   s1.Interval
+	Psi r1.Interval
+}
+
+// EmptyGeneralizedRect returns the empty rectangle.
+func EmptyGeneralizedRect() GeneralizedRect {
+	return GeneralizedRect{X: s1.EmptyInterval(), Psi: r1.EmptyInterval()}
+}
+
+// FullGeneralizedRect returns the rectangle that contains every location.
+func FullGeneralizedRect() GeneralizedRect {
+	return GeneralizedRect{X: s1.FullInterval(), Psi: r1.Interval{Lo: -math.Pi / 2, Hi: math.Pi / 2}}
+}
+
+// GeneralizedRectFromCoords returns the smallest rectangle that contains the location with projective longitude x
+// and generalized latitude psi. Like the methods of GeneralizedRect that accept coordinates, it reduces x modulo 2π,
+// so that it accepts the projective longitudes returned by ToGeneralized for projections with a seam.
+func GeneralizedRectFromCoords(x, psi s1.Angle) GeneralizedRect {
+	x = s1.Angle(math.Remainder(float64(x), 2*math.Pi))
+	return GeneralizedRect{X: s1.IntervalFromEndpoints(float64(x), float64(x)), Psi: r1.Interval{Lo: float64(psi), Hi: float64(psi)}}
+}
+
+// IsValid reports whether X is contained in [-π, π], Psi is contained in [-π/2, π/2], and X and Psi are either both empty
+// or both nonempty.
+func (r GeneralizedRect) IsValid() bool {
+	return r.X.IsValid() && math.Abs(r.Psi.Lo) <= math.Pi/2 && math.Abs(r.Psi.Hi) <= math.Pi/2 && r.X.IsEmpty() == r.Psi.IsEmpty()
+}
+
+// IsEmpty reports whether the rectangle is empty.
+func (r GeneralizedRect) IsEmpty() bool { return r.Psi.IsEmpty() }
+
+// IsFull reports whether the rectangle contains every location.
+func (r GeneralizedRect) IsFull() bool { return r.X.IsFull() && r.Psi == FullGeneralizedRect().Psi }
+
+// ContainsCoords reports whether the rectangle contains the location with projective longitude x and generalized latitude psi.
+func (r GeneralizedRect) ContainsCoords(x, psi s1.Angle) bool {
+	return r.X.Contains(math.Remainder(float64(x), 2*math.Pi)) && r.Psi.Contains(float64(psi))
+}
+
+// Contains reports whether r contains other.
+func (r GeneralizedRect) Contains(other GeneralizedRect) bool {
+	return r.X.ContainsInterval(other.X) && r.Psi.ContainsInterval(other.Psi)
+}
+
+// Intersects reports whether r and other have any locations in common.
+func (r GeneralizedRect) Intersects(other GeneralizedRect) bool {
+	return r.X.Intersects(other.X) && r.Psi.Intersects(other.Psi)
+}
+
+// Union returns the smallest rectangle that contains both r and other.
+func (r GeneralizedRect) Union(other GeneralizedRect) GeneralizedRect {
+	return GeneralizedRect{X: r.X.Union(other.X), Psi: r.Psi.Union(other.Psi)}
+}
+
+// Intersection returns the smallest rectangle that contains the intersection of r and other.
+// As with s2.Rect, the intersection may consist of two disjoint rectangles, in which case a rectangle spanning both is returned.
+func (r GeneralizedRect) Intersection(other GeneralizedRect) GeneralizedRect {
+	x, psi := r.X.Intersection(other.X), r.Psi.Intersection(other.Psi)
+	if x.IsEmpty() || psi.IsEmpty() {
+		return EmptyGeneralizedRect()
+	}
+	return GeneralizedRect{X: x, Psi: psi}
+}
+
+// AddCoords returns the smallest rectangle that contains r and the location with projective longitude x
+// and generalized latitude psi.
+func (r GeneralizedRect) AddCoords(x, psi s1.Angle) GeneralizedRect {
+	return r.Union(GeneralizedRectFromCoords(x, psi))
+}
+
+// GeneralizedRectBound returns a rectangle in generalized coordinates that contains the region r.
+// It is computed in the same way as RegionBounds, and is not tight.
+func (gm *GeneralizedMercator) GeneralizedRectBound(r s2.Region) GeneralizedRect {
+	xs, psis := gm.regionGeneralizedBound(r)
+	switch {
+	case xs.IsEmpty() || psis.IsEmpty():
+		return EmptyGeneralizedRect()
+	case xs.IsFull():
+		return GeneralizedRect{X: xs, Psi: psis}
+	}
+	return GeneralizedRect{
+		X:   s1.IntervalFromEndpoints(math.Remainder(xs.Lo+gm.cut, 2*math.Pi), math.Remainder(xs.Hi+gm.cut, 2*math.Pi)),
+		Psi: psis,
+	}
+}
+
+// GeneralizedRectContainsPoint reports whether r, interpreted in the coordinates of the projection, contains p.
+func (gm *GeneralizedMercator) GeneralizedRectContainsPoint(r GeneralizedRect, p s2.Point) bool {
+	x, psi := gm.ToGeneralized(gm.latLngFromPoint(p))
+	if math.Abs(float64(psi)) == math.Pi/2 {
+		// A pole belongs to every rectangle that reaches it.
+		return !r.X.IsEmpty() && r.Psi.Contains(float64(psi))
+	}
+	return r.ContainsCoords(x, psi)
+}
+
+// GeneralizedRectPolygon returns a polygon that approximates the region of the reference sphere whose generalized
+// coordinates lie in r to within tolerance, which must be positive. Its boundary follows curves of constant
+// projective longitude and generalized latitude, which are not geodesics in general. A rectangle that spans
+// every projective longitude is a band between two curves of constant generalized latitude, or a cap if it reaches
+// a pole. GeneralizedRectPolygon returns an empty polygon if r has no interior.
+func (gm *GeneralizedMercator) GeneralizedRectPolygon(r GeneralizedRect, tolerance s1.Angle) *s2.Polygon {
+	switch {
+	case r.IsFull():
+		return s2.FullPolygon()
+	case r.IsEmpty() || r.X.Length() == 0 || r.Psi.Length() == 0:
+		return &s2.Polygon{}
+	}
+	f := func(x, psi float64) s2.Point { return s2.Point{gm.fromGeneralized(x+gm.x0, psi)} }
+	parallel := func(psi, x0, x1 float64) s2.Polyline {
+		return tessellate(func(x float64) s2.Point { return f(x, psi) }, x0, x1, tolerance)
+	}
+	meridian := func(x, psi0, psi1 float64) s2.Polyline {
+		return tessellate(func(psi float64) s2.Point { return f(x, psi) }, psi0, psi1, tolerance)
+	}
+	lo, hi := r.Psi.Lo, r.Psi.Hi
+	atPos, atNeg := hi == math.Pi/2, lo == -math.Pi/2
+
+	if r.X.IsFull() {
+		// The region is bounded by whole curves of constant generalized latitude, traversed with the region on the left.
+		var loops []*s2.Loop
+		if !atNeg {
+			line := parallel(lo, -math.Pi, math.Pi)
+			loops = append(loops, s2.LoopFromPoints(line[:len(line)-1]))
+		}
+		if !atPos {
+			line := parallel(hi, math.Pi, -math.Pi)
+			loops = append(loops, s2.LoopFromPoints(line[:len(line)-1]))
+		}
+		return s2.PolygonFromOrientedLoops(loops)
+	}
+
+	// Traverse the boundary counterclockwise in the plane, which keeps the region on the left,
+	// omitting the last vertex of each side, which is the first of the next. A side along a pole is a single vertex.
+	x0 := r.X.Lo
+	x1 := x0 + r.X.Length()
+	var pts []s2.Point
+	side := func(line s2.Polyline) { pts = append(pts, line[:len(line)-1]...) }
+	if !atNeg {
+		side(parallel(lo, x0, x1))
+	}
+	side(meridian(x1, lo, hi))
+	if !atPos {
+		side(parallel(hi, x1, x0))
+	}
+	side(meridian(x0, hi, lo))
+	return s2.PolygonFromOrientedLoops([]*s2.Loop{s2.LoopFromPoints(pts)})
+}
@@ -0,0 +1,107 @@
+package gm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r1"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// grect returns the GeneralizedRect with the given endpoints.
+func grect(x0, psi0, x1, psi1 float64) GeneralizedRect {
+	return GeneralizedRect{X: s1.IntervalFromEndpoints(x0, x1), Psi: r1.Interval{Lo: psi0, Hi: psi1}}
+}
+
+func TestGeneralizedRect(t *testing.T) {
+	a := grect(-1, -0.5, 1, 0.5)
+	wrapped := grect(3, 0, -3, 1)
+	for _, test := range []struct {
+		r, other             GeneralizedRect
+		contains, intersects bool
+		union                GeneralizedRect
+	}{
+		{a, grect(-0.5, 0, 0.5, 0.2), true, true, a},
+		{a, grect(0.5, 0, 2, 1), false, true, grect(-1, -0.5, 2, 1)},
+		{a, grect(2, 0, 2.5, 0.2), false, false, grect(-1, -0.5, 2.5, 0.5)},
+		{a, EmptyGeneralizedRect(), true, false, a},
+		{wrapped, grect(3.1, 0.5, -3.1, 0.6), true, true, wrapped},
+		{wrapped, grect(2, 0, 3.1, 1), false, true, grect(2, 0, -3, 1)},
+		{FullGeneralizedRect(), wrapped, true, true, FullGeneralizedRect()},
+	} {
+		if got := test.r.Contains(test.other); got != test.contains {
+			t.Errorf("%v.Contains(%v): got %v, want %v", test.r, test.other, got, test.contains)
+		}
+		if got := test.r.Intersects(test.other); got != test.intersects {
+			t.Errorf("%v.Intersects(%v): got %v, want %v", test.r, test.other, got, test.intersects)
+		}
+		if got := test.r.Union(test.other); got != test.union {
+			t.Errorf("%v.Union(%v): got %v, want %v", test.r, test.other, got, test.union)
+		}
+		if got := test.r.Intersection(test.other); got.IsEmpty() == test.intersects {
+			t.Errorf("%v.Intersection(%v): got %v", test.r, test.other, got)
+		}
+	}
+	if got := EmptyGeneralizedRect().AddCoords(1, 0.5).AddCoords(-1, -0.5); got != a {
+		t.Errorf("AddCoords: got %v, want %v", got, a)
+	}
+	for _, r := range []GeneralizedRect{a, wrapped, EmptyGeneralizedRect(), FullGeneralizedRect()} {
+		if !r.IsValid() {
+			t.Errorf("%v.IsValid(): got false, want true", r)
+		}
+	}
+	if r := grect(0, -2, 1, 0); r.IsValid() {
+		t.Errorf("%v.IsValid(): got true, want false", r)
+	}
+}
+
+func TestGeneralizedRectPolygon(t *testing.T) {
+	const tolerance = 1e-6
+	rnd := rand.New(rand.NewSource(1))
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{New(pos, neg), New(pos, neg, WithSeam(1))} {
+			for _, r := range []GeneralizedRect{
+				grect(-1, -0.5, 1, 0.5),
+				grect(2.5, -1, -2.5, 0.2),
+				grect(-0.3, 0.5, 2, math.Pi/2),
+				grect(1, -math.Pi/2, 2, -1),
+				{s1.FullInterval(), r1.Interval{Lo: -0.4, Hi: 0.6}},
+				{s1.FullInterval(), r1.Interval{Lo: 1, Hi: math.Pi / 2}},
+			} {
+				p := gm.GeneralizedRectPolygon(r, tolerance)
+				if err := p.Validate(); err != nil {
+					t.Errorf("GeneralizedRectPolygon(%v, %v): %v", gm, r, err)
+					continue
+				}
+				bound := gm.GeneralizedRectBound(p)
+				for n := 0; n < 300; n++ {
+					q := s2.Point{randomPoint(rnd).Normalize()}
+					x, psi := gm.ToGeneralized(gm.latLngFromPoint(q))
+					// Skip points near the boundary, where the polygon is only approximate.
+					near := func(a, b float64) bool { return math.Abs(math.Remainder(a-b, 2*math.Pi)) < 0.01 }
+					if (!r.X.IsFull() && (near(float64(x), r.X.Lo) || near(float64(x), r.X.Hi))) ||
+						near(float64(psi), r.Psi.Lo) || near(float64(psi), r.Psi.Hi) {
+						continue
+					}
+					want := gm.GeneralizedRectContainsPoint(r, q)
+					if got := p.ContainsPoint(q); got != want {
+						t.Errorf("GeneralizedRectPolygon(%v, %v).ContainsPoint(%v): got %v, want %v", gm, r, s2.LatLngFromPoint(q), got, want)
+					}
+					if want && !bound.ContainsCoords(x, psi) {
+						t.Errorf("GeneralizedRectBound(%v, %v): got %v, which does not contain (%v, %v)", gm, r, bound, x, psi)
+					}
+				}
+			}
+		}
+	}
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	if p := gm.GeneralizedRectPolygon(FullGeneralizedRect(), tolerance); !p.IsFull() {
+		t.Errorf("GeneralizedRectPolygon(%v, full): got %v", gm, p)
+	}
+	if p := gm.GeneralizedRectPolygon(EmptyGeneralizedRect(), tolerance); !p.IsEmpty() {
+		t.Errorf("GeneralizedRectPolygon(%v, empty): got %v", gm, p)
+	}
+}
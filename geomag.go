@@ -0,0 +1,93 @@
+package gm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// geomagneticDipole lists the degree-1 Gauss coefficients g₁⁰, g₁¹, and h₁¹ of the International Geomagnetic
+// Reference Field (IGRF-13), in nanoteslas, at its five-year epochs. They describe the centered dipole
+// approximation of the Earth's magnetic field.
+var geomagneticDipole = []struct {
+	year          float64
+	g10, g11, h11 float64
+}{
+	{1900, -31543, -2298, 5922},
+	{1905, -31464, -2298, 5909},
+	{1910, -31354, -2297, 5898},
+	{1915, -31212, -2306, 5875},
+	{1920, -31060, -2317, 5845},
+	{1925, -30926, -2318, 5817},
+	{1930, -30805, -2316, 5808},
+	{1935, -30715, -2306, 5812},
+	{1940, -30654, -2292, 5821},
+	{1945, -30594, -2285, 5810},
+	{1950, -30554, -2250, 5815},
+	{1955, -30500, -2215, 5820},
+	{1960, -30421, -2169, 5791},
+	{1965, -30334, -2119, 5776},
+	{1970, -30220, -2068, 5737},
+	{1975, -30100, -2013, 5675},
+	{1980, -29992, -1956, 5604},
+	{1985, -29873, -1905, 5500},
+	{1990, -29775, -1848, 5406},
+	{1995, -29692, -1784, 5306},
+	{2000, -29619.4, -1728.2, 5186.1},
+	{2005, -29554.63, -1669.05, 5077.99},
+	{2010, -29496.57, -1586.42, 4944.26},
+	{2015, -29441.46, -1501.77, 4795.99},
+	{2020, -29404.8, -1450.9, 4652.5},
+}
+
+// GeomagneticPole returns the position of the north geomagnetic pole, the northern pole of the centered dipole
+// approximation of the Earth's magnetic field, in the given decimal year from 1900 to 2020. As in the IGRF,
+// the dipole coefficients are interpolated linearly between epochs. The latitude is geodetic, on the WGS 84 ellipsoid,
+// as in published pole positions. The south geomagnetic pole is its antipode.
+// GeomagneticPole returns an error if year is outside this range.
+func GeomagneticPole(year float64) (s2.LatLng, error) {
+	axis, err := geomagneticAxis(year)
+	if err != nil {
+		return s2.LatLng{}, err
+	}
+	e := (1 - WGS84Flattening) * (1 - WGS84Flattening)
+	lat := math.Atan2(axis.Z, e*math.Hypot(axis.X, axis.Y))
+	return s2.LatLng{Lat: s1.Angle(lat), Lng: s1.Angle(math.Atan2(axis.Y, axis.X))}, nil
+}
+
+// geomagneticAxis returns the geocentric unit vector toward the north geomagnetic pole in the given decimal year,
+// or an error if year is outside the range of geomagneticDipole.
+func geomagneticAxis(year float64) (r3.Vector, error) {
+	first, last := geomagneticDipole[0], geomagneticDipole[len(geomagneticDipole)-1]
+	if !(year >= first.year && year <= last.year) {
+		return r3.Vector{}, fmt.Errorf("gm: no geomagnetic pole data for year %v; want %v to %v", year, first.year, last.year)
+	}
+	n := sort.Search(len(geomagneticDipole), func(n int) bool { return geomagneticDipole[n].year >= year })
+	if n == 0 {
+		n = 1
+	}
+	a, b := geomagneticDipole[n-1], geomagneticDipole[n]
+	t := (year - a.year) / (b.year - a.year)
+	g10, g11, h11 := a.g10+t*(b.g10-a.g10), a.g11+t*(b.g11-a.g11), a.h11+t*(b.h11-a.h11)
+	// The dipole moment is in the direction (g₁¹, h₁¹, g₁⁰), and the north geomagnetic pole is opposite it.
+	return r3.Vector{X: -g11, Y: -h11, Z: -g10}.Normalize(), nil
+}
+
+// NewGeomagnetic returns a pointer to a GeneralizedMercator, configured by opts, with its positive and negative poles
+// at the north and south geomagnetic poles in the given decimal year: the points where the dipole axis meets
+// the reference sphere, whose geodetic positions GeomagneticPole reports.
+// Its generalized latitude is geomagnetic latitude, so that the auroral ovals lie roughly along horizontal lines,
+// and x is geomagnetic longitude, which is zero on the meridian through the geographic South Pole.
+// NewGeomagnetic returns an error if year is out of range or if any option is invalid.
+func NewGeomagnetic(year float64, opts ...Option) (*GeneralizedMercator, error) {
+	axis, err := geomagneticAxis(year)
+	if err != nil {
+		return nil, err
+	}
+	opts = append([]Option{WithCentralPoint(s2.LatLngFromDegrees(-90, 0))}, opts...)
+	return newGM(axis, axis.Mul(-1), opts...)
+}
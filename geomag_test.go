@@ -0,0 +1,73 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s2"
+)
+
+func TestGeomagneticPole(t *testing.T) {
+	for _, test := range []struct {
+		year float64
+		want s2.LatLng
+	}{
+		// Published positions, to the nearest hundredth of a degree
+		{1900, s2.LatLngFromDegrees(78.68, -68.79)},
+		{1925, s2.LatLngFromDegrees(78.62, -68.27)},
+		{1955, s2.LatLngFromDegrees(78.54, -69.16)},
+		{1980, s2.LatLngFromDegrees(78.88, -70.76)},
+		{2020, s2.LatLngFromDegrees(80.65, -72.68)},
+		// Between epochs
+		{1927.5, s2.LatLngFromDegrees(78.615, -68.265)},
+		{2017.5, s2.LatLngFromDegrees(80.51, -72.645)},
+	} {
+		got, err := GeomagneticPole(test.year)
+		if err != nil {
+			t.Errorf("GeomagneticPole(%v): %v", test.year, err)
+			continue
+		}
+		if d := s2.PointFromLatLng(got).Distance(s2.PointFromLatLng(test.want)).Degrees(); d > 0.015 {
+			t.Errorf("GeomagneticPole(%v): got %v, want %v", test.year, got, test.want)
+		}
+	}
+	for _, year := range []float64{1899, 2021, math.NaN()} {
+		if got, err := GeomagneticPole(year); err == nil {
+			t.Errorf("GeomagneticPole(%v): got %v, want error", year, got)
+		}
+		if got, err := NewGeomagnetic(year); err == nil {
+			t.Errorf("NewGeomagnetic(%v): got %v, want error", year, got)
+		}
+	}
+}
+
+func TestNewGeomagnetic(t *testing.T) {
+	gm, err := NewGeomagnetic(2020)
+	if err != nil {
+		t.Fatalf("NewGeomagnetic(2020): %v", err)
+	}
+	if !gm.IsAntipodalPoles() {
+		t.Errorf("NewGeomagnetic(2020): got %v, want antipodal poles", gm)
+	}
+	// The 2020 dipole coefficients of IGRF-13.
+	g10, g11, h11 := -29404.8, -1450.9, 4652.5
+	axis := s2.Point{Vector: r3.Vector{X: -g11, Y: -h11, Z: -g10}.Normalize()}
+	if got := gm.ProjectPoint(axis); !math.IsInf(got.Y, 1) {
+		t.Errorf("ProjectPoint(%v, %v): got %v, want y == +Inf", gm, axis, got)
+	}
+	if pos, _ := gm.PolePoints(); pos.Distance(axis) > 1e-15 {
+		t.Errorf("NewGeomagnetic(2020): got positive pole %v, want %v", pos, axis)
+	}
+	// Geomagnetic longitude is zero toward the geographic South Pole and 180° toward the North Pole.
+	if got := gm.Project(s2.LatLngFromDegrees(-90, 0)); math.Abs(got.X) > 1e-12 {
+		t.Errorf("Project(%v, South Pole): got %v, want x == 0", gm, got)
+	}
+	if got := gm.Project(s2.LatLngFromDegrees(90, 0)); math.Abs(math.Abs(got.X)-pi) > 1e-12 {
+		t.Errorf("Project(%v, North Pole): got %v, want x == ±π", gm, got)
+	}
+	// The geographic North Pole's geomagnetic latitude is the geocentric latitude of the dipole axis.
+	if got, want := gm.Project(s2.LatLngFromDegrees(90, 0)).Y, yFromPsi(math.Atan2(-g10, math.Hypot(g11, h11))); math.Abs(got-want) > 1e-12 {
+		t.Errorf("Project(%v, North Pole): got y == %v, want %v", gm, got, want)
+	}
+}
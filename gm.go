@@ -14,6 +14,7 @@ The Mercator projection is the special case corresponding to poles at latitude 
 package gm
 
 import (
+	"errors"
 	"math"
 
 	"github.com/golang/geo/r2"
@@ -37,6 +38,63 @@ type GeneralizedMercator struct {
 	// d is the (possibly infinite) distance to the line of intersection
 	// of the planes tangent to the unit sphere at Pos and Neg.
 	d float64
+
+	// dinv is 1/d, precomputed for the projection operations. It is 0 if Pos and Neg are antipodes.
+	dinv float64
+
+	// mercator reports whether Pos and Neg are the North and South Poles, so that the projective longitude
+	// and generalized latitude are the longitude and latitude themselves and the projection operations
+	// can use them directly.
+	mercator bool
+
+	// transverse holds the quantities that the projection operations use in place of the basis
+	// when Pos and Neg are antipodes on the Equator.
+	transverse transverseFrame
+
+	// radius is the radius of the reference sphere, by which projected coordinates are scaled.
+	radius float64
+
+	// k0 is the scale factor along the generalized equator.
+	k0 float64
+
+	// flattening is the flattening of the ellipsoid on which latitudes are geodetic, or 0 if they are spherical.
+	flattening float64
+
+	// x0 is the projective longitude, measured from the i axis, of the central line x == 0.
+	x0 float64
+
+	// cut is the projective longitude, measured from the central line, of the middle of the map.
+	// The seam is opposite it, and projected x coordinates lie within π of it before scaling.
+	cut float64
+
+	// side determines the edge of the map to which locations on the seam project.
+	side SeamSide
+
+	// psiMax, if nonzero, is the generalized latitude at which projected y coordinates are truncated.
+	psiMax float64
+
+	// extended, if nonzero, is the angle in radians from either pole within which locations are projected
+	// in double-double arithmetic.
+	extended float64
+
+	// tol is the greatest difference in each component within which near takes unit vectors to be equal,
+	// and within which newGM snaps the components of the poles to integers.
+	tol float64
+
+	// exact reports whether newGM uses the poles exactly as given, without snapping their components to integers.
+	exact bool
+
+	// policy determines the treatment of invalid locations.
+	policy LatLngPolicy
+
+	// out is the affine transformation applied to projected coordinates.
+	out affine
+
+	// central, if not nil, is a location that New places on the central line by setting x0.
+	central *s2.LatLng
+
+	// minSep is the least angle in radians between the poles that New accepts. It is only used during construction.
+	minSep float64
 }
 
 /*
@@ -59,7 +117,7 @@ points with i == d, k == 0. For any point P on the unit sphere, consider the uni
 P. Let β be the dihedral angle between this plane and the ij-plane. The intersection of this plane with the unit sphere
 is a circle; let ψ be the complement of its polar angle, related by sin(ψ) = d*sin(β). ψ is the generalized analogue of
 the conventional latitude coordinate φ, and the vertical projective coordinate y is related by the same function of ψ
-as in the Mercator projection: y = ln(tan(π/4 + ψ/2)), and inversely ψ = 2*arctan(e^y) - π/2. The horizontal projective
+as in the Mercator projection: y = ln(tan(π/4 + ψ/2)) = asinh(tan(ψ)), and inversely ψ = atan(sinh(y)). The horizontal projective
 coordinate x is equal to the arc length along the circle from the point on the circle with maximal i coordinate to P.
 
 It is convenient to define a new basis in which to consider P, rotated by an angle β around the j axis such that the
@@ -67,23 +125,156 @@ axis of the circle coincides with the k' axis. In this basis, ψ is simply the l
 from the positive i' axis.
 */
 
-// New returns a pointer to a GeneralizedMercator with poles at pos and neg.
-// It panics if pos and neg are equal.
-func New(pos, neg s2.LatLng) *GeneralizedMercator {
+// New returns a pointer to a GeneralizedMercator with poles at pos and neg, configured by opts.
+// It panics if pos and neg are equal or if any option is invalid.
+func New(pos, neg s2.LatLng, opts ...Option) *GeneralizedMercator {
+	gm, err := newFromLatLngs(pos, neg, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return gm
+}
+
+// TryNew is like New, but returns an error instead of panicking. In particular, it returns ErrIndistinguishablePoles
+// if pos and neg are too close together to define a projection, so that callers whose poles are computed
+// can detect and handle the degenerate case.
+func TryNew(pos, neg s2.LatLng, opts ...Option) (*GeneralizedMercator, error) {
+	return newFromLatLngs(pos, neg, opts...)
+}
+
+// NewFromPoints returns a pointer to a GeneralizedMercator with poles at the points pos and neg, configured by opts.
+// pos and neg need not be unit length. NewFromPoints panics if they are zero or parallel or if any option is invalid.
+func NewFromPoints(pos, neg s2.Point, opts ...Option) *GeneralizedMercator {
+	if pos.Norm2() == 0 || neg.Norm2() == 0 {
+		panic("zero-length pole")
+	}
+	gm, err := newGM(pos.Normalize(), neg.Normalize(), opts...)
+	if err != nil {
+		panic(err)
+	}
+	return gm
+}
+
+// NewFromGreatCircle returns a pointer to a GeneralizedMercator, configured by opts, whose poles are the poles
+// of the great circle through a and b, so that the great circle projects to the line y = 0.
+// The positive pole is chosen such that x increases from a to b along the shorter arc between them.
+// NewFromGreatCircle panics if a and b are equal or antipodal or if any option is invalid.
+func NewFromGreatCircle(a, b s2.LatLng, opts ...Option) *GeneralizedMercator {
+	cfg, err := applyOptions(opts)
+	if err != nil {
+		panic(err)
+	}
+	if err := cfg.checkLocations(a, b); err != nil {
+		panic(err)
+	}
+	n := cfg.pointFromLatLng(a).Cross(cfg.pointFromLatLng(b).Vector)
+	if n.Norm() < 1e-15 {
+		panic("indeterminate great circle")
+	}
+	n = n.Normalize()
+	gm, err := newGM(n, n.Mul(-1), opts...)
+	if err != nil {
+		panic(err)
+	}
+	return gm
+}
+
+// NewCentered returns a pointer to a GeneralizedMercator, configured by opts, that projects center to the origin
+// and the geodesic leaving center at the given azimuth, measured clockwise from north, to the positive x axis.
+// The central point may be overridden by a WithCentralPoint or WithCentralLongitude option.
+// NewCentered panics if any option is invalid.
+func NewCentered(center s2.LatLng, azimuth s1.Angle, opts ...Option) *GeneralizedMercator {
+	gm, err := newCentered(center, azimuth, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return gm
+}
+
+// newCentered is like NewCentered, but returns an error instead of panicking.
+func newCentered(center s2.LatLng, azimuth s1.Angle, opts ...Option) (*GeneralizedMercator, error) {
+	cfg, err := applyOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.checkLocations(center); err != nil {
+		return nil, err
+	}
+	var (
+		C = cfg.pointFromLatLng(center).Vector
+
+		sinLat, cosLat = math.Sincos(float64(center.Lat))
+		sinLng, cosLng = math.Sincos(float64(center.Lng))
+		east           = r3.Vector{X: -sinLng, Y: cosLng}
+		north          = r3.Vector{X: -sinLat * cosLng, Y: -sinLat * sinLng, Z: cosLat}
+
+		// The positive pole is the axis about which the geodesic in direction t turns counterclockwise.
+		t   = north.Mul(math.Cos(float64(azimuth))).Add(east.Mul(math.Sin(float64(azimuth))))
+		pos = C.Cross(t).Normalize()
+	)
+	return newGM(pos, pos.Mul(-1), append([]Option{WithCentralPoint(center)}, opts...)...)
+}
+
+// newFromLatLngs is like newGM, but takes the poles as locations to be interpreted according to opts.
+func newFromLatLngs(pos, neg s2.LatLng, opts ...Option) (*GeneralizedMercator, error) {
+	cfg, err := applyOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.checkLocations(pos, neg); err != nil {
+		return nil, err
+	}
+	return newGM(cfg.pointFromLatLng(pos).Vector, cfg.pointFromLatLng(neg).Vector, opts...)
+}
+
+// applyOptions returns a GeneralizedMercator with no poles or basis configured by opts,
+// for interpreting locations before the projection is constructed.
+func applyOptions(opts []Option) (*GeneralizedMercator, error) {
+	cfg := new(GeneralizedMercator)
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// ErrIndistinguishablePoles is returned by TryNew and the other error-returning constructors
+// if the poles are approximately equal, or closer than the separation required by WithMinPoleSeparation.
+var ErrIndistinguishablePoles = errors.New("gm: indistinguishable poles")
+
+// newGM returns a pointer to a GeneralizedMercator with poles at the unit vectors pos and neg, configured by opts.
+func newGM(pos, neg r3.Vector, opts ...Option) (*GeneralizedMercator, error) {
 	gm := &GeneralizedMercator{
+		pos:    pos,
+		neg:    neg,
+		radius: 1,
+		k0:     1,
+		out:    identity,
+		tol:    epsilon,
+	}
+	for _, opt := range opts {
+		if err := opt(gm); err != nil {
+			return nil, err
+		}
+	}
+	if !gm.exact {
 		// Snap each coordinate to the nearest integer if necessary to avoid math.Cos rounding error
-		pos: snapToInts(s2.PointFromLatLng(pos).Vector),
-		neg: snapToInts(s2.PointFromLatLng(neg).Vector),
+		gm.pos, gm.neg = snapToInts(gm.pos, gm.tol), snapToInts(gm.neg, gm.tol)
+	}
+	if !gm.out.isValid() {
+		return nil, errInvalidTransform
 	}
 
-	if approxEqual(gm.pos, gm.neg) {
-		panic("indistinguishable poles")
+	if gm.near(gm.pos, gm.neg) || float64(gm.pos.Angle(gm.neg)) < gm.minSep {
+		return nil, ErrIndistinguishablePoles
 	}
+	gm.minSep = 0
 
 	gm.k = gm.pos.Sub(gm.neg).Normalize()
 
 	switch {
-	case approxEqual(gm.pos, gm.neg.Mul(-1)):
+	case gm.near(gm.pos, gm.neg.Mul(-1)):
 		// Pos and Neg are antipodes; their tangent planes intersect at the line at infinity.
 		gm.d = math.Inf(1)
 
@@ -115,54 +306,416 @@ func New(pos, neg s2.LatLng) *GeneralizedMercator {
 	// j is orthogonal to Pos and Neg in the direction of increasing projectional longitude at the zero point.
 	// If Pos and Neg are not antipodes, the intersection line of the planes tangent to the unit sphere at Pos and Neg is parallel to the j axis.
 	gm.j = gm.k.Cross(gm.i)
+	gm.dinv = 1 / gm.d
+	gm.mercator = gm.pos == r3.Vector{Z: 1} && gm.neg == r3.Vector{Z: -1}
+	if gm.IsTransverse() {
+		gm.transverse = newTransverseFrame(gm.i, gm.j, gm.k, gm.tol)
+	}
 
-	return gm
+	if gm.central != nil {
+		P := gm.pointFromLatLng(*gm.central).Vector
+		if gm.near(P, gm.pos) || gm.near(P, gm.neg) {
+			return nil, errors.New("gm: central point at a pole")
+		}
+		gm.x0, _ = gm.generalized(P)
+		gm.central = nil
+	}
+
+	return gm, nil
+}
+
+// Poles returns the positive and negative poles of the projection.
+// New(gm.Poles()) is equivalent to gm.
+func (gm *GeneralizedMercator) Poles() (pos, neg s2.LatLng) {
+	return gm.latLngFromPoint(s2.Point{gm.pos}), gm.latLngFromPoint(s2.Point{gm.neg})
+}
+
+// PolePoints returns the positive and negative poles of the projection as points on the reference sphere.
+// Unlike Poles, it returns them exactly as the projection operations use them.
+// NewFromPoints(gm.PolePoints()) is equivalent to gm.
+func (gm *GeneralizedMercator) PolePoints() (pos, neg s2.Point) {
+	return s2.Point{gm.pos}, s2.Point{gm.neg}
+}
+
+// Basis returns the right-handed orthonormal basis (i, j, k) in which the projection operations are expressed.
+// The k axis is parallel to the vector from the negative pole to the positive pole,
+// and the i axis is the point at the origin of the projected plane.
+func (gm *GeneralizedMercator) Basis() (i, j, k r3.Vector) {
+	return gm.i, gm.j, gm.k
+}
+
+// TangentDistance returns the (possibly infinite) distance from the center of the unit sphere
+// to the line of intersection of the planes tangent to it at the poles.
+// This line is the set of points with i == TangentDistance(), k == 0 in the basis returned by Basis.
+func (gm *GeneralizedMercator) TangentDistance() float64 {
+	return gm.d
+}
+
+// TangentLine returns the line of intersection of the planes tangent to the unit sphere at the poles,
+// as its closest point to the center of the sphere and a unit vector in its direction,
+// which are TangentDistance() times the i axis and the j axis of the basis returned by Basis.
+// Every circle of constant generalized latitude lies in a plane containing this line.
+// If the poles are antipodes, the tangent planes are parallel and TangentLine returns ok == false.
+func (gm *GeneralizedMercator) TangentLine() (closest, direction r3.Vector, ok bool) {
+	if gm.IsAntipodalPoles() {
+		return r3.Vector{}, r3.Vector{}, false
+	}
+	return gm.i.Mul(gm.d), gm.j, true
+}
+
+// IsAntipodalPoles reports whether the poles of gm are antipodes, in which case the projection is conformal
+// and its generalized equator is the great circle equidistant from them.
+func (gm *GeneralizedMercator) IsAntipodalPoles() bool {
+	return math.IsInf(gm.d, 1)
+}
+
+// IsNormalMercator reports whether the poles of gm are the North and South Poles, in either order,
+// so that the projection is the Mercator projection in its normal aspect.
+func (gm *GeneralizedMercator) IsNormalMercator() bool {
+	return gm.IsAntipodalPoles() && (gm.k == r3.Vector{Z: 1} || gm.k == r3.Vector{Z: -1})
+}
+
+// IsTransverse reports whether the poles of gm are antipodes on the Equator,
+// so that the projection is the transverse Mercator projection, whose generalized equator is a meridian.
+func (gm *GeneralizedMercator) IsTransverse() bool {
+	return gm.IsAntipodalPoles() && gm.k.Z == 0
+}
+
+// IsOblique reports whether the poles of gm are antipodes that are neither the North and South Poles
+// nor on the Equator, so that the projection is an oblique Mercator projection.
+// If the poles are not antipodes, IsNormalMercator, IsTransverse, and IsOblique all report false.
+func (gm *GeneralizedMercator) IsOblique() bool {
+	return gm.IsAntipodalPoles() && !gm.IsNormalMercator() && !gm.IsTransverse()
+}
+
+// Equal reports whether gm and other define exactly the same projection.
+func (gm *GeneralizedMercator) Equal(other *GeneralizedMercator) bool {
+	return *gm == *other
+}
+
+// ApproxEqual reports whether the corresponding poles of gm and other are within the given angular tolerance
+// and their other parameters are equal.
+func (gm *GeneralizedMercator) ApproxEqual(other *GeneralizedMercator, tolerance s1.Angle) bool {
+	for _, p := range params {
+		a, _ := p.value(gm)
+		b, _ := p.value(other)
+		if a != b {
+			return false
+		}
+	}
+	return gm.pos.Angle(other.pos) <= tolerance && gm.neg.Angle(other.neg) <= tolerance
+}
+
+// Swapped returns the projection with the same parameters as gm but with its poles exchanged.
+// The two coordinate systems are related by a half turn about the origin: x and y are both negated,
+// so that Swapped maps each location to the reflection through the origin of its projection by gm,
+// before any output transformation. Swapped().Swapped() is equal to gm.
+func (gm *GeneralizedMercator) Swapped() *GeneralizedMercator {
+	g, err := newGM(gm.neg, gm.pos, append(gm.options(), WithCentralLongitude(-gm.x0), WithSeam(math.Pi-gm.cut), WithSeamSide(gm.side.opposite()))...)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Rotated returns the projection with the same parameters as gm but with projected x coordinates shifted by theta,
+// modulo the circumference of the generalized equator: each location's projective longitude is increased by theta
+// and reduced to the interval [-π, π], before scaling and any output transformation.
+func (gm *GeneralizedMercator) Rotated(theta s1.Angle) *GeneralizedMercator {
+	g, err := newGM(gm.pos, gm.neg, append(gm.options(), WithCentralLongitude(gm.x0-float64(theta)))...)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Recentered returns the projection with the same parameters as gm but with ll at the origin:
+// it rotates the projection horizontally so that ll is on the central line, as by WithCentralPoint,
+// and offsets the false origin so that ll projects to (0, 0) after any output transformation.
+// It panics if ll is a pole of gm.
+func (gm *GeneralizedMercator) Recentered(ll s2.LatLng) *GeneralizedMercator {
+	g, err := newGM(gm.pos, gm.neg, append(gm.options(), WithCentralPoint(ll))...)
+	if err != nil {
+		panic(err)
+	}
+	p := g.Project(ll)
+	g.out.e -= p.X
+	g.out.f -= p.Y
+	return g
 }
 
 // Project converts ll to a projected 2D point.
 func (gm *GeneralizedMercator) Project(ll s2.LatLng) r2.Point {
-	P := s2.PointFromLatLng(ll).Vector
+	return gm.out.apply(gm.project(ll))
+}
+
+// project converts ll to a projected 2D point before the output transformation.
+func (gm *GeneralizedMercator) project(ll s2.LatLng) r2.Point {
+	ll, ok := gm.location(ll)
+	if !ok {
+		return nanPoint
+	}
+	if gm.mercator {
+		// In the Mercator projection, the projective longitude and generalized latitude are the longitude
+		// and latitude themselves; using them directly avoids rounding error in the basis computations.
+		// A latitude beyond a pole continues over it to the opposite meridian, as it does in the general path.
+		lat, lng := ll.Lat, float64(ll.Lng)
+		if math.Abs(float64(lat)) > math.Pi/2 {
+			sin, cos := math.Sincos(float64(lat))
+			lat = s1.Angle(math.Atan2(sin, math.Abs(cos)))
+			if cos < 0 {
+				lng += math.Pi
+			}
+		}
+		psi := float64(gm.pointLat(lat))
+		if cos := math.Abs(math.Cos(psi)); cos == 0 || cos < gm.tol {
+			return gm.toPlane(0, math.Copysign(math.Pi/2, psi))
+		}
+		return gm.toPlane(math.Remainder(lng, 2*math.Pi), psi)
+	}
+	if gm.transverse.ok {
+		return gm.toPlane(gm.transverse.generalized(gm.pointLat(ll.Lat), ll.Lng))
+	}
+	p := gm.pointFromLatLng(ll)
+	if gm.nearPole(p.Vector) {
+		return gm.projectExtended(gm.extendedPoint(ll))
+	}
+	return gm.projectPoint(p)
+}
+
+// ProjectClamped is like Project, but clamps the projected y coordinate to the interval [-maxY, maxY],
+// so that the result is finite even at or near the poles. The clamping precedes any output transformation.
+func (gm *GeneralizedMercator) ProjectClamped(ll s2.LatLng, maxY float64) r2.Point {
+	p := gm.project(ll)
+	p.Y = math.Max(-maxY, math.Min(p.Y, maxY))
+	return gm.out.apply(p)
+}
+
+// ProjectPoint converts a point p on the reference sphere to a projected 2D point.
+func (gm *GeneralizedMercator) ProjectPoint(p s2.Point) r2.Point {
+	return gm.out.apply(gm.projectPoint(p))
+}
+
+// projectPoint converts a point p on the reference sphere to a projected 2D point before the output transformation.
+func (gm *GeneralizedMercator) projectPoint(p s2.Point) r2.Point {
+	P := p.Vector
 	switch {
-	case approxEqual(P, gm.pos):
-		return r2.Point{Y: math.Inf(1)}
-	case approxEqual(P, gm.neg):
-		return r2.Point{Y: math.Inf(-1)}
+	case gm.near(P, gm.pos):
+		return gm.toPlane(0, math.Pi/2)
+	case gm.near(P, gm.neg):
+		return gm.toPlane(0, -math.Pi/2)
+	case gm.nearPole(P):
+		return gm.projectExtended(ddVector{dd{P.X, 0}, dd{P.Y, 0}, dd{P.Z, 0}})
 	}
+	return gm.toPlane(gm.generalized(P))
+}
 
-	var (
-		beta   = math.Copysign(float64(gm.i.Sub(P.Mul(1/gm.d)).Cross(gm.j).Angle(gm.k)), P.Dot(gm.k))
-		iprime = s2.Rotate(s2.Point{gm.i}, s2.Point{gm.j}, s1.Angle(beta)).Vector
-		kprime = s2.Rotate(s2.Point{gm.k}, s2.Point{gm.j}, s1.Angle(beta)).Vector
-		psi    = math.Asin(P.Dot(kprime))
+// toPlane returns the projected point with projective longitude x, measured from the i axis, and generalized latitude psi.
+// The poles, with psi == ±π/2, project to (0, ±Inf), or to the middle of the map if the seam has been moved,
+// unless the projection is truncated.
+func (gm *GeneralizedMercator) toPlane(x, psi float64) r2.Point {
+	switch {
+	case psi >= math.Pi/2:
+		return gm.scaled(gm.cut, math.Inf(1))
+	case psi <= -math.Pi/2:
+		return gm.scaled(gm.cut, math.Inf(-1))
+	}
+	return gm.toPlaneY(x, yFromPsi(psi))
+}
 
-		y = math.Log(math.Tan(math.Pi/4 + psi/2))
-		x = math.Atan2(P.Dot(gm.j), P.Dot(iprime))
-	)
+// toPlaneY is like toPlane, but takes the finite y coordinate before scaling in place of the generalized latitude.
+func (gm *GeneralizedMercator) toPlaneY(x, y float64) r2.Point {
+	if gm.x0 != 0 || gm.cut != 0 || gm.side != SeamAsComputed {
+		x = gm.wrap(x-gm.x0-gm.cut) + gm.cut
+	}
+	return gm.scaled(x, y)
+}
 
-	return r2.Point{x, y}
+// scaled returns the point (x, y) with y truncated, if the projection is truncated, and both coordinates scaled.
+func (gm *GeneralizedMercator) scaled(x, y float64) r2.Point {
+	if gm.psiMax != 0 {
+		yMax := yFromPsi(gm.psiMax)
+		y = math.Max(-yMax, math.Min(y, yMax))
+	}
+	return r2.Point{x, y}.Mul(gm.radius * gm.k0)
+}
+
+// ToGeneralized returns the generalized coordinates of ll: its projective longitude x, measured from the central line
+// and reduced to the horizontal extent of the map as by Project, and its generalized latitude ψ.
+// The projection of ll before scaling and any output transformation is (x, ln(tan(π/4 + ψ/2))).
+// The poles have generalized latitude ±π/2 and the projective longitude of the middle of the map.
+func (gm *GeneralizedMercator) ToGeneralized(ll s2.LatLng) (x, psi s1.Angle) {
+	P := gm.pointFromLatLng(ll).Vector
+	switch {
+	case gm.near(P, gm.pos):
+		return s1.Angle(gm.cut), math.Pi / 2
+	case gm.near(P, gm.neg):
+		return s1.Angle(gm.cut), -math.Pi / 2
+	}
+	gx, gpsi := gm.generalized(P)
+	return s1.Angle(gm.wrap(gx-gm.x0-gm.cut) + gm.cut), s1.Angle(gpsi)
+}
+
+// FromGeneralized returns the location with projective longitude x, measured from the central line,
+// and generalized latitude psi, which must be in the interval [-π/2, π/2]. It is the inverse of ToGeneralized.
+func (gm *GeneralizedMercator) FromGeneralized(x, psi s1.Angle) s2.LatLng {
+	return gm.latLngFromPoint(s2.Point{gm.fromGeneralized(float64(x)+gm.x0, float64(psi))})
+}
+
+// EquatorPoint returns the point q on the generalized equator, the great circle equidistant from the poles,
+// that is nearest to p, and the generalized latitude psi of p, whose sign indicates the side of the equator
+// on which p lies. The distance from p to the equator is p.Distance(q); if the poles are antipodal, it is |psi|.
+// If p is a pole of the generalized equator, every point on the equator is equally near,
+// and EquatorPoint returns the one on the central line.
+func (gm *GeneralizedMercator) EquatorPoint(p s2.Point) (q s2.Point, psi s1.Angle) {
+	switch {
+	case gm.near(p.Vector, gm.pos):
+		psi = math.Pi / 2
+	case gm.near(p.Vector, gm.neg):
+		psi = -math.Pi / 2
+	default:
+		_, gpsi := gm.generalized(p.Vector)
+		psi = s1.Angle(gpsi)
+	}
+	v := p.Sub(gm.k.Mul(p.Dot(gm.k)))
+	if v.Norm() < epsilon {
+		return s2.Point{gm.fromGeneralized(gm.x0, 0)}, psi
+	}
+	return s2.Point{v.Normalize()}, psi
+}
+
+// generalized returns the projective longitude x, measured from the i axis, and the generalized latitude ψ of P.
+func (gm *GeneralizedMercator) generalized(P r3.Vector) (x, psi float64) {
+	// x and ψ are the longitude and latitude of P in the rotated basis, computed with atan2 to avoid the loss of precision
+	// of acos and asin near the ends of their domains.
+	u, b, w := gm.rotated(P)
+	return math.Atan2(b, u), math.Atan2(w, math.Hypot(b, u))
+}
+
+// rotated returns the coordinates of P in the basis (i', j, k') rotated by β around the j axis
+// such that the axis of the circle of P's generalized latitude coincides with the k' axis.
+// Since P is a unit vector, w is the sine of its generalized latitude.
+func (gm *GeneralizedMercator) rotated(P r3.Vector) (u, b, w float64) {
+	// Rotating the basis by β around the j axis takes i to i' = i*cos(β) - k*sin(β) and k to k' = k*cos(β) + i*sin(β),
+	// so the coordinates of P in the rotated basis follow from its coordinates a, b, c in (i, j, k).
+	// β is the angle of the vector (1 - a/d, c/d) in the ki-plane, whose components give cos(β) and sin(β) directly.
+	a, b, c := P.Dot(gm.i), P.Dot(gm.j), P.Dot(gm.k)
+	cos, sin := 1-a*gm.dinv, c*gm.dinv
+	r := math.Hypot(cos, sin)
+	cos, sin = cos/r, sin/r
+	return a*cos - c*sin, b, c*cos + a*sin
 }
 
 // Unproject converts a projected point p to a location on the reference sphere.
 func (gm *GeneralizedMercator) Unproject(p r2.Point) s2.LatLng {
+	q := gm.out.invert(p)
+	switch {
+	case math.IsInf(q.Y, 1):
+		return gm.latLngFromPoint(s2.Point{gm.pos})
+	case math.IsInf(q.Y, -1):
+		return gm.latLngFromPoint(s2.Point{gm.neg})
+	}
+	q = q.Mul(1 / (gm.radius * gm.k0))
+	if gm.mercator {
+		// As in project, the location follows directly from the projective longitude and generalized latitude.
+		return s2.LatLng{Lat: gm.latFromPointLat(s1.Angle(psiFromY(q.Y))), Lng: s1.Angle(math.Remainder(q.X+gm.x0, 2*math.Pi))}
+	}
+	return gm.locationOn(gm.parallel(psiFromY(q.Y)), q.X+gm.x0)
+}
+
+// UnprojectPoint converts a projected point p to a point on the reference sphere.
+func (gm *GeneralizedMercator) UnprojectPoint(p r2.Point) s2.Point {
+	p = gm.out.invert(p)
 	switch {
 	case math.IsInf(p.Y, 1):
-		return s2.LatLngFromPoint(s2.Point{gm.pos})
+		return s2.Point{gm.pos}
 	case math.IsInf(p.Y, -1):
-		return s2.LatLngFromPoint(s2.Point{gm.neg})
+		return s2.Point{gm.neg}
 	}
+	p = p.Mul(1 / (gm.radius * gm.k0))
+	return s2.Point{gm.fromGeneralized(p.X+gm.x0, psiFromY(p.Y))}
+}
 
-	var (
-		psi    = 2*math.Atan(math.Exp(p.Y)) - math.Pi/2
-		beta   = math.Asin(math.Sin(psi) / gm.d)
-		iprime = s2.Rotate(s2.Point{gm.i}, s2.Point{gm.j}, s1.Angle(beta))
-		kprime = s2.Rotate(s2.Point{gm.k}, s2.Point{gm.j}, s1.Angle(beta))
+// fromGeneralized returns the point with projective longitude x, measured from the i axis, and generalized latitude psi.
+func (gm *GeneralizedMercator) fromGeneralized(x, psi float64) r3.Vector {
+	return gm.fromParallel(gm.parallel(psi), x)
+}
 
-		P = iprime.Mul(math.Cos(psi) * math.Cos(p.X)).Add(gm.j.Mul(math.Cos(psi) * math.Sin(p.X))).Add(kprime.Mul(math.Sin(psi)))
+// A parallel holds the quantities on which the points with a given generalized latitude ψ depend:
+// its sine and cosine and those of the angle β by which the basis is rotated around the j axis,
+// where sin(β) = sin(ψ)/d.
+type parallel struct {
+	psi, sinPsi, cosPsi, sinBeta, cosBeta float64
+}
+
+// parallel returns the parallel of generalized latitude psi.
+func (gm *GeneralizedMercator) parallel(psi float64) parallel {
+	sinPsi, cosPsi := math.Sincos(psi)
+	sinBeta := sinPsi * gm.dinv
+	return parallel{psi: psi, sinPsi: sinPsi, cosPsi: cosPsi, sinBeta: sinBeta, cosBeta: math.Sqrt(1 - sinBeta*sinBeta)}
+}
+
+// fromParallel returns the point with projective longitude x, measured from the i axis, on the parallel pl.
+func (gm *GeneralizedMercator) fromParallel(pl parallel, x float64) r3.Vector {
+	// The point has coordinates (cos(ψ)cos(x), cos(ψ)sin(x), sin(ψ)) in the basis (i', j, k') rotated by β around the j axis.
+	// Rotating them back by β gives its coordinates a, b, c in (i, j, k) in a single step.
+	sinX, cosX := math.Sincos(x)
+	u := pl.cosPsi * cosX
+	a, b, c := u*pl.cosBeta+pl.sinPsi*pl.sinBeta, pl.cosPsi*sinX, pl.sinPsi*pl.cosBeta-u*pl.sinBeta
+	return r3.Vector{
+		X: a*gm.i.X + b*gm.j.X + c*gm.k.X,
+		Y: a*gm.i.Y + b*gm.j.Y + c*gm.k.Y,
+		Z: a*gm.i.Z + b*gm.j.Z + c*gm.k.Z,
+	}
+}
+
+// locationOn returns the location with projective longitude x, measured from the i axis, on the parallel pl.
+func (gm *GeneralizedMercator) locationOn(pl parallel, x float64) s2.LatLng {
+	switch {
+	case gm.mercator:
+		return s2.LatLng{Lat: gm.latFromPointLat(s1.Angle(pl.psi)), Lng: s1.Angle(math.Remainder(x, 2*math.Pi))}
+	case gm.transverse.ok:
+		ll := gm.transverse.fromParallel(pl, x)
+		ll.Lat = gm.latFromPointLat(ll.Lat)
+		return ll
+	}
+	return gm.latLngFromPoint(s2.Point{gm.fromParallel(pl, x)})
+}
+
+// Bounds returns the rectangle containing the projections of all points whose generalized latitude ψ
+// satisfies |ψ| <= psiMax. It is the finite extent of a map of the projection truncated at ±psiMax,
+// or at the projection's own truncation latitude if that is smaller. If the projection has an output transformation,
+// Bounds returns the rectangle bounding the transformed extent.
+func (gm *GeneralizedMercator) Bounds(psiMax s1.Angle) r2.Rect {
+	psi := math.Abs(float64(psiMax))
+	if gm.psiMax != 0 {
+		psi = math.Min(psi, gm.psiMax)
+	}
+	s := gm.radius * gm.k0
+	x, y := math.Pi*s, yFromPsi(psi)*s
+	return r2.RectFromPoints(
+		gm.fromCentered(r2.Point{X: -x, Y: -y}), gm.fromCentered(r2.Point{X: x, Y: -y}),
+		gm.fromCentered(r2.Point{X: x, Y: y}), gm.fromCentered(r2.Point{X: -x, Y: y}),
 	)
+}
+
+// yFromPsi returns the projected y coordinate corresponding to the generalized latitude psi.
+// It is ln(tan(π/4 + ψ/2)), computed as asinh(tan(ψ)), which keeps its precision near the generalized equator,
+// where the logarithm of a number near 1 loses it, and near the poles, where the rounding error of π/4 + ψ/2,
+// an angle near π/2, is magnified by its tangent.
+func yFromPsi(psi float64) float64 {
+	return math.Asinh(math.Tan(psi))
+}
 
-	return s2.LatLngFromPoint(s2.Point{P})
+// psiFromY returns the generalized latitude corresponding to the projected y coordinate.
+// It is 2*atan(e^y) - π/2, computed as atan(sinh(y)), which avoids the cancellation of that difference near y == 0.
+func psiFromY(y float64) float64 {
+	return math.Atan(math.Sinh(y))
 }
 
+// epsilon is the tolerance of approxEqual, and the default tolerance of a GeneralizedMercator.
+const epsilon = 1e-15
+
 // approxEqual is equivalent to r3.Vector's ApproxEqual method but with a larger tolerance.
 func approxEqual(a, b r3.Vector) bool {
 	// r3's epsilon of 1e-16 is too strict to accommodate some values returned by s2.PointFromLatLng
@@ -170,20 +723,29 @@ func approxEqual(a, b r3.Vector) bool {
 	// For example, s2.PointFromLatLng(Lat: 0, Lng: math.Pi) == (-1, -1.2246467991473515e-16, 0),
 	// and s2.LatLng{Lat: math.Pi/2}.ApproxEqual(s2.LatLng{Lat: math.Pi/2, Lng: math.Pi}) is false.
 	// 1e-15 is still only about 6.4 nanometers at the Earth's surface.
-	const epsilon = 1e-15
-	return math.Abs(a.X-b.X) < epsilon && math.Abs(a.Y-b.Y) < epsilon && math.Abs(a.Z-b.Z) < epsilon
+	return approxEqualWithin(a, b, epsilon)
+}
+
+// near reports whether a and b are equal within the tolerance of gm. The projection operations take a point
+// near a pole to be the pole.
+func (gm *GeneralizedMercator) near(a, b r3.Vector) bool {
+	return approxEqualWithin(a, b, gm.tol)
+}
+
+// approxEqualWithin reports whether a and b are equal or each of their components differ by less than tol.
+func approxEqualWithin(a, b r3.Vector, tol float64) bool {
+	return a == b || math.Abs(a.X-b.X) < tol && math.Abs(a.Y-b.Y) < tol && math.Abs(a.Z-b.Z) < tol
 }
 
-// snapToInts returns v with any component approximately equal to an integer rounded to that integer.
-func snapToInts(v r3.Vector) r3.Vector {
-	const epsilon = 1e-15
-	if r := math.Round(v.X); math.Abs(v.X-r) < epsilon {
+// snapToInts returns v with any component within tol of an integer rounded to that integer.
+func snapToInts(v r3.Vector, tol float64) r3.Vector {
+	if r := math.Round(v.X); math.Abs(v.X-r) < tol {
 		v.X = r
 	}
-	if r := math.Round(v.Y); math.Abs(v.Y-r) < epsilon {
+	if r := math.Round(v.Y); math.Abs(v.Y-r) < tol {
 		v.Y = r
 	}
-	if r := math.Round(v.Z); math.Abs(v.Z-r) < epsilon {
+	if r := math.Round(v.Z); math.Abs(v.Z-r) < tol {
 		v.Z = r
 	}
 	return v
@@ -1,11 +1,14 @@
 package gm
 
 import (
+	"errors"
 	"math"
 	"testing"
 
+	"github.com/golang/geo/r1"
 	"github.com/golang/geo/r2"
 	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
 	"github.com/golang/geo/s2"
 )
 
@@ -211,6 +214,152 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewFromPoints(t *testing.T) {
+	for _, test := range []struct{ p, n s2.LatLng }{
+		{s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)},
+		{s2.LatLngFromDegrees(0, 45), s2.LatLngFromDegrees(0, -135)},
+		{s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30)},
+		{s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2)},
+	} {
+		p, n := s2.PointFromLatLng(test.p), s2.PointFromLatLng(test.n)
+		want := New(test.p, test.n)
+		if got := NewFromPoints(p, n); !gmApproxEqual(got, want) {
+			t.Errorf("NewFromPoints(%v, %v): got %+v, want %+v", p, n, got, want)
+		}
+		p2, n2 := s2.Point{Vector: p.Mul(2)}, s2.Point{Vector: n.Mul(0.5)}
+		if got := NewFromPoints(p2, n2); !gmApproxEqual(got, want) {
+			t.Errorf("NewFromPoints(%v, %v): got %+v, want %+v", p2, n2, got, want)
+		}
+	}
+
+	// The pole vectors are preserved exactly when no component needs snapping.
+	p := s2.Point{Vector: r3.Vector{X: 0.48, Y: 0.6, Z: 0.64}}
+	n := s2.Point{Vector: r3.Vector{X: -0.6, Y: 0.8, Z: 0}}
+	if got := NewFromPoints(p, n); got.pos != p.Vector || got.neg != n.Vector {
+		t.Errorf("NewFromPoints(%v, %v): got poles %v, %v", p, n, got.pos, got.neg)
+	}
+}
+
+func TestNewFromGreatCircle(t *testing.T) {
+	for _, test := range []struct{ a, b s2.LatLng }{
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 90)},
+		{s2.LatLngFromDegrees(40.6, -73.8), s2.LatLngFromDegrees(51.5, -0.5)},
+		{s2.LatLngFromDegrees(-33.9, 151.2), s2.LatLngFromDegrees(25.3, 55.4)},
+		{s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30)},
+	} {
+		gm := NewFromGreatCircle(test.a, test.b)
+		pa, pb := gm.Project(test.a), gm.Project(test.b)
+		if math.Abs(pa.Y) > 1e-15 || math.Abs(pb.Y) > 1e-15 {
+			t.Errorf("NewFromGreatCircle(%v, %v): got projections %v, %v, want y == 0", test.a, test.b, pa, pb)
+		}
+		if dx := math.Remainder(pb.X-pa.X, 2*pi); dx <= 0 {
+			t.Errorf("NewFromGreatCircle(%v, %v): got projections %v, %v, want x increasing from a to b", test.a, test.b, pa, pb)
+		}
+		// Every point on the geodesic between a and b projects to y == 0.
+		for f := 0.0; f <= 1; f += 0.125 {
+			ll := s2.LatLngFromPoint(s2.Interpolate(f, s2.PointFromLatLng(test.a), s2.PointFromLatLng(test.b)))
+			if p := gm.Project(ll); math.Abs(p.Y) > 1e-15 {
+				t.Errorf("NewFromGreatCircle(%v, %v): Project(%v): got %v, want y == 0", test.a, test.b, ll, p)
+			}
+		}
+	}
+}
+
+func TestNewCentered(t *testing.T) {
+	for _, test := range []struct {
+		center  s2.LatLng
+		azimuth s1.Angle
+		pos     s2.LatLng
+	}{
+		{s2.LatLngFromDegrees(0, 0), 90 * s1.Degree, s2.LatLngFromDegrees(90, 0)},
+		{s2.LatLngFromDegrees(0, 0), 0, s2.LatLngFromDegrees(0, -90)},
+		{s2.LatLngFromDegrees(0, 90), -90 * s1.Degree, s2.LatLngFromDegrees(-90, 0)},
+		{s2.LatLngFromDegrees(45, 0), 90 * s1.Degree, s2.LatLngFromDegrees(45, 180)},
+	} {
+		gm := NewCentered(test.center, test.azimuth)
+		if pos, _ := gm.Poles(); !llApproxEqual(pos, test.pos) {
+			t.Errorf("NewCentered(%v, %v): got positive pole %v, want %v", test.center, test.azimuth, pos, test.pos)
+		}
+		if got := gm.Project(test.center); !ptApproxEqual(got, r2.Point{}) {
+			t.Errorf("NewCentered(%v, %v): Project(%v): got %v, want the origin", test.center, test.azimuth, test.center, got)
+		}
+	}
+
+	// A short step from the center in the direction of the azimuth projects onto the positive x axis.
+	for _, az := range []float64{0, 30, 135, -100} {
+		center := s2.LatLngFromDegrees(48.2, 16.4)
+		gm := NewCentered(center, s1.Angle(az)*s1.Degree)
+		step := s2.LatLng{
+			Lat: center.Lat + s1.Angle(1e-6*math.Cos(az*pi/180)),
+			Lng: center.Lng + s1.Angle(1e-6*math.Sin(az*pi/180)/math.Cos(float64(center.Lat))),
+		}
+		if p := gm.Project(step); p.X <= 0 || math.Abs(p.Y) > 1e-9 {
+			t.Errorf("NewCentered(%v, %v°): Project(%v): got %v, want a point on the positive x axis", center, az, step, p)
+		}
+	}
+}
+
+func TestPoles(t *testing.T) {
+	for _, test := range []struct{ p, n s2.LatLng }{
+		{s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)},
+		{s2.LatLngFromDegrees(0, 45), s2.LatLngFromDegrees(0, -135)},
+		{s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30)},
+		{s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2)},
+	} {
+		gm := New(test.p, test.n)
+		if p, n := gm.Poles(); !llApproxEqual(p, test.p) || !llApproxEqual(n, test.n) {
+			t.Errorf("New(%v, %v).Poles(): got %v, %v", test.p, test.n, p, n)
+		}
+		if got := New(gm.Poles()); !gmApproxEqual(got, gm) {
+			t.Errorf("New(New(%v, %v).Poles()): got %+v, want %+v", test.p, test.n, got, gm)
+		}
+		if got := NewFromPoints(gm.PolePoints()); !got.Equal(gm) {
+			t.Errorf("NewFromPoints(New(%v, %v).PolePoints()): got %+v, want %+v", test.p, test.n, got, gm)
+		}
+	}
+}
+
+func TestBasis(t *testing.T) {
+	for _, test := range projTests {
+		i, j, k := test.gm.Basis()
+		if i != test.gm.i || j != test.gm.j || k != test.gm.k {
+			t.Errorf("Basis(%+v): got %v, %v, %v", test.gm, i, j, k)
+		}
+		if !approxEqual(i.Cross(j), k) {
+			t.Errorf("Basis(%+v): %v × %v = %v, want %v", test.gm, i, j, i.Cross(j), k)
+		}
+		if d := test.gm.TangentDistance(); d != test.gm.d {
+			t.Errorf("TangentDistance(%+v): got %v, want %v", test.gm, d, test.gm.d)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := New(s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30))
+	b := New(s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30))
+	c := New(s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30+1e-9))
+	d := New(s2.LatLngFromDegrees(-60, 30), s2.LatLngFromDegrees(60, 30))
+	for _, test := range []struct {
+		a, b   *GeneralizedMercator
+		tol    s1.Angle
+		eq, ae bool
+	}{
+		{a, a, 0, true, true},
+		{a, b, 0, true, true},
+		{a, c, 0, false, false},
+		{a, c, s1.Degree * 1e-6, false, true},
+		{a, d, s1.Degree, false, false},
+		{a, New(s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30), WithRadius(2)), s1.Degree, false, false},
+	} {
+		if got := test.a.Equal(test.b); got != test.eq {
+			t.Errorf("Equal(%+v, %+v): got %v, want %v", test.a, test.b, got, test.eq)
+		}
+		if got := test.a.ApproxEqual(test.b, test.tol); got != test.ae {
+			t.Errorf("ApproxEqual(%+v, %+v, %v): got %v, want %v", test.a, test.b, test.tol, got, test.ae)
+		}
+	}
+}
+
 type proj struct {
 	s s2.LatLng
 	r r2.Point
@@ -222,12 +371,16 @@ var projTests = []struct {
 }{
 	{
 		gm: &GeneralizedMercator{
-			pos: r3.Vector{0, 0, 1},
-			neg: r3.Vector{0, 0, -1},
-			i:   r3.Vector{1, 0, 0},
-			j:   r3.Vector{0, 1, 0},
-			k:   r3.Vector{0, 0, 1},
-			d:   math.Inf(1),
+			pos:    r3.Vector{0, 0, 1},
+			neg:    r3.Vector{0, 0, -1},
+			i:      r3.Vector{1, 0, 0},
+			j:      r3.Vector{0, 1, 0},
+			k:      r3.Vector{0, 0, 1},
+			d:      math.Inf(1),
+			radius: 1,
+			k0:     1,
+			out:    identity,
+			tol:    epsilon,
 		},
 		ps: []proj{
 			{s2.LatLng{Lat: pi / 2}, r2.Point{Y: math.Inf(1)}},
@@ -248,12 +401,17 @@ var projTests = []struct {
 	},
 	{
 		gm: &GeneralizedMercator{
-			pos: r3.Vector{0.5, -sqrt3 / 2, 0},
-			neg: r3.Vector{0.5, sqrt3 / 2, 0},
-			i:   r3.Vector{1, 0, 0},
-			j:   r3.Vector{0, 0, 1},
-			k:   r3.Vector{0, -1, 0},
-			d:   2,
+			pos:    r3.Vector{0.5, -sqrt3 / 2, 0},
+			neg:    r3.Vector{0.5, sqrt3 / 2, 0},
+			i:      r3.Vector{1, 0, 0},
+			j:      r3.Vector{0, 0, 1},
+			k:      r3.Vector{0, -1, 0},
+			d:      2,
+			dinv:   0.5,
+			radius: 1,
+			k0:     1,
+			out:    identity,
+			tol:    epsilon,
 		},
 		ps: []proj{
 			{s2.LatLng{Lat: 0, Lng: -pi / 3}, r2.Point{Y: math.Inf(1)}},
@@ -274,12 +432,17 @@ var projTests = []struct {
 	},
 	{
 		gm: &GeneralizedMercator{
-			pos: r3.Vector{sqrt2 / 2, 0, -sqrt2 / 2},
-			neg: r3.Vector{-sqrt2 / 2, 0, -sqrt2 / 2},
-			i:   r3.Vector{0, 0, -1},
-			j:   r3.Vector{0, 1, 0},
-			k:   r3.Vector{1, 0, 0},
-			d:   sqrt2,
+			pos:    r3.Vector{sqrt2 / 2, 0, -sqrt2 / 2},
+			neg:    r3.Vector{-sqrt2 / 2, 0, -sqrt2 / 2},
+			i:      r3.Vector{0, 0, -1},
+			j:      r3.Vector{0, 1, 0},
+			k:      r3.Vector{1, 0, 0},
+			d:      sqrt2,
+			dinv:   1 / sqrt2,
+			radius: 1,
+			k0:     1,
+			out:    identity,
+			tol:    epsilon,
 		},
 		ps: []proj{
 			{s2.LatLng{Lat: -pi / 4, Lng: 0}, r2.Point{Y: math.Inf(1)}},
@@ -320,6 +483,57 @@ func TestUnproject(t *testing.T) {
 	}
 }
 
+func TestProjectClamped(t *testing.T) {
+	for _, test := range projTests {
+		for _, p := range test.ps {
+			want := p.r
+			want.Y = math.Max(-1, math.Min(want.Y, 1))
+			if got := test.gm.ProjectClamped(p.s, 1); !ptApproxEqual(got, want) {
+				t.Errorf("ProjectClamped(%+v, %+v, 1): got %+v, want %+v", test.gm, p.s, got, want)
+			}
+		}
+	}
+}
+
+func TestProjectPoint(t *testing.T) {
+	for _, test := range projTests {
+		for _, p := range test.ps {
+			if got := test.gm.ProjectPoint(s2.PointFromLatLng(p.s)); !ptApproxEqual(got, p.r) {
+				t.Errorf("ProjectPoint(%+v, %+v): got %+v, want %+v", test.gm, s2.PointFromLatLng(p.s), got, p.r)
+			}
+		}
+	}
+}
+
+func TestUnprojectPoint(t *testing.T) {
+	for _, test := range projTests {
+		for _, p := range test.ps {
+			if got := test.gm.UnprojectPoint(p.r); !approxEqual(got.Vector, s2.PointFromLatLng(p.s).Vector) {
+				t.Errorf("UnprojectPoint(%+v, %+v): got %+v, want %+v", test.gm, p.r, got, s2.PointFromLatLng(p.s))
+			}
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	for _, test := range projTests {
+		for _, psi := range []s1.Angle{0, s1.Angle(math.Asin(0.5)), s1.Angle(math.Atan(math.Sinh(pi)))} {
+			got := test.gm.Bounds(psi)
+			y := math.Asinh(math.Tan(float64(psi)))
+			want := r2.Rect{X: r1.Interval{Lo: -pi, Hi: pi}, Y: r1.Interval{Lo: -y, Hi: y}}
+			if !got.ApproxEqual(want) {
+				t.Errorf("Bounds(%+v, %v): got %v, want %v", test.gm, psi, got, want)
+			}
+		}
+		b := test.gm.Bounds(s1.Angle(math.Asin(0.5)) + 1e-12)
+		for _, p := range test.ps {
+			if in := b.ContainsPoint(p.r); in != (math.Abs(p.r.Y) <= math.Log(sqrt3)+1e-9) {
+				t.Errorf("Bounds(%+v, asin(0.5)).ContainsPoint(%v): got %v", test.gm, p.r, in)
+			}
+		}
+	}
+}
+
 func ptApproxEqual(a, b r2.Point) bool {
 	return (a.X == b.X || math.Abs(a.X-b.X) < 1e-15) && (a.Y == b.Y || math.Abs(a.Y-b.Y) < 1e-15)
 }
@@ -383,14 +597,393 @@ func p3(c, d float64) r3.Vector {
 
 func BenchmarkProject(b *testing.B) {
 	gm := New(s2.LatLng{Lat: math.Pi / 3}, s2.LatLng{Lat: -math.Pi / 3})
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		gm.Project(s2.LatLng{Lat: math.Pi / 4, Lng: 3 * math.Pi / 4})
 	}
 }
 
+func TestProjectAllocs(t *testing.T) {
+	for _, test := range projTests {
+		gm := New(test.gm.Poles())
+		ll := s2.LatLngFromDegrees(30, 40)
+		if allocs := testing.AllocsPerRun(10, func() { gm.Project(ll) }); allocs != 0 {
+			t.Errorf("%v.Project: got %v allocations, want 0", gm, allocs)
+		}
+	}
+}
+
 func BenchmarkUnproject(b *testing.B) {
 	gm := New(s2.LatLng{Lat: math.Pi / 3}, s2.LatLng{Lat: -math.Pi / 3})
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		gm.Unproject(r2.Point{1, 1})
 	}
 }
+
+func TestMercatorFastPath(t *testing.T) {
+	for _, gm := range []*GeneralizedMercator{
+		New(s2.LatLng{Lat: pi / 2}, s2.LatLng{Lat: -pi / 2}),
+		New(s2.LatLng{Lat: pi / 2}, s2.LatLng{Lat: -pi / 2}, WithRadius(2), WithCentralLongitude(0.5), WithSeam(1)),
+		New(s2.LatLng{Lat: pi / 2}, s2.LatLng{Lat: -pi / 2}, WithGeodeticLatitude(WGS84Flattening), WithAffine(0, -1, 1, 0, 2, 3)),
+	} {
+		if !gm.mercator {
+			t.Fatalf("%v: mercator == false, want true", gm)
+		}
+		general := *gm
+		general.mercator = false
+		for lat := -90.0; lat <= 90; lat += 15 {
+			for lng := -165.0; lng <= 180; lng += 15 {
+				ll := s2.LatLngFromDegrees(lat, lng)
+				p := gm.Project(ll)
+				// The general path differs by the rounding error of its basis computations.
+				if want := general.Project(ll); p != want && !(p.Sub(want).Norm() < 1e-12) {
+					t.Errorf("%v.Project(%v): got %v, want %v", gm, ll, p, want)
+				}
+				if got, want := gm.Unproject(p), general.Unproject(p); !llApproxEqual(got, want) {
+					t.Errorf("%v.Unproject(%v): got %v, want %v", gm, p, got, want)
+				}
+			}
+		}
+		// Latitudes beyond the poles continue over them to the opposite meridian, which is never the seam here.
+		for _, lat := range []float64{-350, -270, -180, -135, -100, -90.5, 90.5, 100, 135, 180, 270, 350} {
+			for lng := -170.0; lng < 180; lng += 20 {
+				ll := s2.LatLngFromDegrees(lat, lng)
+				p, want := gm.Project(ll), general.Project(ll)
+				if p != want && !(p.Sub(want).Norm() < 1e-12) {
+					t.Errorf("%v.Project(%v): got %v, want %v", gm, ll, p, want)
+				}
+			}
+		}
+	}
+	for _, test := range []struct {
+		ll   s2.LatLng
+		want r2.Point
+	}{
+		{s2.LatLngFromDegrees(100, 0), r2.Point{X: math.Pi, Y: math.Asinh(math.Tan(80 * math.Pi / 180))}},
+		{s2.LatLngFromDegrees(-100, 30), r2.Point{X: -150 * math.Pi / 180, Y: -math.Asinh(math.Tan(80 * math.Pi / 180))}},
+	} {
+		gm := New(s2.LatLng{Lat: pi / 2}, s2.LatLng{Lat: -pi / 2})
+		if got := gm.Project(test.ll); !(got.Sub(test.want).Norm() < 1e-12) {
+			t.Errorf("Project(%v): got %v, want %v", test.ll, got, test.want)
+		}
+	}
+}
+
+func TestTransverseFastPath(t *testing.T) {
+	for _, poles := range [][2]s2.LatLng{
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 180)},
+		{s2.LatLngFromDegrees(0, 90), s2.LatLngFromDegrees(0, -90)},
+		{s2.LatLngFromDegrees(0, -90), s2.LatLngFromDegrees(0, 90)},
+		{s2.LatLngFromDegrees(0, 30), s2.LatLngFromDegrees(0, -150)},
+	} {
+		for _, gm := range []*GeneralizedMercator{
+			New(poles[0], poles[1]),
+			New(poles[0], poles[1], WithRadius(2), WithCentralLongitude(0.5), WithSeam(1)),
+			New(poles[0], poles[1], WithGeodeticLatitude(WGS84Flattening), WithAffine(0, -1, 1, 0, 2, 3)),
+		} {
+			if !gm.transverse.ok {
+				t.Fatalf("%v: transverse.ok == false, want true", gm)
+			}
+			general := *gm
+			general.transverse = transverseFrame{}
+			for lat := -90.0; lat <= 90; lat += 15 {
+				for lng := -165.0; lng <= 180; lng += 15 {
+					ll := s2.LatLngFromDegrees(lat, lng)
+					// Locations on the seam may project to either edge of the map, as computed.
+					p := gm.Project(ll)
+					if want := general.Project(ll); p != want && !(gm.WrapDestination(want, p).Sub(want).Norm() < 1e-12) {
+						t.Errorf("%v.Project(%v): got %v, want %v", gm, ll, p, want)
+					}
+					if got, want := gm.Unproject(p), general.Unproject(p); !s2.PointFromLatLng(got).ApproxEqual(s2.PointFromLatLng(want)) {
+						t.Errorf("%v.Unproject(%v): got %v, want %v", gm, p, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkProjectMercator(b *testing.B) {
+	gm := New(s2.LatLng{Lat: math.Pi / 2}, s2.LatLng{Lat: -math.Pi / 2})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		gm.Project(s2.LatLng{Lat: math.Pi / 4, Lng: 3 * math.Pi / 4})
+	}
+}
+
+func BenchmarkUnprojectMercator(b *testing.B) {
+	gm := New(s2.LatLng{Lat: math.Pi / 2}, s2.LatLng{Lat: -math.Pi / 2})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		gm.Unproject(r2.Point{1, 1})
+	}
+}
+
+func BenchmarkProjectTransverse(b *testing.B) {
+	gm := New(s2.LatLng{Lng: math.Pi / 2}, s2.LatLng{Lng: -math.Pi / 2})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		gm.Project(s2.LatLng{Lat: math.Pi / 4, Lng: 3 * math.Pi / 4})
+	}
+}
+
+func BenchmarkUnprojectTransverse(b *testing.B) {
+	gm := New(s2.LatLng{Lng: math.Pi / 2}, s2.LatLng{Lng: -math.Pi / 2})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		gm.Unproject(r2.Point{1, 1})
+	}
+}
+
+func TestSwapped(t *testing.T) {
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{New(pos, neg), New(pos, neg, WithCentralLongitude(0.5), WithRadius(2)), New(pos, neg, WithSeam(2))} {
+			sw := gm.Swapped()
+			if p, n := sw.Poles(); p != neg || n != pos {
+				t.Errorf("Swapped(%v).Poles(): got %v, %v, want %v, %v", gm, p, n, neg, pos)
+			}
+			if got := sw.Swapped(); !got.Equal(gm) {
+				t.Errorf("Swapped(Swapped(%v)): got %v, want %v", gm, got, gm)
+			}
+			for _, p := range test.ps {
+				want := gm.Project(p.s)
+				if math.Abs(math.Abs(want.X)-pi*gm.radius) < 1e-9 {
+					// x = ±π is ambiguous.
+					continue
+				}
+				want = want.Mul(-1)
+				got := sw.Project(p.s)
+				if math.IsInf(want.Y, 0) {
+					if got.Y != want.Y {
+						t.Errorf("Project(%v, %v): got %v, want %v", sw, p.s, got, want)
+					}
+				} else if !ptApproxEqual(got, want) {
+					t.Errorf("Project(%v, %v): got %v, want %v", sw, p.s, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestRotated(t *testing.T) {
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg, WithRadius(2))
+		for _, theta := range []s1.Angle{0, 1, -2.5, 7} {
+			rot := gm.Rotated(theta)
+			for _, p := range test.ps {
+				want := gm.Project(p.s)
+				if !math.IsInf(want.Y, 0) {
+					want.X = 2 * math.Remainder(want.X/2+float64(theta), 2*pi)
+				}
+				got := rot.Project(p.s)
+				if math.Abs(math.Abs(want.X)-2*pi) < 1e-9 && math.Abs(math.Abs(got.X)-2*pi) < 1e-9 {
+					// x = ±π is ambiguous.
+					want.X = got.X
+				}
+				if math.IsInf(want.Y, 0) {
+					if got.Y != want.Y {
+						t.Errorf("Project(%v, %v): got %v, want %v", rot, p.s, got, want)
+					}
+				} else if !ptApproxEqual(got, want) {
+					t.Errorf("Project(%v, %v): got %v, want %v", rot, p.s, got, want)
+				}
+			}
+		}
+		if got := gm.Rotated(1).Rotated(-1); !got.ApproxEqual(gm, 0) {
+			t.Errorf("Rotated(Rotated(%v, 1), -1): got %v, want %v", gm, got, gm)
+		}
+	}
+}
+
+func TestRecentered(t *testing.T) {
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{
+			New(pos, neg),
+			New(pos, neg, WithRadius(2), WithAffine(0, -1, 1, 0, 3, 4)),
+		} {
+			for _, p := range test.ps {
+				if math.IsInf(p.r.Y, 0) {
+					continue
+				}
+				rc := gm.Recentered(p.s)
+				if got := rc.Project(p.s); got.Norm() > 1e-14 {
+					t.Errorf("Project(%v, %v): got %v, want (0, 0)", rc, p.s, got)
+				}
+				if got := rc.Unproject(r2.Point{}); !s2.PointFromLatLng(got).ApproxEqual(s2.PointFromLatLng(p.s)) {
+					t.Errorf("Unproject(%v, (0, 0)): got %v, want %v", rc, got, p.s)
+				}
+			}
+		}
+	}
+}
+
+func TestClassification(t *testing.T) {
+	for _, test := range []struct {
+		pos, neg                               s2.LatLng
+		antipodal, normal, transverse, oblique bool
+	}{
+		{s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), true, true, false, false},
+		{s2.LatLngFromDegrees(-90, 0), s2.LatLngFromDegrees(90, 0), true, true, false, false},
+		{s2.LatLngFromDegrees(0, 30), s2.LatLngFromDegrees(0, -150), true, false, true, false},
+		{s2.LatLngFromDegrees(45, 30), s2.LatLngFromDegrees(-45, -150), true, false, false, true},
+		{s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0), false, false, false, false},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 90), false, false, false, false},
+	} {
+		gm := New(test.pos, test.neg)
+		if got := gm.IsAntipodalPoles(); got != test.antipodal {
+			t.Errorf("IsAntipodalPoles(%v): got %v, want %v", gm, got, test.antipodal)
+		}
+		if got := gm.IsNormalMercator(); got != test.normal {
+			t.Errorf("IsNormalMercator(%v): got %v, want %v", gm, got, test.normal)
+		}
+		if got := gm.IsTransverse(); got != test.transverse {
+			t.Errorf("IsTransverse(%v): got %v, want %v", gm, got, test.transverse)
+		}
+		if got := gm.IsOblique(); got != test.oblique {
+			t.Errorf("IsOblique(%v): got %v, want %v", gm, got, test.oblique)
+		}
+	}
+}
+
+func TestTangentLine(t *testing.T) {
+	for _, test := range projTests {
+		gm := New(test.gm.Poles())
+		T, dir, ok := gm.TangentLine()
+		if ok != !math.IsInf(gm.d, 1) {
+			t.Errorf("TangentLine(%v): got ok == %v", gm, ok)
+		}
+		if !ok {
+			continue
+		}
+		if got := dir.Norm(); math.Abs(got-1) > 1e-15 {
+			t.Errorf("TangentLine(%v): got direction of length %v, want 1", gm, got)
+		}
+		if got := T.Dot(dir); math.Abs(got) > 1e-15 {
+			t.Errorf("TangentLine(%v): got closest·direction == %v, want 0", gm, got)
+		}
+		// Every point of the line is in both tangent planes.
+		for _, s := range []float64{0, 1, -3} {
+			P := T.Add(dir.Mul(s))
+			for _, pole := range []r3.Vector{gm.pos, gm.neg} {
+				if got := P.Dot(pole); math.Abs(got-1) > 1e-14 {
+					t.Errorf("TangentLine(%v): got point %v with %v·%v == %v, want 1", gm, P, P, pole, got)
+				}
+			}
+		}
+	}
+}
+
+func TestTryNew(t *testing.T) {
+	a, b := s2.LatLngFromDegrees(45, 10), s2.LatLngFromDegrees(45.5, 10)
+	for _, test := range []struct {
+		pos, neg s2.LatLng
+		opts     []Option
+		want     error
+	}{
+		{a, b, nil, nil},
+		{a, a, nil, ErrIndistinguishablePoles},
+		{a, b, []Option{WithMinPoleSeparation(s1.Degree)}, ErrIndistinguishablePoles},
+		{a, b, []Option{WithMinPoleSeparation(s1.Degree / 4)}, nil},
+	} {
+		gm, err := TryNew(test.pos, test.neg, test.opts...)
+		if !errors.Is(err, test.want) {
+			t.Errorf("TryNew(%v, %v): got error %v, want %v", test.pos, test.neg, err, test.want)
+		}
+		if err == nil && !gm.Equal(New(test.pos, test.neg)) {
+			t.Errorf("TryNew(%v, %v): got %v, want %v", test.pos, test.neg, gm, New(test.pos, test.neg))
+		}
+	}
+	if _, err := TryNew(a, b, WithMinPoleSeparation(-1)); err == nil || errors.Is(err, ErrIndistinguishablePoles) {
+		t.Errorf("TryNew with WithMinPoleSeparation(-1): got error %v, want invalid option", err)
+	}
+}
+
+func TestGeneralized(t *testing.T) {
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{
+			New(pos, neg),
+			New(pos, neg, WithRadius(2), WithCentralLongitude(0.5), WithSeam(1)),
+			New(pos, neg, WithGeodeticLatitude(WGS84Flattening), WithAffine(0, -1, 1, 0, 2, 3)),
+		} {
+			s := gm.radius * gm.k0
+			for _, p := range test.ps {
+				x, psi := gm.ToGeneralized(p.s)
+				want := gm.out.invert(gm.Project(p.s))
+				if math.IsInf(want.Y, 0) {
+					if psi != s1.Angle(math.Copysign(pi/2, want.Y)) {
+						t.Errorf("ToGeneralized(%v, %v): got ψ == %v, want %v", gm, p.s, psi, math.Copysign(pi/2, want.Y))
+					}
+				} else if got := (r2.Point{X: float64(x), Y: yFromPsi(float64(psi))}).Mul(s); !ptApproxEqual(got, want) {
+					t.Errorf("ToGeneralized(%v, %v): got %v, %v, which projects to %v, want %v", gm, p.s, x, psi, got, want)
+				}
+				if ll := gm.FromGeneralized(x, psi); !s2.PointFromLatLng(ll).ApproxEqual(s2.PointFromLatLng(p.s)) {
+					t.Errorf("FromGeneralized(%v, %v, %v): got %v, want %v", gm, x, psi, ll, p.s)
+				}
+			}
+		}
+	}
+}
+
+func TestGeneralizedNearPoles(t *testing.T) {
+	gm := New(s2.LatLng{Lat: pi / 2}, s2.LatLng{Lat: -pi / 2})
+	for _, delta := range []float64{1e-4, 1e-8, 1e-12} {
+		sin, cos := math.Sincos(delta)
+		for _, P := range []r3.Vector{{X: sin, Z: cos}, {Y: sin, Z: -cos}} {
+			_, psi := gm.generalized(P)
+			if got := pi/2 - math.Abs(psi); math.Abs(got-delta) > 1e-15 {
+				t.Errorf("generalized(%v): got ψ == %v, %v from the pole, want %v", P, psi, got, delta)
+			}
+		}
+	}
+}
+
+func TestYFromPsi(t *testing.T) {
+	// Near the generalized equator, y = ψ + ψ³/6 + O(ψ⁵) and ψ = y - y³/6 + O(y⁵).
+	for _, v := range []float64{1e-300, 1e-12, 1e-8, 1e-5} {
+		if got, want := yFromPsi(v), v+v*v*v/6; math.Abs(got-want) > 1e-15*want {
+			t.Errorf("yFromPsi(%v): got %v, want %v", v, got, want)
+		}
+		if got, want := psiFromY(v), v-v*v*v/6; math.Abs(got-want) > 1e-15*want {
+			t.Errorf("psiFromY(%v): got %v, want %v", v, got, want)
+		}
+	}
+	for _, psi := range []float64{1e-10, 0.3, 1, 1.5, 1.57, 1.5707963} {
+		for _, psi := range []float64{psi, -psi} {
+			if got := psiFromY(yFromPsi(psi)); math.Abs(got-psi) > 1e-15*math.Abs(psi) {
+				t.Errorf("psiFromY(yFromPsi(%v)): got %v", psi, got)
+			}
+		}
+	}
+}
+
+func TestEquatorPoint(t *testing.T) {
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg)
+		for _, p := range test.ps {
+			P := s2.PointFromLatLng(p.s)
+			q, psi := gm.EquatorPoint(P)
+			if got := gm.ProjectPoint(q); math.Abs(got.Y) > 1e-12 {
+				t.Errorf("EquatorPoint(%v, %v): got %v, which projects to %v", gm, p.s, q, got)
+			}
+			if want := s1.Angle(math.Asin(math.Abs(P.Dot(gm.k)))); math.Abs(float64(P.Distance(q)-want)) > 1e-12 {
+				t.Errorf("EquatorPoint(%v, %v): got %v at distance %v, want %v", gm, p.s, q, P.Distance(q), want)
+			}
+			if want := gm.out.invert(gm.Project(p.s)).Y; math.IsInf(want, 0) {
+				if psi != s1.Angle(math.Copysign(pi/2, want)) {
+					t.Errorf("EquatorPoint(%v, %v): got ψ == %v, want ±π/2", gm, p.s, psi)
+				}
+			} else if got := yFromPsi(float64(psi)); math.Abs(got-want) > 1e-12 {
+				t.Errorf("EquatorPoint(%v, %v): got ψ == %v, which projects to y == %v, want %v", gm, p.s, psi, got, want)
+			}
+			if gm.IsAntipodalPoles() && math.Abs(float64(P.Distance(q)-psi.Abs())) > 1e-12 {
+				t.Errorf("EquatorPoint(%v, %v): got distance %v, want |ψ| == %v", gm, p.s, P.Distance(q), psi.Abs())
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A GraticuleLine is a line of the projection's own graticule: a curve of constant projective longitude x,
+// which projects to a vertical line, or of constant generalized latitude ψ, which projects to a horizontal line,
+// before any output transformation.
+type GraticuleLine struct {
+	// Meridian reports whether the line has constant projective longitude rather than constant generalized latitude.
+	Meridian bool
+
+	// Value is the projective longitude of the line, measured from the central line, or its generalized latitude.
+	Value s1.Angle
+
+	// Sphere is a polyline on the reference sphere that approximates the line.
+	// The polyline of a line of constant generalized latitude is closed: its last vertex is its first.
+	Sphere s2.Polyline
+
+	// Plane is the projection of the line, which is a straight segment from Plane[0] to Plane[1].
+	Plane [2]r2.Point
+}
+
+// Graticule returns the lines of constant projective longitude that are multiples of xStep, and of constant
+// generalized latitude that are multiples of psiStep, within the part of the map with generalized latitudes
+// between -psiMax and psiMax, or the projection's own truncation latitude if that is smaller. Lines of constant
+// projective longitude extend between these latitudes, and lines of constant generalized latitude extend
+// across the map between its left and right edges. The lines on the sphere are approximated to within tolerance.
+// Both steps must be positive, and psiMax must be less than π/2. Lines of constant projective longitude
+// are listed first, in order of increasing x, followed by lines of constant generalized latitude in order
+// of increasing ψ. A line on the seam appears only once, on the left edge of the map.
+func (gm *GeneralizedMercator) Graticule(xStep, psiStep, psiMax, tolerance s1.Angle) []GraticuleLine {
+	psi := math.Abs(float64(psiMax))
+	if gm.psiMax != 0 {
+		psi = math.Min(psi, gm.psiMax)
+	}
+	s := gm.radius * gm.k0
+	var lines []GraticuleLine
+	left := gm.cut - math.Pi
+	// The bounds on the multiples of the steps allow for rounding error, so that a line exactly on the edge
+	// of the map or at psiMax is neither omitted nor duplicated.
+	const slack = 1e-9
+	for k, end := math.Ceil(left/float64(xStep)-slack), math.Ceil((left+2*math.Pi)/float64(xStep)-slack); k < end; k++ {
+		x := k * float64(xStep)
+		lines = append(lines, GraticuleLine{
+			Meridian: true,
+			Value:    s1.Angle(x),
+			Sphere:   gm.meridianCurve(x, psi, tolerance),
+			Plane: [2]r2.Point{
+				gm.out.apply(r2.Point{X: x * s, Y: -yFromPsi(psi) * s}),
+				gm.out.apply(r2.Point{X: x * s, Y: yFromPsi(psi) * s}),
+			},
+		})
+	}
+	for k, end := math.Ceil(-psi/float64(psiStep)-slack), math.Floor(psi/float64(psiStep)+slack); k <= end; k++ {
+		p := math.Max(-psi, math.Min(k*float64(psiStep), psi))
+		lines = append(lines, GraticuleLine{
+			Value:  s1.Angle(p),
+			Sphere: gm.parallelCurve(p, tolerance),
+			Plane: [2]r2.Point{
+				gm.out.apply(r2.Point{X: left * s, Y: yFromPsi(p) * s}),
+				gm.out.apply(r2.Point{X: (left + 2*math.Pi) * s, Y: yFromPsi(p) * s}),
+			},
+		})
+	}
+	return lines
+}
+
+// MeridianCurve returns a polyline from Neg to Pos that approximates to within tolerance, which must be positive,
+// the curve of locations that project to the vertical line at x, in projected units before any output transformation.
+// Its vertices are in order of increasing projected y coordinate. Values of x that differ by a multiple of
+// the period of the projection describe the same curve.
+func (gm *GeneralizedMercator) MeridianCurve(x float64, tolerance s1.Angle) s2.Polyline {
+	return gm.meridianCurve(x/(gm.radius*gm.k0), math.Pi/2, tolerance)
+}
+
+// ParallelCurve returns the cap on the reference sphere whose boundary is the circle of locations that project
+// to the horizontal line at y, in projected units before any output transformation. The cap contains Pos and
+// the locations that project above the line. Its boundary is the curve of constant generalized latitude ψ,
+// where y is ln(tan(π/4 + ψ/2)) times the radius and scale factor.
+func (gm *GeneralizedMercator) ParallelCurve(y float64) s2.Cap {
+	return gm.parallelCap(psiFromY(y / (gm.radius * gm.k0)))
+}
+
+// parallelCap returns the cap whose boundary is the curve of generalized latitude psi, containing Pos.
+func (gm *GeneralizedMercator) parallelCap(psi float64) s2.Cap {
+	// The locations with generalized latitude psi lie on the circle with center sin(psi) k'
+	// and radius cos(psi), where k' is the k axis rotated as in fromGeneralized.
+	beta := math.Asin(math.Sin(psi) / gm.d)
+	axis := s2.Rotate(s2.Point{gm.k}, s2.Point{gm.j}, s1.Angle(beta))
+	return s2.CapFromCenterAngle(axis, s1.Angle(math.Pi/2-psi))
+}
+
+// meridianCurve returns a polyline that approximates to within tolerance the curve of projective longitude x,
+// measured from the central line, from generalized latitude -psiMax to psiMax.
+func (gm *GeneralizedMercator) meridianCurve(x, psiMax float64, tolerance s1.Angle) s2.Polyline {
+	f := func(psi float64) s2.Point { return s2.Point{gm.fromGeneralized(gm.x0+x, psi)} }
+	line := tessellate(f, -psiMax, 0, tolerance)
+	return append(line, tessellate(f, 0, psiMax, tolerance)[1:]...)
+}
+
+// parallelCurve returns a closed polyline that approximates to within tolerance the curve of generalized latitude psi,
+// beginning and ending on the left edge of the map.
+func (gm *GeneralizedMercator) parallelCurve(psi float64, tolerance s1.Angle) s2.Polyline {
+	left := gm.x0 + gm.cut - math.Pi
+	f := func(x float64) s2.Point { return s2.Point{gm.fromGeneralized(x, psi)} }
+	var line s2.Polyline
+	for n := 0; n < 4; n++ {
+		// Each quarter of the circle is less than a half turn, so its chord determines it.
+		q := tessellate(f, left+float64(n)*math.Pi/2, left+float64(n+1)*math.Pi/2, tolerance)
+		if n > 0 {
+			q = q[1:]
+		}
+		line = append(line, q...)
+	}
+	line[len(line)-1] = line[0]
+	return line
+}
@@ -0,0 +1,125 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestGraticule(t *testing.T) {
+	const tolerance = 1e-4
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{
+			New(pos, neg),
+			New(pos, neg, WithCentralLongitude(0.4), WithSeam(2), WithRadius(3)),
+			New(pos, neg, WithAffine(0, -1, 1, 0, 2, 3)),
+		} {
+			lines := gm.Graticule(pi/6, pi/12, 5*pi/12, tolerance)
+			var meridians, parallels int
+			for _, l := range lines {
+				if l.Meridian {
+					meridians++
+				} else {
+					parallels++
+				}
+				// The vertices of the line on the sphere project onto the line in the plane.
+				a, b := l.Plane[0], l.Plane[1]
+				for _, v := range l.Sphere {
+					p := gm.WrapDestination(a, gm.ProjectPoint(v))
+					if l.Meridian {
+						if d := math.Abs(b.Sub(a).Cross(p.Sub(a))) / b.Sub(a).Norm(); d > 1e-9 {
+							t.Errorf("Graticule(%v): vertex %v of meridian %v projects to %v, %v from the line", gm, v, l.Value, p, d)
+							break
+						}
+					} else if q := gm.out.invert(p); math.Abs(q.Y-yFromPsi(float64(l.Value))*gm.radius*gm.k0) > 1e-9 {
+						t.Errorf("Graticule(%v): vertex %v of parallel %v projects to %v", gm, v, l.Value, p)
+						break
+					}
+				}
+				// Every point of the curve is near the polyline.
+				for f := 0.0; f <= 1; f += 1.0 / 256 {
+					var p s2.Point
+					if l.Meridian {
+						p = s2.Point{gm.fromGeneralized(gm.x0+float64(l.Value), (2*f-1)*5*pi/12)}
+					} else {
+						p = s2.Point{gm.fromGeneralized(2*pi*f, float64(l.Value))}
+					}
+					if d := distanceFromPolyline(p, l.Sphere); d > 1.01*tolerance {
+						t.Errorf("Graticule(%v): point %v of line %v is %v from the polyline", gm, f, l.Value, d)
+						break
+					}
+				}
+				if !l.Meridian && l.Sphere[0] != l.Sphere[len(l.Sphere)-1] {
+					t.Errorf("Graticule(%v): parallel %v is not closed", gm, l.Value)
+				}
+			}
+			if meridians != 12 || parallels != 11 {
+				t.Errorf("Graticule(%v): got %d meridians and %d parallels, want 12 and 11", gm, meridians, parallels)
+			}
+		}
+	}
+
+	// Truncation limits the extent of the graticule.
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithTruncation(pi/4))
+	lines := gm.Graticule(pi/2, pi/8, s1.Angle(1.5), 1e-6)
+	if len(lines) != 4+5 {
+		t.Fatalf("Graticule(%v): got %d lines, want 9", gm, len(lines))
+	}
+	if got, want := lines[0].Plane[1].Y, yFromPsi(pi/4); math.Abs(got-want) > 1e-15 {
+		t.Errorf("Graticule(%v): meridian ends at y == %v, want %v", gm, got, want)
+	}
+}
+
+func TestMeridianCurve(t *testing.T) {
+	const tolerance = 1e-4
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg, WithRadius(2), WithCentralLongitude(0.3))
+		for _, x := range []float64{0, 1, -2.5, 6} {
+			line := gm.MeridianCurve(x, tolerance)
+			if !line[0].ApproxEqual(s2.Point{gm.neg}) || !line[len(line)-1].ApproxEqual(s2.Point{gm.pos}) {
+				t.Errorf("MeridianCurve(%v, %v): got endpoints %v, %v", gm, x, line[0], line[len(line)-1])
+			}
+			want := math.Remainder(x, 2*pi*gm.radius)
+			for _, v := range line[1 : len(line)-1] {
+				if got := gm.ProjectPoint(v); math.Abs(math.Remainder(got.X-want, 2*pi*gm.radius)) > 1e-9 {
+					t.Errorf("MeridianCurve(%v, %v): vertex %v projects to %v", gm, x, v, got)
+					break
+				}
+			}
+			for y := -3.0; y <= 3; y += 0.25 {
+				p := gm.UnprojectPoint(r2.Point{X: x, Y: y})
+				if d := distanceFromPolyline(p, line); d > 1.01*tolerance {
+					t.Errorf("MeridianCurve(%v, %v): %v is %v from the polyline", gm, x, p, d)
+				}
+			}
+		}
+	}
+}
+
+func TestParallelCurve(t *testing.T) {
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg, WithRadius(2))
+		for _, y := range []float64{0, 1, -2.5, 6} {
+			c := gm.ParallelCurve(y)
+			if !c.ContainsPoint(s2.Point{gm.pos}) || c.ContainsPoint(s2.Point{gm.neg}) {
+				t.Errorf("ParallelCurve(%v, %v): %v does not separate the poles", gm, y, c)
+			}
+			for x := -3.0; x <= 3; x += 0.5 {
+				// The boundary of the cap projects to y, and the cap contains the points above it.
+				p := gm.UnprojectPoint(r2.Point{X: x, Y: y})
+				if d := p.Distance(c.Center()) - c.Radius(); math.Abs(float64(d)) > 1e-12 {
+					t.Errorf("ParallelCurve(%v, %v): %v is %v from the boundary", gm, y, p, d)
+				}
+				if above, below := gm.UnprojectPoint(r2.Point{X: x, Y: y + 0.1}), gm.UnprojectPoint(r2.Point{X: x, Y: y - 0.1}); !c.ContainsPoint(above) || c.ContainsPoint(below) {
+					t.Errorf("ParallelCurve(%v, %v): cap contains %v: %v, %v: %v", gm, y, above, c.ContainsPoint(above), below, c.ContainsPoint(below))
+				}
+			}
+		}
+	}
+}
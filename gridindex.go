@@ -0,0 +1,151 @@
+package gm
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+)
+
+// A GridIndex is a spatial index of features by their bounding rectangles in the plane, for finding the features
+// in a window of the map during interactive rendering. It divides the strip of the map with |y| <= maxY,
+// before any output transformation, into a uniform grid of cells, and records each feature in the cells
+// that its bounds overlap. Bounds and windows are translated by whole periods into the map, and those that
+// straddle the seam are split into a piece at each edge, so that a feature drawn across the seam is found
+// from either side. Bounds and windows that extend beyond the strip are clamped to its top or bottom row of cells.
+// If the output transformation rotates or shears the map, each rectangle is enlarged to the rectangle aligned
+// with the map that contains it, so Search may also report features that are near the window.
+//
+// A GridIndex is not safe for concurrent use if any goroutine inserts features.
+type GridIndex struct {
+	gm         *GeneralizedMercator
+	cols, rows int
+	maxY       float64
+	entries    []gridEntry
+	cells      [][]int32 // the indexes of the entries overlapping each cell, in row-major order
+}
+
+// A gridEntry is a piece of the bounds of a feature, before the output transformation
+// with x coordinates measured from the middle of the map.
+type gridEntry struct {
+	id   int
+	rect r2.Rect
+}
+
+// NewGridIndex returns an empty GridIndex with cols columns and rows rows of cells covering the strip |y| <= maxY.
+// It panics if cols or rows is not positive or if maxY is not positive and finite.
+func (gm *GeneralizedMercator) NewGridIndex(cols, rows int, maxY float64) *GridIndex {
+	if cols <= 0 || rows <= 0 || !(maxY > 0) || math.IsInf(maxY, 1) {
+		panic("gm: invalid grid")
+	}
+	return &GridIndex{gm: gm, cols: cols, rows: rows, maxY: maxY, cells: make([][]int32, cols*rows)}
+}
+
+// Insert adds the feature identified by id with the projected bounding rectangle bound, which may extend
+// beyond the edges of the map. A feature may be inserted more than once, such as for each of the pieces returned
+// by ProjectPolyline, and is reported once by Search if any of its bounds intersect the window.
+func (ix *GridIndex) Insert(id int, bound r2.Rect) {
+	for _, r := range ix.centered(bound) {
+		n := int32(len(ix.entries))
+		ix.entries = append(ix.entries, gridEntry{id, r})
+		c0, r0, c1, r1 := ix.span(r)
+		for row := r0; row <= r1; row++ {
+			for col := c0; col <= c1; col++ {
+				ix.cells[row*ix.cols+col] = append(ix.cells[row*ix.cols+col], n)
+			}
+		}
+	}
+}
+
+// InsertPieces adds the feature identified by id with the bounding rectangle of each of pieces,
+// such as the pieces returned by ProjectPolyline or the rings returned by ProjectPolygon.
+func (ix *GridIndex) InsertPieces(id int, pieces [][]r2.Point) {
+	for _, piece := range pieces {
+		if len(piece) > 0 {
+			ix.Insert(id, r2.RectFromPoints(piece...))
+		}
+	}
+}
+
+// Search calls f with the id of each feature whose bounds intersect window, which may extend beyond the edges
+// of the map, in order of insertion. Each feature is reported once. If f returns false, Search stops.
+func (ix *GridIndex) Search(window r2.Rect, f func(id int) bool) {
+	seen := make(map[int32]bool)
+	var found []int32
+	for _, w := range ix.centered(window) {
+		c0, r0, c1, r1 := ix.span(w)
+		for row := r0; row <= r1; row++ {
+			for col := c0; col <= c1; col++ {
+				for _, n := range ix.cells[row*ix.cols+col] {
+					if !seen[n] && ix.entries[n].rect.Intersects(w) {
+						seen[n] = true
+						found = append(found, n)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(found, func(a, b int) bool { return found[a] < found[b] })
+	reported := make(map[int]bool)
+	for _, n := range found {
+		id := ix.entries[n].id
+		if reported[id] {
+			continue
+		}
+		reported[id] = true
+		if !f(id) {
+			return
+		}
+	}
+}
+
+// centered returns the rectangles before the output transformation, with x coordinates measured from the middle
+// of the map and translated into the map, whose union bounds the projected rectangle r.
+func (ix *GridIndex) centered(r r2.Rect) []r2.Rect {
+	if r.IsEmpty() {
+		return nil
+	}
+	gm := ix.gm
+	var b r2.Rect
+	for n, v := range r.Vertices() {
+		p := gm.out.invert(v)
+		p.X -= gm.cut * gm.radius * gm.k0
+		if n == 0 {
+			b = r2.RectFromPoints(p)
+		} else {
+			b = b.AddPoint(p)
+		}
+	}
+	e := math.Pi * gm.radius * gm.k0
+	if !(b.X.Length() < 2*e) {
+		b.X.Lo, b.X.Hi = -e, e
+		return []r2.Rect{b}
+	}
+	shift := 2 * e * math.Floor((b.X.Lo+e)/(2*e))
+	b.X.Lo, b.X.Hi = b.X.Lo-shift, b.X.Hi-shift
+	if b.X.Hi <= e {
+		return []r2.Rect{b}
+	}
+	left := b
+	left.X.Lo, left.X.Hi = -e, b.X.Hi-2*e
+	b.X.Hi = e
+	return []r2.Rect{b, left}
+}
+
+// span returns the first and last columns and rows of the cells that r, which lies in the map, overlaps.
+func (ix *GridIndex) span(r r2.Rect) (c0, r0, c1, r1 int) {
+	e := math.Pi * ix.gm.radius * ix.gm.k0
+	cell := func(v, lo, size float64, n int) int {
+		k := math.Floor((v - lo) / size)
+		switch {
+		case !(k >= 0):
+			return 0
+		case k >= float64(n):
+			return n - 1
+		}
+		return int(k)
+	}
+	w, h := 2*e/float64(ix.cols), 2*ix.maxY/float64(ix.rows)
+	return cell(r.X.Lo, -e, w, ix.cols), cell(r.Y.Lo, -ix.maxY, h, ix.rows),
+		cell(r.X.Hi, -e, w, ix.cols), cell(r.Y.Hi, -ix.maxY, h, ix.rows)
+}
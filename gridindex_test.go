@@ -0,0 +1,81 @@
+package gm
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s2"
+)
+
+func TestGridIndex(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, gm := range []*GeneralizedMercator{
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+		New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150), WithSeam(2), WithAffine(0, -2, 2, 0, 5, 1)),
+	} {
+		ix := gm.NewGridIndex(16, 8, 3)
+		// wrapped reports whether a and b intersect after translating b by some whole number of periods.
+		wrapped := func(a, b r2.Rect) bool {
+			lo, hi := gm.shifts(a, b)
+			for k := lo; k <= hi; k++ {
+				if a.Intersects(gm.shifted(b, k)) {
+					return true
+				}
+			}
+			return false
+		}
+		var bounds []r2.Rect
+		for id := 0; id < 200; id++ {
+			ll := s2.LatLngFromDegrees(170*(2*rnd.Float64()-1), 360*rnd.Float64())
+			p := gm.Project(ll)
+			b := r2.RectFromCenterSize(p, r2.Point{X: rnd.Float64(), Y: rnd.Float64()})
+			bounds = append(bounds, b)
+			ix.Insert(id, b)
+		}
+		for n := 0; n < 100; n++ {
+			w := r2.RectFromCenterSize(gm.Project(s2.LatLngFromDegrees(60*(2*rnd.Float64()-1), 360*rnd.Float64())), r2.Point{X: 2 * rnd.Float64(), Y: 2 * rnd.Float64()})
+			got := make(map[int]bool)
+			ix.Search(w, func(id int) bool {
+				if got[id] {
+					t.Errorf("Search(%v): reported %d twice", w, id)
+				}
+				got[id] = true
+				return true
+			})
+			for id, b := range bounds {
+				// The index may also report features near the window, but it must report every feature in it.
+				if wrapped(w, b) && !got[id] {
+					t.Errorf("Search(%v): missing %d with bounds %v", w, id, b)
+				}
+			}
+		}
+	}
+
+	// A polyline across the seam is found from either edge of the map.
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	ix := gm.NewGridIndex(8, 4, 3)
+	ix.InsertPieces(7, gm.ProjectPolyline(polyline([2]float64{0, 170}, [2]float64{0, -170})))
+	for _, test := range []struct {
+		w    r2.Rect
+		want int
+	}{
+		{rect(3, -0.1, 3.1, 0.1), 1},
+		{rect(-3.1, -0.1, -3, 0.1), 1},
+		{rect(3.2, -0.1, 3.3, 0.1), 1},
+		{rect(0, -0.1, 1, 0.1), 0},
+		{rect(-4, -0.1, 4, 0.1), 1},
+	} {
+		var n int
+		ix.Search(test.w, func(id int) bool {
+			if id != 7 {
+				t.Errorf("Search(%v): got id %d, want 7", test.w, id)
+			}
+			n++
+			return true
+		})
+		if n != test.want {
+			t.Errorf("Search(%v): got %d results, want %d", test.w, n, test.want)
+		}
+	}
+}
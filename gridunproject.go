@@ -0,0 +1,141 @@
+package gm
+
+import (
+	"errors"
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// maxGridNodes is the greatest number of nodes in the grid of a GridUnprojector.
+const maxGridNodes = 1 << 20
+
+// A GridUnprojector approximates the unprojection of the points in a rectangle of the plane by bilinear interpolation
+// between the points on the reference sphere to which the nodes of a regular grid unproject, for applications such as
+// interactive panning that need many fast inverse lookups and can tolerate a small error.
+type GridUnprojector struct {
+	gm         *GeneralizedMercator
+	rect       r2.Rect
+	cols, rows int
+	maxErr     s1.Angle
+
+	// nodes holds the unprojections of the nodes of the grid, row by row from the bottom of rect.
+	nodes []r3.Vector
+}
+
+// NewGridUnprojector returns a GridUnprojector for the points in rect whose results are within maxErr
+// of those of UnprojectPoint, measured as an angle on the reference sphere. The grid is refined until a bound
+// on the error of interpolation within each cell, derived from bounds on the second derivatives of the unprojection
+// over the cell, is at most maxErr. NewGridUnprojector returns an error if rect is empty or not finite, if maxErr is not
+// positive, or if the grid needed to achieve maxErr would have more than about a million nodes.
+func (gm *GeneralizedMercator) NewGridUnprojector(rect r2.Rect, maxErr s1.Angle) (*GridUnprojector, error) {
+	if rect.IsEmpty() || !isFinite(rect.Lo()) || !isFinite(rect.Hi()) {
+		return nil, errors.New("gm: invalid grid rectangle")
+	}
+	if !(maxErr > 0) {
+		return nil, errors.New("gm: invalid grid error bound")
+	}
+	// Begin with square cells whose size would achieve maxErr for unit second derivatives, as at the equator
+	// of the normal Mercator projection, for which bilinear interpolation is within h²/4 of the unprojected points.
+	h := gm.radius * gm.k0 * math.Sqrt(4*float64(maxErr))
+	g := &GridUnprojector{
+		gm:     gm,
+		rect:   rect,
+		cols:   int(math.Max(1, math.Ceil(rect.X.Length()/h))),
+		rows:   int(math.Max(1, math.Ceil(rect.Y.Length()/h))),
+		maxErr: maxErr,
+	}
+	for {
+		if (g.cols+1)*(g.rows+1) > maxGridNodes {
+			return nil, errors.New("gm: grid error bound requires too many nodes")
+		}
+		g.build()
+		if g.check() {
+			return g, nil
+		}
+		g.cols *= 2
+		g.rows *= 2
+	}
+}
+
+// build computes the nodes of g.
+func (g *GridUnprojector) build() {
+	g.nodes = make([]r3.Vector, 0, (g.cols+1)*(g.rows+1))
+	for r := 0; r <= g.rows; r++ {
+		for c := 0; c <= g.cols; c++ {
+			g.nodes = append(g.nodes, g.gm.UnprojectPoint(g.node(c, r)).Vector)
+		}
+	}
+}
+
+// node returns the projected point at the node in column c and row r of g.
+func (g *GridUnprojector) node(c, r int) r2.Point {
+	return r2.Point{
+		X: g.rect.X.Lo + g.rect.X.Length()*float64(c)/float64(g.cols),
+		Y: g.rect.Y.Lo + g.rect.Y.Length()*float64(r)/float64(g.rows),
+	}
+}
+
+// check reports whether the bound on the interpolation error of g within each cell is at most g.maxErr.
+func (g *GridUnprojector) check() bool {
+	for r := 0; r < g.rows; r++ {
+		for c := 0; c < g.cols; c++ {
+			if g.cellError(c, r) > g.maxErr {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cellError returns an upper bound on the interpolation error of g within the cell whose lower left node
+// is in column c and row r.
+func (g *GridUnprojector) cellError(c, r int) s1.Angle {
+	// Bilinear interpolation is linear interpolation along the rows followed by linear interpolation between them,
+	// so the interpolated vector V differs from the unprojection F by at most (|F_uu| + |F_vv|)/8, where u and v
+	// are the sides of the cell. Since F is a unit vector, the sine of the angle between them is at most |F - V|.
+	p := g.gm.unscaled(g.node(c, r))
+	u, v := g.gm.unscaled(g.node(c+1, r)).Sub(p), g.gm.unscaled(g.node(c, r+1)).Sub(p)
+	lo, hi := p.Y, p.Y
+	for _, q := range []r2.Point{p.Add(u), p.Add(v), p.Add(u).Add(v)} {
+		lo, hi = math.Min(lo, q.Y), math.Max(hi, q.Y)
+	}
+	y := minAbs(lo, hi)
+	e := (g.gm.secondDerivative(u, y) + g.gm.secondDerivative(v, y)) / 8
+	if !(e < 1) {
+		return math.Pi
+	}
+	return s1.Angle(math.Asin(e))
+}
+
+// Rect returns the rectangle of the plane within which g approximates the unprojection.
+func (g *GridUnprojector) Rect() r2.Rect { return g.rect }
+
+// MaxError returns the bound on the error of g with which it was constructed.
+func (g *GridUnprojector) MaxError() s1.Angle { return g.maxErr }
+
+// Size returns the number of columns and rows of cells in the grid of g.
+func (g *GridUnprojector) Size() (cols, rows int) { return g.cols, g.rows }
+
+// UnprojectPoint returns the approximate point on the reference sphere to which p unprojects.
+// Points outside the rectangle of g are extrapolated from the nearest cell, without the bound on the error.
+func (g *GridUnprojector) UnprojectPoint(p r2.Point) s2.Point {
+	fx := (p.X - g.rect.X.Lo) / g.rect.X.Length() * float64(g.cols)
+	fy := (p.Y - g.rect.Y.Lo) / g.rect.Y.Length() * float64(g.rows)
+	c := int(math.Max(0, math.Min(math.Floor(fx), float64(g.cols-1))))
+	r := int(math.Max(0, math.Min(math.Floor(fy), float64(g.rows-1))))
+	tx, ty := fx-float64(c), fy-float64(r)
+	n := r*(g.cols+1) + c
+	a, b := g.nodes[n], g.nodes[n+1]
+	d, e := g.nodes[n+g.cols+1], g.nodes[n+g.cols+2]
+	v := a.Mul((1 - tx) * (1 - ty)).Add(b.Mul(tx * (1 - ty))).Add(d.Mul((1 - tx) * ty)).Add(e.Mul(tx * ty))
+	return s2.Point{v.Normalize()}
+}
+
+// Unproject returns the approximate location on the reference sphere to which p unprojects.
+func (g *GridUnprojector) Unproject(p r2.Point) s2.LatLng {
+	return g.gm.latLngFromPoint(g.UnprojectPoint(p))
+}
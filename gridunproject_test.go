@@ -0,0 +1,95 @@
+package gm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r1"
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestGridUnprojector(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{
+			New(pos, neg),
+			New(pos, neg, WithRadius(2), WithCentralLongitude(0.5), WithGeodeticLatitude(WGS84Flattening)),
+			New(pos, neg, WithAffine(0, -1, 1, 0, 2, 3)),
+		} {
+			for _, rect := range []r2.Rect{
+				gm.Bounds(1),
+				r2.RectFromCenterSize(gm.Project(s2.LatLngFromDegrees(10, 20)), r2.Point{X: 0.5, Y: 0.5}),
+			} {
+				const maxErr = s1.Angle(1e-4)
+				g, err := gm.NewGridUnprojector(rect, maxErr)
+				if err != nil {
+					t.Fatalf("NewGridUnprojector(%v, %v, %v): %v", gm, rect, maxErr, err)
+				}
+				if g.Rect() != rect || g.MaxError() != maxErr {
+					t.Errorf("NewGridUnprojector(%v, %v, %v): got rect %v and error %v", gm, rect, maxErr, g.Rect(), g.MaxError())
+				}
+				for n := 0; n < 1000; n++ {
+					p := r2.Point{
+						X: rect.X.Lo + rnd.Float64()*rect.X.Length(),
+						Y: rect.Y.Lo + rnd.Float64()*rect.Y.Length(),
+					}
+					want := gm.UnprojectPoint(p)
+					if d := g.UnprojectPoint(p).Distance(want); d > maxErr {
+						t.Errorf("%v: grid unprojection of %v is %v from %v, want at most %v", gm, p, d, want, maxErr)
+					}
+					if ll, want := g.Unproject(p), gm.Unproject(p); s2.PointFromLatLng(ll).Distance(s2.PointFromLatLng(want)) > maxErr {
+						t.Errorf("%v: grid unprojection of %v: got %v, want %v", gm, p, ll, want)
+					}
+				}
+				// Sample the interiors of cells densely, where the error of interpolation is greatest.
+				cols, rows := g.Size()
+				for n := 0; n < 100; n++ {
+					c, r := rnd.Intn(cols), rnd.Intn(rows)
+					for k := 0; k < 100; k++ {
+						p := r2.Point{
+							X: rect.X.Lo + (float64(c)+rnd.Float64())*rect.X.Length()/float64(cols),
+							Y: rect.Y.Lo + (float64(r)+rnd.Float64())*rect.Y.Length()/float64(rows),
+						}
+						if d := g.UnprojectPoint(p).Distance(gm.UnprojectPoint(p)); d > maxErr {
+							t.Errorf("%v: grid unprojection of %v in cell (%d, %d) is %v from the unprojection, want at most %v", gm, p, c, r, d, maxErr)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestGridUnprojectorInvalid(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0))
+	for _, test := range []struct {
+		rect   r2.Rect
+		maxErr s1.Angle
+	}{
+		{r2.EmptyRect(), 1e-3},
+		{r2.Rect{X: r1.Interval{Lo: 0, Hi: 1}, Y: r1.Interval{Lo: 0, Hi: math.Inf(1)}}, 1e-3},
+		{gm.Bounds(1), 0},
+		{gm.Bounds(1), -1},
+		{gm.Bounds(1), 1e-15},
+	} {
+		if _, err := gm.NewGridUnprojector(test.rect, test.maxErr); err == nil {
+			t.Errorf("NewGridUnprojector(%v, %v): got nil error", test.rect, test.maxErr)
+		}
+	}
+}
+
+func BenchmarkGridUnprojector(b *testing.B) {
+	gm := New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0))
+	g, err := gm.NewGridUnprojector(gm.Bounds(1), 1e-5)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.UnprojectPoint(r2.Point{1, 1})
+	}
+}
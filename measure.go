@@ -0,0 +1,154 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// RingsArea returns the area on the reference sphere of the region bounded by rings in the plane, such as rings
+// returned by ProjectPolygon or drawn by a user on the map, in square units of the radius of the reference sphere:
+// steradians for a projection with unit radius, or square kilometers for a radius in kilometers. Rings that are
+// counterclockwise in the plane count positively and clockwise rings negatively, so that holes are subtracted.
+// Each edge of a ring is a straight segment in the plane, whose preimage on the sphere is approximated to within
+// tolerance, which must be positive; the result therefore accounts for the distortion of area by the projection.
+// Rings may extend beyond the edges of the map, but their points must have finite coordinates.
+func (gm *GeneralizedMercator) RingsArea(rings [][]r2.Point, tolerance s1.Angle) float64 {
+	var area float64
+	for _, ring := range rings {
+		l, sign := gm.unprojectRing(ring, tolerance)
+		if l == nil {
+			continue
+		}
+		if sign > 0 {
+			area += l.Area()
+		} else {
+			area -= 4*math.Pi - l.Area()
+		}
+	}
+	return area * gm.radius * gm.radius
+}
+
+// RingsCentroid returns the centroid on the reference sphere of the region bounded by rings in the plane,
+// interpreted as by RingsArea: the normalized mean of the points of the region, weighted by true area
+// rather than by projected area. The centroid is undefined if the mean is zero, as for a band around the map
+// that is symmetric about the generalized equator, in which case RingsCentroid returns the zero LatLng.
+func (gm *GeneralizedMercator) RingsCentroid(rings [][]r2.Point, tolerance s1.Angle) s2.LatLng {
+	var c s2.Point
+	for _, ring := range rings {
+		// The centroid of the complement of a region is the negation of its centroid, since the centroid of
+		// the whole sphere is zero, so each loop contributes its own centroid whatever its orientation.
+		if l, _ := gm.unprojectRing(ring, tolerance); l != nil {
+			c = s2.Point{c.Add(l.Centroid().Vector)}
+		}
+	}
+	return gm.centroidLatLng(c)
+}
+
+// LineCentroid returns the centroid on the reference sphere of the polyline with vertices pts in the plane:
+// the normalized mean of the points of the preimage of its straight edges, approximated to within tolerance,
+// weighted by true length. The centroid is undefined if the mean is zero, in which case LineCentroid returns
+// the zero LatLng. Points must have finite coordinates.
+func (gm *GeneralizedMercator) LineCentroid(pts []r2.Point, tolerance s1.Angle) s2.LatLng {
+	var c s2.Point
+	for n := 1; n < len(pts); n++ {
+		line := gm.UnprojectSegment(pts[n-1], pts[n], tolerance)
+		c = s2.Point{c.Add(line.Centroid().Vector)}
+	}
+	return gm.centroidLatLng(c)
+}
+
+// centroidLatLng returns the location in the direction of c, or the zero LatLng if c is zero.
+func (gm *GeneralizedMercator) centroidLatLng(c s2.Point) s2.LatLng {
+	if c.Norm2() == 0 {
+		return s2.LatLng{}
+	}
+	return gm.latLngFromPoint(s2.Point{c.Normalize()})
+}
+
+// unprojectRing returns the loop on the reference sphere that approximates the preimage of ring to within tolerance,
+// and the sign of the orientation of ring in the plane before any output transformation: 1 if it is counterclockwise,
+// so that the loop encloses the region bounded by ring, and -1 otherwise, so that the loop encloses its complement.
+// It returns nil if ring bounds no area.
+func (gm *GeneralizedMercator) unprojectRing(ring []r2.Point, tolerance s1.Angle) (*s2.Loop, float64) {
+	var a float64
+	for n, p := range ring {
+		a += p.Cross(ring[(n+1)%len(ring)])
+	}
+	if gm.out.a*gm.out.d-gm.out.b*gm.out.c < 0 {
+		a = -a
+	}
+	if a == 0 {
+		return nil, 0
+	}
+	var pts []s2.Point
+	for n, p := range ring {
+		line := gm.UnprojectSegment(p, ring[(n+1)%len(ring)], tolerance)
+		for _, v := range line[:len(line)-1] {
+			if len(pts) == 0 || !approxEqual(v.Vector, pts[len(pts)-1].Vector) {
+				pts = append(pts, v)
+			}
+		}
+	}
+	if len(pts) > 1 && approxEqual(pts[0].Vector, pts[len(pts)-1].Vector) {
+		pts = pts[:len(pts)-1]
+	}
+	if len(pts) < 3 {
+		return nil, 0
+	}
+	return s2.LoopFromPoints(pts), math.Copysign(1, a)
+}
+
+// A DistortionReport describes the lengths of the edges of a polyline on the reference sphere and in the plane,
+// as produced by LengthDistortion.
+type DistortionReport struct {
+	// Length is the length of the polyline on the reference sphere, in the units of its radius.
+	Length float64
+
+	// Edges describes each edge of the polyline in order.
+	Edges []EdgeDistortion
+}
+
+// An EdgeDistortion describes the length of an edge of a polyline on the reference sphere and in the plane.
+type EdgeDistortion struct {
+	// Length is the length of the geodesic edge on the reference sphere, in the units of its radius,
+	// and Projected is the length of its projection, in projected units, including the pieces on either side
+	// of the seam if the edge crosses it. Projected is +Inf if the edge passes through a pole.
+	Length, Projected float64
+
+	// Ratio is Projected divided by Length, or NaN if the edge has zero length,
+	// and Exceeds reports whether it is greater than the threshold given to LengthDistortion.
+	Ratio   float64
+	Exceeds bool
+}
+
+// LengthDistortion reports the length of line on the reference sphere and the true and projected lengths
+// of each of its edges, flagging the edges whose ratio of projected to true length exceeds threshold.
+// The projected length of each edge is measured along its projected curve, approximated to within maxErr,
+// which must be positive, measured as an angle on the reference sphere. For example, if the poles are antipodes
+// and there is no output transformation, the ratio along a short edge at generalized latitude ψ is about k0 sec ψ.
+func (gm *GeneralizedMercator) LengthDistortion(line s2.Polyline, maxErr s1.Angle, threshold float64) DistortionReport {
+	var r DistortionReport
+	for n := 1; n < len(line); n++ {
+		a, b := line[n-1], line[n]
+		e := EdgeDistortion{Length: float64(a.Distance(b)) * gm.radius}
+		for _, piece := range gm.ProjectPolyline(append(gm.densifyEdge(nil, a, b, maxErr), b)) {
+			for k := 1; k < len(piece); k++ {
+				e.Projected += piece[k].Sub(piece[k-1]).Norm()
+			}
+		}
+		if math.IsNaN(e.Projected) {
+			e.Projected = math.Inf(1)
+		}
+		e.Ratio = math.NaN()
+		if e.Length > 0 {
+			e.Ratio = e.Projected / e.Length
+		}
+		e.Exceeds = e.Ratio > threshold
+		r.Length += e.Length
+		r.Edges = append(r.Edges, e)
+	}
+	return r
+}
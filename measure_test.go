@@ -0,0 +1,149 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// llNear reports whether a and b are within 1e-7 radians of each other on the sphere.
+func llNear(a, b s2.LatLng) bool {
+	return s2.PointFromLatLng(a).Angle(s2.PointFromLatLng(b).Vector) <= 1e-7
+}
+
+func TestRingsArea(t *testing.T) {
+	const tolerance = 1e-7
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	square := []r2.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}}
+	reversed := []r2.Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}}
+	for _, test := range []struct {
+		gm    *GeneralizedMercator
+		rings [][]r2.Point
+		want  float64
+	}{
+		// The area of the unit sphere per unit of projected area is sech²(y) in the standard Mercator projection.
+		{mercator, [][]r2.Point{square}, math.Tanh(1)},
+		{mercator, [][]r2.Point{reversed}, -math.Tanh(1)},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithRadius(6371)), [][]r2.Point{{{X: 0, Y: 0}, {X: 6371, Y: 0}, {X: 6371, Y: 6371}, {X: 0, Y: 6371}}}, math.Tanh(1) * 6371 * 6371},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithAffine(-1, 0, 0, 1, 0, 0)), [][]r2.Point{reversed}, math.Tanh(1)},
+		// A square across the seam.
+		{mercator, [][]r2.Point{{{X: 2.5, Y: -1}, {X: 3.5, Y: -1}, {X: 3.5, Y: 0}, {X: 2.5, Y: 0}}}, math.Tanh(1)},
+		// A square with a hole.
+		{mercator, [][]r2.Point{square, {{X: 0.25, Y: 0}, {X: 0.25, Y: 0.5}, {X: 0.75, Y: 0.5}, {X: 0.75, Y: 0}}}, math.Tanh(1) - 0.5*math.Tanh(0.5)},
+		// The strip |y| <= 2 spans the whole map.
+		{mercator, [][]r2.Point{mercator.strip(2)}, 4 * pi * math.Tanh(2)},
+	} {
+		if got := test.gm.RingsArea(test.rings, tolerance); math.Abs(got-test.want) > 1e-5*math.Abs(test.want) {
+			t.Errorf("RingsArea(%v, %v): got %v, want %v", test.gm, test.rings, got, test.want)
+		}
+	}
+
+	// The area of a projected polygon with short edges is nearly that of the polygon.
+	for _, gm := range []*GeneralizedMercator{mercator, New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150))} {
+		for _, p := range []*s2.Polygon{
+			s2.PolygonFromLoops([]*s2.Loop{rectLoop(-10, 170, 10, 190)}),
+			s2.PolygonFromLoops([]*s2.Loop{rectLoop(-20, -40, 30, 60), rectLoop(0, 0, 10, 10)}),
+		} {
+			rings := gm.ProjectPolygon(p, 10)
+			if got, want := gm.RingsArea(rings, tolerance), p.Area(); math.Abs(got-want) > 1e-3*want {
+				t.Errorf("RingsArea(%v, %v): got %v, want %v", gm, p, got, want)
+			}
+		}
+	}
+}
+
+func TestRingsCentroid(t *testing.T) {
+	const tolerance = 1e-8
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	// In the standard Mercator projection, the square [0, 1]² is the region of latitudes from 0 to gd(1)
+	// and longitudes from 0 to 1, whose centroid has latitude atan2(sin²φ/2, (φ/2 + sin(2φ)/4) 2 sin(1/2)).
+	phi := math.Atan(math.Sinh(1))
+	lat := math.Atan2(math.Pow(math.Sin(phi), 2)/2, (phi/2+math.Sin(2*phi)/4)*2*math.Sin(0.5))
+	square := []r2.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}}
+	for _, test := range []struct {
+		gm    *GeneralizedMercator
+		rings [][]r2.Point
+		want  s2.LatLng
+	}{
+		{mercator, [][]r2.Point{square}, s2.LatLng{Lat: s1.Angle(lat), Lng: 0.5}},
+		{mercator, [][]r2.Point{{{X: 2.5, Y: -1}, {X: 3.5, Y: -1}, {X: 3.5, Y: 1}, {X: 2.5, Y: 1}}}, s2.LatLng{Lng: 3}},
+		// A hole on one side moves the centroid to the other.
+		{mercator, [][]r2.Point{{{X: 0, Y: -1}, {X: 1, Y: -1}, {X: 1, Y: 1}, {X: 0, Y: 1}}, {{X: 0.25, Y: -0.5}, {X: 0.25, Y: 0.5}, {X: 0.5, Y: 0.5}, {X: 0.5, Y: -0.5}}}, s2.LatLng{}},
+	} {
+		got := test.gm.RingsCentroid(test.rings, tolerance)
+		if len(test.rings) > 1 {
+			if got.Lat.Abs() > 1e-9 || got.Lng <= 0.5 {
+				t.Errorf("RingsCentroid(%v, %v): got %v, want latitude 0 and longitude greater than 0.5", test.gm, test.rings, got)
+			}
+			continue
+		}
+		if !llNear(got, test.want) {
+			t.Errorf("RingsCentroid(%v, %v): got %v, want %v", test.gm, test.rings, got, test.want)
+		}
+	}
+
+	// The centroid of a projected polygon is that of the polygon.
+	gm := New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150))
+	p := s2.PolygonFromLoops([]*s2.Loop{rectLoop(-20, -40, 30, 60), rectLoop(0, 0, 10, 10)})
+	want := s2.LatLngFromPoint(s2.Point{p.Centroid().Normalize()})
+	if got := gm.RingsCentroid(gm.ProjectPolygon(p, 10), tolerance); float64(s2.PointFromLatLng(got).Angle(s2.PointFromLatLng(want).Vector)) > 1e-3 {
+		t.Errorf("RingsCentroid(%v, %v): got %v, want %v", gm, p, got, want)
+	}
+}
+
+func TestLineCentroid(t *testing.T) {
+	const tolerance = 1e-8
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, test := range []struct {
+		pts  []r2.Point
+		want s2.LatLng
+	}{
+		{[]r2.Point{{X: 0, Y: 0}, {X: 1, Y: 0}}, s2.LatLng{Lng: 0.5}},
+		{[]r2.Point{{X: 3, Y: 0}, {X: 3.5, Y: 0}}, s2.LatLng{Lng: 3.25}},
+		// The centroid of a meridian segment is at its midpoint on the sphere, not in the plane.
+		{[]r2.Point{{X: 0, Y: 0}, {X: 0, Y: 1}}, s2.LatLng{Lat: s1.Angle(math.Atan(math.Sinh(1)) / 2)}},
+		{[]r2.Point{{X: 0, Y: -1}, {X: 0, Y: 0}, {X: 0, Y: 1}}, s2.LatLng{}},
+		{[]r2.Point{{X: 0, Y: 0}}, s2.LatLng{}},
+	} {
+		if got := mercator.LineCentroid(test.pts, tolerance); !llNear(got, test.want) {
+			t.Errorf("LineCentroid(%v, %v): got %v, want %v", mercator, test.pts, got, test.want)
+		}
+	}
+}
+
+func TestLengthDistortion(t *testing.T) {
+	const maxErr = 1e-9
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithRadius(6371))
+	line := polyline([2]float64{0, 0}, [2]float64{0, 10}, [2]float64{60, 10}, [2]float64{60, 10}, [2]float64{0, 170}, [2]float64{0, -170}, [2]float64{90, 0})
+	r := gm.LengthDistortion(line, maxErr, 1.1)
+	deg := pi / 180
+	for n, want := range []EdgeDistortion{
+		{Length: 10 * deg * 6371, Projected: 10 * deg * 6371, Ratio: 1},
+		{Length: 60 * deg * 6371, Projected: math.Log(math.Tan(pi/4+pi/6)) * 6371, Ratio: math.Log(math.Tan(pi/4+pi/6)) / (pi / 3), Exceeds: true},
+		{Ratio: math.NaN()},
+		{Length: float64(line[3].Distance(line[4])) * 6371, Exceeds: true},
+		{Length: 20 * deg * 6371, Projected: 20 * deg * 6371, Ratio: 1},
+		{Length: 90 * deg * 6371, Projected: math.Inf(1), Ratio: math.Inf(1), Exceeds: true},
+	} {
+		got := r.Edges[n]
+		if n == 3 {
+			// The projection of the edge bulges poleward, so it is longer than its true length by an unknown ratio.
+			if math.Abs(got.Length-want.Length) > 1e-9*want.Length || !(got.Ratio > 1.1) || got.Exceeds != want.Exceeds {
+				t.Errorf("LengthDistortion(%v, %v): edge %d got %+v, want length %v and a large ratio", gm, line, n, got, want.Length)
+			}
+			continue
+		}
+		near := func(a, b float64) bool {
+			return a == b || math.Abs(a-b) <= 1e-6*math.Abs(b) || math.IsNaN(a) && math.IsNaN(b)
+		}
+		if !near(got.Length, want.Length) || !near(got.Projected, want.Projected) || !near(got.Ratio, want.Ratio) || got.Exceeds != want.Exceeds {
+			t.Errorf("LengthDistortion(%v, %v): edge %d got %+v, want %+v", gm, line, n, got, want)
+		}
+	}
+	if want := 180 * deg * 6371; len(r.Edges) != len(line)-1 || math.Abs(r.Length-want-r.Edges[3].Length) > 1e-6*want {
+		t.Errorf("LengthDistortion(%v, %v): got length %v with %d edges", gm, line, r.Length, len(r.Edges))
+	}
+}
@@ -0,0 +1,69 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/s2"
+)
+
+// Morph returns the projection at fraction t of the way from a to b, for animating a transition between them.
+// Its poles are interpolated along great circles from the poles of a to the corresponding poles of b, and
+// the location on its central line at generalized latitude 0 moves along the great circle between those of a and b,
+// so that projected coordinates vary continuously with t. The radius, scale factor, flattening, output transformation,
+// and truncation latitude are interpolated linearly, and the seam along the shorter arc between the seams of a and b.
+// The seam side, and the truncation latitude if only one of a and b is truncated, change halfway.
+// Morph returns a copy of a if t is 0 and of b if t is 1, and an error if the interpolated projection is invalid,
+// such as if its poles coincide.
+func Morph(a, b *GeneralizedMercator, t float64) (*GeneralizedMercator, error) {
+	switch t {
+	case 0:
+		g := *a
+		return &g, nil
+	case 1:
+		g := *b
+		return &g, nil
+	}
+	opts := make([]Option, 0, len(params))
+	for _, p := range params {
+		va, _ := p.value(a)
+		vb, _ := p.value(b)
+		v := va + t*(vb-va)
+		switch p.key {
+		case "x0":
+			// The central longitude is set from the middle location below.
+			continue
+		case "s":
+			v = va + t*math.Remainder(vb-va, 2*math.Pi)
+		case "ss", "llp", "xp":
+			v = vb
+			if t < 0.5 {
+				v = va
+			}
+		case "t":
+			if va == 0 || vb == 0 {
+				v = vb
+				if t < 0.5 {
+					v = va
+				}
+			}
+		}
+		opts = append(opts, p.option(v))
+	}
+	pos := s2.Interpolate(t, s2.Point{a.pos}, s2.Point{b.pos})
+	neg := s2.Interpolate(t, s2.Point{a.neg}, s2.Point{b.neg})
+	g, err := newGM(pos.Vector, neg.Vector, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Since the i axis of each projection is fixed only by convention, the central longitudes of a and b
+	// are not comparable. Interpolating the locations on their central lines avoids a jump in the
+	// projected coordinates where the convention changes, unless the location passes through a pole.
+	mid := s2.Interpolate(t, s2.Point{a.fromGeneralized(a.x0, 0)}, s2.Point{b.fromGeneralized(b.x0, 0)})
+	if g.near(mid.Vector, g.pos) || g.near(mid.Vector, g.neg) {
+		g.x0 = a.x0 + t*math.Remainder(b.x0-a.x0, 2*math.Pi)
+	} else {
+		g.x0, _ = g.generalized(mid.Vector)
+	}
+	return g, nil
+}
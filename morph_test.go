@@ -0,0 +1,58 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestMorph(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, test := range []struct {
+		a, b *GeneralizedMercator
+	}{
+		{mercator, New(s2.LatLngFromDegrees(45, 30), s2.LatLngFromDegrees(-45, -150))},
+		{mercator, New(s2.LatLngFromDegrees(0, 60), s2.LatLngFromDegrees(0, -120), WithRadius(2), WithCentralLongitude(1))},
+		{New(s2.LatLngFromDegrees(60, 10), s2.LatLngFromDegrees(20, 40), WithSeam(3)), New(s2.LatLngFromDegrees(80, -20), s2.LatLngFromDegrees(-10, 70), WithSeam(-3), WithFalseOrigin(1, 2))},
+	} {
+		for _, f := range []float64{0, 1} {
+			want := test.a
+			if f == 1 {
+				want = test.b
+			}
+			if got, err := Morph(test.a, test.b, f); err != nil || !got.Equal(want) {
+				t.Errorf("Morph(%v, %v, %v): got %v, %v, want %v", test.a, test.b, f, got, err, want)
+			}
+		}
+
+		// Small steps in t move projected locations by small amounts, apart from wrapping across the seam.
+		const steps = 200
+		lls := []s2.LatLng{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(20, -40), s2.LatLngFromDegrees(-30, 100)}
+		prev := test.a
+		for n := 1; n <= steps; n++ {
+			f := float64(n) / steps
+			g, err := Morph(test.a, test.b, f)
+			if err != nil {
+				t.Errorf("Morph(%v, %v, %v): got error %v", test.a, test.b, f, err)
+				break
+			}
+			for _, ll := range lls {
+				p, q := prev.Project(ll), g.Project(ll)
+				period := 2 * math.Pi * g.radius * g.k0
+				dx := math.Remainder(q.X-p.X, period)
+				if math.Abs(dx) > 0.1*g.radius || math.Abs(q.Y-p.Y) > 0.1*g.radius {
+					t.Errorf("Morph(%v, %v, %v): %v moved from %v to %v", test.a, test.b, f, ll, p, q)
+				}
+			}
+			prev = g
+		}
+	}
+
+	// The poles cannot coincide.
+	a := New(s2.LatLngFromDegrees(10, 0), s2.LatLngFromDegrees(-10, 0))
+	b := New(s2.LatLngFromDegrees(-10, 0), s2.LatLngFromDegrees(10, 0))
+	if g, err := Morph(a, b, 0.5); err == nil {
+		t.Errorf("Morph(%v, %v, 0.5): got %v, want error", a, b, g)
+	}
+}
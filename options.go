@@ -0,0 +1,312 @@
+package gm
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// An Option configures a GeneralizedMercator constructed by New.
+type Option func(*GeneralizedMercator) error
+
+// WithRadius sets the radius of the reference sphere, so that projected coordinates are expressed
+// in the same units as r. For example, WithRadius(6378137) yields coordinates in meters on a sphere
+// with the Earth's equatorial radius. The default radius is 1.
+func WithRadius(r float64) Option {
+	return func(gm *GeneralizedMercator) error {
+		if !(r > 0) || math.IsInf(r, 1) {
+			return fmt.Errorf("gm: invalid radius %v", r)
+		}
+		gm.radius = r
+		return nil
+	}
+}
+
+// Radius returns the radius of the reference sphere.
+func (gm *GeneralizedMercator) Radius() float64 {
+	return gm.radius
+}
+
+// WithScaleFactor sets the scale factor k0 along the generalized equator, by which projected coordinates are scaled
+// in addition to the radius. A scale factor less than 1 reduces the scale along the generalized equator
+// so that the projection is true to scale along two lines on either side of it. The default scale factor is 1.
+func WithScaleFactor(k0 float64) Option {
+	return func(gm *GeneralizedMercator) error {
+		if !(k0 > 0) || math.IsInf(k0, 1) {
+			return fmt.Errorf("gm: invalid scale factor %v", k0)
+		}
+		gm.k0 = k0
+		return nil
+	}
+}
+
+// WithStandardCircle sets the scale factor so that the projection is true to scale along the small circles
+// of generalized latitude ±psi0, which must be in the interval [0, π/2). It is equivalent to WithScaleFactor(cos(psi0)).
+// If the poles are not antipodes, the scale along the standard circles varies with direction.
+func WithStandardCircle(psi0 s1.Angle) Option {
+	return func(gm *GeneralizedMercator) error {
+		if !(psi0 >= 0 && psi0 < math.Pi/2) {
+			return fmt.Errorf("gm: invalid standard circle latitude %v", psi0)
+		}
+		gm.k0 = math.Cos(float64(psi0))
+		return nil
+	}
+}
+
+// ScaleFactor returns the scale factor k0 along the generalized equator.
+func (gm *GeneralizedMercator) ScaleFactor() float64 {
+	return gm.k0
+}
+
+// WithFalseOrigin adds easting and northing to the projected x and y coordinates, respectively,
+// so that the origin of the projection has coordinates (easting, northing). It preserves the linear part
+// of any transformation set by WithAffine.
+func WithFalseOrigin(easting, northing float64) Option {
+	return func(gm *GeneralizedMercator) error {
+		gm.out.e, gm.out.f = easting, northing
+		return nil
+	}
+}
+
+// WithAffine applies an affine transformation to the projected coordinates, mapping (x, y) to
+// (a*x + b*y + e, c*x + d*y + f). The transformation follows scaling by the radius and scale factor,
+// and must be finite and invertible. It overrides WithFalseOrigin.
+// AreaScale and ScaleFactors describe the projection before the transformation.
+func WithAffine(a, b, c, d, e, f float64) Option {
+	return func(gm *GeneralizedMercator) error {
+		gm.out = affine{a, b, c, d, e, f}
+		return nil
+	}
+}
+
+// WithCentralLongitude rotates the projection horizontally so that the central line x = 0
+// passes through the points that would otherwise project to x = lng, in radians.
+// Equivalently, each projected x coordinate is reduced by lng, modulo 2π, to the interval [-π, π].
+func WithCentralLongitude(lng float64) Option {
+	return func(gm *GeneralizedMercator) error {
+		if math.IsInf(lng, 0) || math.IsNaN(lng) {
+			return fmt.Errorf("gm: invalid central longitude %v", lng)
+		}
+		gm.x0 = math.Remainder(lng, 2*math.Pi)
+		gm.central = nil
+		return nil
+	}
+}
+
+// WithSeam places the seam, where projected x coordinates jump between the left and right edges of the map,
+// at projective longitude lng, in radians, measured from the central line. Projected x coordinates are reduced,
+// modulo 2π, to the interval [lng-2π, lng] before scaling, so the central line need not be in the middle of the map.
+// The default is π, which places the seam opposite the central line.
+func WithSeam(lng float64) Option {
+	return func(gm *GeneralizedMercator) error {
+		if math.IsInf(lng, 0) || math.IsNaN(lng) {
+			return fmt.Errorf("gm: invalid seam longitude %v", lng)
+		}
+		gm.cut = math.Remainder(lng-math.Pi, 2*math.Pi)
+		return nil
+	}
+}
+
+// WithSeamSide sets the edge of the map to which locations on the seam project. The default is SeamAsComputed.
+func WithSeamSide(side SeamSide) Option {
+	return func(gm *GeneralizedMercator) error {
+		if side < SeamAsComputed || side > SeamNegative {
+			return fmt.Errorf("gm: invalid seam side %d", side)
+		}
+		gm.side = side
+		return nil
+	}
+}
+
+// WithCentralPoint rotates the projection horizontally so that ll projects onto the central line x = 0.
+// It overrides WithCentralLongitude. ll must not be a pole of the projection.
+func WithCentralPoint(ll s2.LatLng) Option {
+	return func(gm *GeneralizedMercator) error {
+		if !ll.IsValid() {
+			return fmt.Errorf("gm: invalid central point %v", ll)
+		}
+		gm.central = &ll
+		return nil
+	}
+}
+
+// WithMinPoleSeparation causes construction to fail with ErrIndistinguishablePoles if the angle between the poles
+// is less than sep. Poles that are very close together define a valid projection, but one in which the projection
+// of any location is highly sensitive to the poles' positions; if the poles are noisy, requiring a minimum separation
+// turns such an ill-conditioned projection into a detectable error. It does not affect the constructed projection.
+func WithMinPoleSeparation(sep s1.Angle) Option {
+	return func(gm *GeneralizedMercator) error {
+		if !(sep >= 0 && sep <= math.Pi) {
+			return fmt.Errorf("gm: invalid minimum pole separation %v", sep)
+		}
+		gm.minSep = float64(sep)
+		return nil
+	}
+}
+
+// WithTruncation truncates the projection at generalized latitude ±psiMax: locations closer to either pole
+// project to the line y = ±Y, where Y is the y coordinate of the circle of generalized latitude psiMax,
+// so that all projected coordinates are finite. psiMax must be in the interval [0, π/2); zero disables truncation.
+func WithTruncation(psiMax s1.Angle) Option {
+	return func(gm *GeneralizedMercator) error {
+		if !(psiMax >= 0 && psiMax < math.Pi/2) {
+			return fmt.Errorf("gm: invalid truncation latitude %v", psiMax)
+		}
+		gm.psiMax = float64(psiMax)
+		return nil
+	}
+}
+
+// WithExtendedPrecision causes Project and ProjectPoint to compute the projections of locations within near
+// of either pole in double-double arithmetic, which is about an order of magnitude slower than float64 arithmetic.
+// In float64 arithmetic, the projected y coordinate at a distance δ from a pole has an error of about 1e-16/δ
+// before scaling, from cancellation in the components of the location along the basis vectors: a meter at
+// the Earth's radius at δ == 1e-10. In double-double arithmetic, the error is at most a few ulps of the coordinate.
+// Locations within the tolerance of a pole project to the pole either way, and the accuracy of a round trip through
+// Unproject remains limited by the resolution of the unprojected location, whose rounding error is magnified
+// by the scale of the projection. near must be in the interval [0, π]; zero disables extended precision,
+// which is the default.
+func WithExtendedPrecision(near s1.Angle) Option {
+	return func(gm *GeneralizedMercator) error {
+		if !(near >= 0 && near <= math.Pi) {
+			return fmt.Errorf("gm: invalid extended precision angle %v", near)
+		}
+		gm.extended = float64(near)
+		return nil
+	}
+}
+
+// WithLatLngPolicy sets the treatment of invalid locations, whose latitude is outside [-π/2, π/2] or whose longitude
+// is outside [-π, π], by the projection operations and by constructors that take locations. The default is
+// LatLngAsGiven.
+func WithLatLngPolicy(policy LatLngPolicy) Option {
+	return func(gm *GeneralizedMercator) error {
+		if policy < LatLngAsGiven || policy > LatLngRejected {
+			return fmt.Errorf("gm: invalid location policy %d", policy)
+		}
+		gm.policy = policy
+		return nil
+	}
+}
+
+// DefaultTolerance is the default tolerance of a GeneralizedMercator: about 6.4 nanometers at the Earth's surface,
+// large enough to absorb the rounding error of s2.PointFromLatLng at the poles of the Earth and on the Equator.
+const DefaultTolerance = epsilon
+
+// WithTolerance sets the tolerance within which points are taken to coincide: a location each of whose coordinates
+// on the unit sphere is within tol of those of a pole is taken to be the pole, poles within tol of coinciding
+// are indistinguishable, poles within tol of antipodes are taken to be antipodes, and coordinates of the poles
+// within tol of an integer are rounded to it. A greater tolerance accommodates noisy inputs, for which locations
+// that should be at a pole would otherwise project to extreme but finite coordinates; a lesser one distinguishes
+// locations closer to a pole, and zero takes only exact equality to be coincidence. tol must be in the interval
+// [0, 1e-3]. The default is DefaultTolerance.
+func WithTolerance(tol float64) Option {
+	return func(gm *GeneralizedMercator) error {
+		if !(tol >= 0 && tol <= 1e-3) {
+			return fmt.Errorf("gm: invalid tolerance %v", tol)
+		}
+		gm.tol = tol
+		return nil
+	}
+}
+
+// WithExactPoles causes New to use the poles exactly as given. By default, each component of a pole within
+// the tolerance of an integer is rounded to it, so that poles that s2.PointFromLatLng computes with rounding error,
+// such as the North and South Poles, are exact, and the normal Mercator projection uses the latitude and longitude
+// of a location directly; as a consequence, poles that differ only by less than the tolerance can produce
+// identical projections. With exact poles, such poles produce distinct projections, and the normal Mercator projection
+// uses the latitude and longitude directly only if its poles are given as exact points, such as by NewFromPoints.
+// Locations within the tolerance of a pole are still taken to be the pole; WithTolerance(0) disables that as well.
+func WithExactPoles() Option {
+	return withExactPoles(true)
+}
+
+// withExactPoles returns the Option that sets whether the poles are used exactly as given.
+func withExactPoles(exact bool) Option {
+	return func(gm *GeneralizedMercator) error {
+		gm.exact = exact
+		return nil
+	}
+}
+
+// Tolerance returns the tolerance of gm set by WithTolerance.
+func (gm *GeneralizedMercator) Tolerance() float64 {
+	return gm.tol
+}
+
+// WebMercatorRadius is the radius in meters of the sphere of the Web Mercator projection (EPSG:3857).
+const WebMercatorRadius = 6378137
+
+// WebMercatorTruncation is the latitude, approximately 85.0511°, at which the Web Mercator projection is truncated
+// so that the projected world is a square.
+var WebMercatorTruncation = s1.Angle(math.Atan(math.Sinh(math.Pi)))
+
+// NewWebMercator returns a pointer to a GeneralizedMercator, configured by opts, that is equivalent to
+// the Web Mercator projection (EPSG:3857): the Mercator projection with its poles at the North and South Poles,
+// coordinates in meters on a sphere of radius WebMercatorRadius, and truncation at WebMercatorTruncation,
+// so that Project returns coordinates in the square [-πR, πR] × [-πR, πR].
+func NewWebMercator(opts ...Option) *GeneralizedMercator {
+	opts = append([]Option{WithRadius(WebMercatorRadius), WithTruncation(WebMercatorTruncation)}, opts...)
+	return New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), opts...)
+}
+
+// WGS84Flattening is the flattening of the WGS 84 reference ellipsoid.
+const WGS84Flattening = 1 / 298.257223563
+
+// WithGeodeticLatitude specifies that latitudes, including those of the poles passed to New,
+// are geodetic latitudes on an ellipsoid of revolution with the given flattening,
+// such as WGS84Flattening for GPS data. Each geodetic latitude φ is converted to the geocentric latitude
+// atan((1-flattening)² tan(φ)) of the corresponding point on the ellipsoid's surface, whose direction
+// from the center determines its location on the reference sphere; latitudes returned by Unproject and Poles
+// are converted back. By default, the flattening is 0 and latitudes are taken to be spherical.
+//
+// The conversion applies only where locations are expressed as s2.LatLng values: an s2.Point is always
+// taken as a direction from the center of the reference sphere.
+func WithGeodeticLatitude(flattening float64) Option {
+	return func(gm *GeneralizedMercator) error {
+		if !(flattening >= 0 && flattening < 1) {
+			return fmt.Errorf("gm: invalid flattening %v", flattening)
+		}
+		gm.flattening = flattening
+		return nil
+	}
+}
+
+// pointFromLatLng returns the point on the reference sphere corresponding to ll, or a point with NaN components
+// if the LatLngPolicy of gm rejects ll.
+func (gm *GeneralizedMercator) pointFromLatLng(ll s2.LatLng) s2.Point {
+	ll, ok := gm.location(ll)
+	if !ok {
+		return s2.Point{Vector: r3.Vector{X: math.NaN(), Y: math.NaN(), Z: math.NaN()}}
+	}
+	ll.Lat = gm.pointLat(ll.Lat)
+	return s2.PointFromLatLng(ll)
+}
+
+// pointLat returns the latitude on the reference sphere corresponding to lat.
+func (gm *GeneralizedMercator) pointLat(lat s1.Angle) s1.Angle {
+	if gm.flattening == 0 {
+		return lat
+	}
+	e := (1 - gm.flattening) * (1 - gm.flattening)
+	return s1.Angle(math.Atan2(e*math.Sin(float64(lat)), math.Cos(float64(lat))))
+}
+
+// latLngFromPoint returns the location corresponding to the point p on the reference sphere.
+func (gm *GeneralizedMercator) latLngFromPoint(p s2.Point) s2.LatLng {
+	ll := s2.LatLngFromPoint(p)
+	ll.Lat = gm.latFromPointLat(ll.Lat)
+	return ll
+}
+
+// latFromPointLat returns the latitude corresponding to the latitude lat on the reference sphere. It is the inverse of pointLat.
+func (gm *GeneralizedMercator) latFromPointLat(lat s1.Angle) s1.Angle {
+	if gm.flattening == 0 {
+		return lat
+	}
+	e := (1 - gm.flattening) * (1 - gm.flattening)
+	return s1.Angle(math.Atan2(math.Sin(float64(lat)), e*math.Cos(float64(lat))))
+}
@@ -0,0 +1,411 @@
+package gm
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestWithRadius(t *testing.T) {
+	const r = 6378137
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg, WithRadius(r))
+		if got := gm.Radius(); got != r {
+			t.Errorf("Radius(%v): got %v, want %v", gm, got, r)
+		}
+		for _, p := range test.ps {
+			want := p.r.Mul(r)
+			if got := gm.Project(p.s); !(got.X == want.X || math.Abs(got.X-want.X) < 1e-15*r) || !(got.Y == want.Y || math.Abs(got.Y-want.Y) < 1e-15*r) {
+				t.Errorf("Project(%v, %v): got %v, want %v", gm, p.s, got, want)
+			}
+			if got := gm.Unproject(want); !llApproxEqual(got, p.s) {
+				t.Errorf("Unproject(%v, %v): got %v, want %v", gm, want, got, p.s)
+			}
+		}
+	}
+	for _, r := range []float64{0, -1, math.Inf(1), math.NaN()} {
+		if _, err := newGM(s2.PointFromLatLng(s2.LatLngFromDegrees(90, 0)).Vector, s2.PointFromLatLng(s2.LatLngFromDegrees(-90, 0)).Vector, WithRadius(r)); err == nil {
+			t.Errorf("WithRadius(%v): got nil error", r)
+		}
+	}
+}
+
+func TestWithGeodeticLatitude(t *testing.T) {
+	for _, test := range []struct {
+		pos, neg s2.LatLng
+		ll       s2.LatLng
+		want     r2.Point
+	}{
+		// Geodetic latitude 45° corresponds to geocentric latitude atan((1-f)²) on the equatorial Mercator.
+		{
+			s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0),
+			s2.LatLngFromDegrees(45, 30),
+			r2.Point{X: pi / 6, Y: math.Asinh(math.Pow(1-WGS84Flattening, 2))},
+		},
+		// Equatorial points are unaffected.
+		{
+			s2.LatLngFromDegrees(0, 90), s2.LatLngFromDegrees(0, -90),
+			s2.LatLngFromDegrees(0, 45),
+			r2.Point{X: 0, Y: math.Asinh(1)},
+		},
+	} {
+		gm := New(test.pos, test.neg, WithGeodeticLatitude(WGS84Flattening))
+		if got := gm.Project(test.ll); !ptApproxEqual(got, test.want) {
+			t.Errorf("Project(%v, %v): got %v, want %v", gm, test.ll, got, test.want)
+		}
+		if got := gm.Unproject(test.want); !llApproxEqual(got, test.ll) {
+			t.Errorf("Unproject(%v, %v): got %v, want %v", gm, test.want, got, test.ll)
+		}
+		if pos, neg := gm.Poles(); !llApproxEqual(pos, test.pos) || !llApproxEqual(neg, test.neg) {
+			t.Errorf("Poles(%v): got %v, %v, want %v, %v", gm, pos, neg, test.pos, test.neg)
+		}
+	}
+
+	// A pole given in geodetic coordinates is located at the corresponding geocentric latitude.
+	gm := New(s2.LatLngFromDegrees(45, 0), s2.LatLngFromDegrees(-45, 180), WithGeodeticLatitude(WGS84Flattening))
+	want := s2.LatLng{Lat: s1.Angle(math.Atan(math.Pow(1-WGS84Flattening, 2)))}
+	if got := s2.LatLngFromPoint(s2.Point{Vector: gm.pos}); !llApproxEqual(got, want) {
+		t.Errorf("New(45°N 0°E, 45°S 180°E, WithGeodeticLatitude(WGS84Flattening)): got positive pole %v, want %v", got, want)
+	}
+
+	for _, f := range []float64{-0.1, 1, math.NaN()} {
+		if _, err := newFromLatLngs(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithGeodeticLatitude(f)); err == nil {
+			t.Errorf("WithGeodeticLatitude(%v): got nil error", f)
+		}
+	}
+}
+
+func TestWithCentralPoint(t *testing.T) {
+	for _, test := range scaleTests {
+		pos, neg := test.gm.Poles()
+		for _, c := range test.lls {
+			gm := New(pos, neg, WithCentralPoint(c))
+			if got := gm.Project(c); math.Abs(got.X) > 1e-14 {
+				t.Errorf("Project(%v, %v): got %v, want x == 0", gm, c, got)
+			}
+			for _, ll := range test.lls {
+				p, q := test.gm.Project(ll), gm.Project(ll)
+				if q.Y != p.Y || math.Abs(math.Remainder(q.X-(p.X-test.gm.Project(c).X), 2*pi)) > 1e-14 || math.Abs(q.X) > pi {
+					t.Errorf("Project(%v, %v): got %v, want %v shifted by %v", gm, ll, q, p, test.gm.Project(c).X)
+				}
+				if got := gm.Unproject(q); !llApproxEqual(got, ll) {
+					t.Errorf("Unproject(%v, %v): got %v, want %v", gm, q, got, ll)
+				}
+			}
+		}
+		if _, err := newFromLatLngs(pos, neg, WithCentralPoint(pos)); err == nil {
+			t.Errorf("WithCentralPoint(%v) for %v: got nil error", pos, test.gm)
+		}
+	}
+}
+
+func TestWithCentralLongitude(t *testing.T) {
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg, WithCentralLongitude(pi/2))
+		for _, p := range test.ps {
+			want := p.r
+			if !math.IsInf(want.Y, 0) {
+				want.X = math.Remainder(want.X-pi/2, 2*pi)
+			}
+			if got := gm.Project(p.s); !ptApproxEqual(got, want) && !(math.Abs(got.X) == pi && math.Abs(want.X) == pi) {
+				t.Errorf("Project(%v, %v): got %v, want %v", gm, p.s, got, want)
+			}
+		}
+	}
+}
+
+func TestWebMercator(t *testing.T) {
+	const r = WebMercatorRadius
+	gm := NewWebMercator()
+	for _, ll := range []s2.LatLng{
+		s2.LatLngFromDegrees(0, 0),
+		s2.LatLngFromDegrees(51.4779, -0.0015),
+		s2.LatLngFromDegrees(-33.8688, 151.2093),
+		s2.LatLngFromDegrees(85, 180),
+		s2.LatLngFromDegrees(-85, -179.9),
+	} {
+		// The EPSG:3857 forward formulas, which Project evaluates in an equivalent form
+		want := r2.Point{X: r * float64(ll.Lng), Y: r * math.Log(math.Tan(pi/4+float64(ll.Lat)/2))}
+		if got := gm.Project(ll); got.X != want.X || math.Abs(got.Y-want.Y) > 1e-15*math.Abs(want.Y) {
+			t.Errorf("Project(%v, %v): got %v, want %v", gm, ll, got, want)
+		}
+		if got := gm.Unproject(want); !got.ApproxEqual(ll) {
+			t.Errorf("Unproject(%v, %v): got %v, want %v", gm, want, got, ll)
+		}
+	}
+	for _, ll := range []s2.LatLng{
+		s2.LatLngFromDegrees(90, 0),
+		s2.LatLngFromDegrees(89, 10),
+		s2.LatLngFromDegrees(-86, -10),
+		s2.LatLngFromDegrees(-90, 0),
+	} {
+		if got := gm.Project(ll); math.Abs(math.Abs(got.Y)-pi*r) > 1e-6 || math.Abs(got.X) > pi*r {
+			t.Errorf("Project(%v, %v): got %v, want a point on y = ±πR", gm, ll, got)
+		}
+	}
+	if got, want := gm.Bounds(pi/2), r2.RectFromPoints(r2.Point{X: -pi * r, Y: -pi * r}, r2.Point{X: pi * r, Y: pi * r}); got.X != want.X || math.Abs(got.Y.Hi-want.Y.Hi) > 1e-15*pi*r || got.Y.Lo != -got.Y.Hi {
+		t.Errorf("Bounds(%v, π/2): got %v, want %v", gm, got, want)
+	}
+}
+
+func TestWithTruncation(t *testing.T) {
+	psiMax := s1.Angle(math.Asin(0.5))
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg, WithTruncation(psiMax))
+		for _, p := range test.ps {
+			want := p.r
+			if math.Abs(want.Y) > math.Log(sqrt3) {
+				want.Y = math.Copysign(math.Log(sqrt3), want.Y)
+			}
+			if got := gm.Project(p.s); math.Abs(got.Y-want.Y) > 1e-14 || !math.IsInf(p.r.Y, 0) && math.Abs(got.X-want.X) > 1e-14 {
+				t.Errorf("Project(%v, %v): got %v, want %v", gm, p.s, got, want)
+			}
+		}
+	}
+	for _, psi := range []s1.Angle{-1, pi / 2, s1.Angle(math.NaN())} {
+		if _, err := newFromLatLngs(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithTruncation(psi)); err == nil {
+			t.Errorf("WithTruncation(%v): got nil error", psi)
+		}
+	}
+}
+
+func TestWithSeam(t *testing.T) {
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, seam := range []float64{pi, -pi, pi / 2, 0.1, -2, 3 * pi} {
+			gm := New(pos, neg, WithSeam(seam))
+			lo := math.Remainder(seam-pi, 2*pi) - pi
+			for _, p := range test.ps {
+				got := gm.Project(p.s)
+				if math.IsInf(p.r.Y, 0) {
+					if got.Y != p.r.Y {
+						t.Errorf("Project(%v, %v): got %v, want %v", gm, p.s, got, p.r)
+					}
+					continue
+				}
+				if got.X < lo-1e-14 || got.X > lo+2*pi+1e-14 {
+					t.Errorf("Project(%v, %v): got %v, want x in [%v, %v]", gm, p.s, got, lo, lo+2*pi)
+				}
+				// Moving the seam changes x only by a multiple of 2π.
+				if d := got.X - p.r.X; math.Abs(math.Remainder(d, 2*pi)) > 1e-14 || math.Abs(got.Y-p.r.Y) > 1e-14 {
+					t.Errorf("Project(%v, %v): got %v, want %v modulo 2π", gm, p.s, got, p.r)
+				}
+				if ll := gm.Unproject(got); !s2.PointFromLatLng(ll).ApproxEqual(s2.PointFromLatLng(p.s)) {
+					t.Errorf("Unproject(%v, %v): got %v, want %v", gm, got, ll, p.s)
+				}
+			}
+		}
+	}
+	for _, seam := range []float64{math.Inf(1), math.NaN()} {
+		if _, err := newFromLatLngs(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeam(seam)); err == nil {
+			t.Errorf("WithSeam(%v): got nil error", seam)
+		}
+	}
+}
+
+func TestWithSeamSide(t *testing.T) {
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, side := range []SeamSide{SeamPositive, SeamNegative} {
+			gm := New(pos, neg, WithSeamSide(side), WithCentralLongitude(0.7))
+			want := pi
+			if side == SeamNegative {
+				want = -pi
+			}
+			for _, psi := range []float64{-1.2, -0.3, 0, 0.5, 1.4} {
+				p := s2.Point{gm.fromGeneralized(gm.x0+pi, psi)}
+				if got := gm.ProjectPoint(p); got.X != want {
+					t.Errorf("ProjectPoint(%v, %v): got %v, want x == %v", gm, p, got, want)
+				}
+				if got := gm.Project(s2.LatLngFromPoint(p)); got.X != want {
+					t.Errorf("Project(%v, %v): got %v, want x == %v", gm, s2.LatLngFromPoint(p), got, want)
+				}
+			}
+			if got := gm.Swapped().ProjectPoint(s2.Point{gm.fromGeneralized(gm.x0+pi, 0.5)}); got.X != -want {
+				t.Errorf("ProjectPoint(%v): got %v, want x == %v", gm.Swapped(), got, -want)
+			}
+		}
+	}
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeamSide(SeamNegative))
+	for _, lng := range []float64{180, -180, 540} {
+		if got := mercator.Project(s2.LatLngFromDegrees(10, lng)); got.X != -pi {
+			t.Errorf("Project(%v, %v): got %v, want x == %v", mercator, lng, got, -pi)
+		}
+	}
+	for _, side := range []SeamSide{-1, 3} {
+		if _, err := newFromLatLngs(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeamSide(side)); err == nil {
+			t.Errorf("WithSeamSide(%v): got nil error", side)
+		}
+	}
+}
+
+func TestWithScaleFactor(t *testing.T) {
+	const k0 = 0.9996
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg, WithScaleFactor(k0))
+		for _, p := range test.ps {
+			got, want := gm.Project(p.s), p.r.Mul(k0)
+			if math.IsInf(want.Y, 0) {
+				if got.Y != want.Y {
+					t.Errorf("Project(%v, %v): got %v, want %v", gm, p.s, got, want)
+				}
+				continue
+			}
+			if !ptApproxEqual(got, want) {
+				t.Errorf("Project(%v, %v): got %v, want %v", gm, p.s, got, want)
+			}
+			if ll := gm.Unproject(got); !s2.PointFromLatLng(ll).ApproxEqual(s2.PointFromLatLng(p.s)) {
+				t.Errorf("Unproject(%v, %v): got %v, want %v", gm, got, ll, p.s)
+			}
+		}
+	}
+
+	// The projection is true to scale along the standard circles.
+	psi0 := s1.Angle(math.Acos(k0))
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithStandardCircle(psi0))
+	if got := gm.ScaleFactor(); math.Abs(got-k0) > 1e-15 {
+		t.Errorf("ScaleFactor(%v): got %v, want %v", gm, got, k0)
+	}
+	for _, lat := range []s1.Angle{psi0, -psi0} {
+		ll := s2.LatLng{Lat: lat, Lng: 1}
+		if max, min := gm.ScaleFactors(ll); math.Abs(max-1) > 1e-12 || math.Abs(min-1) > 1e-12 {
+			t.Errorf("ScaleFactors(%v, %v): got %v, %v, want 1, 1", gm, ll, max, min)
+		}
+	}
+
+	for _, k := range []float64{0, -1, math.Inf(1), math.NaN()} {
+		if _, err := newFromLatLngs(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithScaleFactor(k)); err == nil {
+			t.Errorf("WithScaleFactor(%v): got nil error", k)
+		}
+	}
+	for _, psi := range []s1.Angle{-1, pi / 2} {
+		if _, err := newFromLatLngs(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithStandardCircle(psi)); err == nil {
+			t.Errorf("WithStandardCircle(%v): got nil error", psi)
+		}
+	}
+}
+
+func TestWithAffine(t *testing.T) {
+	const e, n = 5, -2
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, c := range []struct {
+			opt  Option
+			want func(r2.Point) r2.Point
+		}{
+			{WithFalseOrigin(e, n), func(p r2.Point) r2.Point { return r2.Point{X: p.X + e, Y: p.Y + n} }},
+			{WithAffine(0, -2, 2, 0, e, n), func(p r2.Point) r2.Point { return r2.Point{X: -2*p.Y + e, Y: 2*p.X + n} }},
+		} {
+			gm := New(pos, neg, c.opt)
+			for _, p := range test.ps {
+				got, want := gm.Project(p.s), c.want(p.r)
+				if math.IsInf(p.r.Y, 0) {
+					if want.X != got.X && !(math.IsInf(want.X, 0) && got.X == want.X) || math.IsNaN(got.X) || math.IsNaN(got.Y) {
+						t.Errorf("Project(%v, %v): got %v, want %v", gm, p.s, got, want)
+					}
+				} else if math.Abs(got.X-want.X) > 1e-9 || math.Abs(got.Y-want.Y) > 1e-9 {
+					t.Errorf("Project(%v, %v): got %v, want %v", gm, p.s, got, want)
+				}
+				if ll := gm.Unproject(got); !s2.PointFromLatLng(ll).ApproxEqual(s2.PointFromLatLng(p.s)) {
+					t.Errorf("Unproject(%v, %v): got %v, want %v", gm, got, ll, p.s)
+				}
+			}
+		}
+	}
+	for _, opt := range []Option{
+		WithAffine(1, 2, 2, 4, 0, 0),
+		WithAffine(1, 0, 0, 1, math.Inf(1), 0),
+		WithFalseOrigin(0, math.NaN()),
+	} {
+		if _, err := newFromLatLngs(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), opt); err == nil {
+			t.Errorf("newFromLatLngs with invalid output transformation: got nil error")
+		}
+	}
+}
+
+func TestWithTolerance(t *testing.T) {
+	north, south := s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)
+	oblique := s2.LatLngFromDegrees(37.7, -122.4)
+	for _, test := range []struct {
+		pos, neg s2.LatLng
+		ll       s2.LatLng
+		tol      float64
+		want     bool // whether ll projects to the pole
+	}{
+		{north, south, s2.LatLng{Lat: pi/2 - 1e-12}, DefaultTolerance, false},
+		{north, south, s2.LatLng{Lat: pi/2 - 1e-12}, 1e-9, true},
+		{north, south, s2.LatLngFromDegrees(90, 180), DefaultTolerance, true},
+		{north, south, s2.LatLngFromDegrees(90, 180), 0, false},
+		{north, south, north, 0, true},
+		{oblique, s2.LatLngFromDegrees(-10, 60), s2.LatLng{Lat: oblique.Lat + 1e-12, Lng: oblique.Lng}, DefaultTolerance, false},
+		{oblique, s2.LatLngFromDegrees(-10, 60), s2.LatLng{Lat: oblique.Lat + 1e-12, Lng: oblique.Lng}, 1e-9, true},
+		{s2.LatLngFromDegrees(0, 45), s2.LatLngFromDegrees(0, -135), s2.LatLngFromDegrees(1e-10, 45), 1e-9, true},
+	} {
+		gm := New(test.pos, test.neg, WithTolerance(test.tol))
+		if got := gm.Tolerance(); got != test.tol {
+			t.Errorf("Tolerance(%v): got %v, want %v", gm, got, test.tol)
+		}
+		if got := math.IsInf(gm.Project(test.ll).Y, 1); got != test.want {
+			t.Errorf("Project(%v, %v): got %v, want projection to the pole: %v", gm, test.ll, gm.Project(test.ll), test.want)
+		}
+	}
+	if New(north, south, WithTolerance(0)).IsNormalMercator() {
+		t.Errorf("WithTolerance(0): got normal Mercator projection with unrounded poles")
+	}
+
+	a, b := s2.LatLngFromDegrees(45, 10), s2.LatLng{Lat: s1.Angle(pi/4 + 1e-10), Lng: s1.Angle(pi / 18)}
+	if _, err := TryNew(a, b); err != nil {
+		t.Errorf("TryNew(%v, %v): got error %v", a, b, err)
+	}
+	if _, err := TryNew(a, b, WithTolerance(1e-9)); !errors.Is(err, ErrIndistinguishablePoles) {
+		t.Errorf("TryNew(%v, %v, WithTolerance(1e-9)): got error %v, want %v", a, b, err, ErrIndistinguishablePoles)
+	}
+
+	for _, tol := range []float64{-1, 1e-2, math.NaN()} {
+		if _, err := newFromLatLngs(north, south, WithTolerance(tol)); err == nil {
+			t.Errorf("WithTolerance(%v): got nil error", tol)
+		}
+	}
+}
+
+func TestWithExactPoles(t *testing.T) {
+	for _, test := range []struct {
+		pos, neg s2.LatLng
+		exact    r3.Vector // the exact point that pos approximates
+	}{
+		{s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), r3.Vector{Z: 1}},
+		{s2.LatLngFromDegrees(0, 90), s2.LatLngFromDegrees(0, -90), r3.Vector{Y: 1}},
+		{s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(10, 20), r3.Vector{Z: 1}},
+	} {
+		pos, neg := s2.PointFromLatLng(test.pos), s2.PointFromLatLng(test.neg)
+		if pos.Vector == test.exact {
+			t.Fatalf("PointFromLatLng(%v) == %v exactly", test.pos, test.exact)
+		}
+		exact := s2.Point{Vector: test.exact}
+
+		// By default, the rounding error of pos is snapped away.
+		if a, b := NewFromPoints(pos, neg), NewFromPoints(exact, neg); !a.Equal(b) {
+			t.Errorf("NewFromPoints(%v, %v): got %v, want %v", pos, neg, a, b)
+		}
+		a, b := NewFromPoints(pos, neg, WithExactPoles()), NewFromPoints(exact, neg, WithExactPoles())
+		if a.Equal(b) {
+			t.Errorf("NewFromPoints(%v, %v, WithExactPoles()) == NewFromPoints(%v, %v, WithExactPoles())", pos, neg, exact, neg)
+		}
+		if got, _ := a.PolePoints(); got != pos {
+			t.Errorf("NewFromPoints(%v, %v, WithExactPoles()).PolePoints(): got %v", pos, neg, got)
+		}
+		// Locations within the tolerance of a pole are still taken to be the pole.
+		if got := a.Project(test.pos); !math.IsInf(got.Y, 1) {
+			t.Errorf("Project(%v, %v): got %v, want +Inf", a, test.pos, got)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package oracle
+
+import (
+	"math/big"
+	"sync"
+)
+
+// The elementary functions below compute their results with guardBits more bits of precision than they return,
+// reducing their arguments to small intervals on which their Taylor series converge quickly.
+
+// guardBits is the number of bits by which the working precision of a computation exceeds the precision of its result.
+const guardBits = 64
+
+// halvings is the number of times the reduced argument of sincos, atan, and exp is halved before summing the series,
+// at the cost of a few guard bits for the steps that undo the halving.
+const halvings = 8
+
+func newFloat(prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec)
+}
+
+func fromFloat64(prec uint, v float64) *big.Float {
+	return newFloat(prec).SetFloat64(v)
+}
+
+func fromInt(prec uint, n int64) *big.Float {
+	return newFloat(prec).SetInt64(n)
+}
+
+func add(prec uint, a, b *big.Float) *big.Float { return newFloat(prec).Add(a, b) }
+func sub(prec uint, a, b *big.Float) *big.Float { return newFloat(prec).Sub(a, b) }
+func mul(prec uint, a, b *big.Float) *big.Float { return newFloat(prec).Mul(a, b) }
+func quo(prec uint, a, b *big.Float) *big.Float { return newFloat(prec).Quo(a, b) }
+func sqrt(prec uint, a *big.Float) *big.Float   { return newFloat(prec).Sqrt(a) }
+
+// negligible reports whether term is too small to change sum at precision prec.
+func negligible(prec uint, term, sum *big.Float) bool {
+	return term.Sign() == 0 || sum.Sign() != 0 && term.MantExp(nil) < sum.MantExp(nil)-int(prec)-1
+}
+
+var piCache struct {
+	sync.Mutex
+	v *big.Float
+}
+
+// pi returns π to precision prec, computed by Machin's formula π = 16 atan(1/5) - 4 atan(1/239).
+func pi(prec uint) *big.Float {
+	piCache.Lock()
+	defer piCache.Unlock()
+	if piCache.v == nil || piCache.v.Prec() < prec {
+		wp := prec + guardBits
+		piCache.v = sub(wp, mul(wp, fromInt(wp, 16), atanInv(wp, 5)), mul(wp, fromInt(wp, 4), atanInv(wp, 239)))
+	}
+	return newFloat(prec).Set(piCache.v)
+}
+
+// atanInv returns atan(1/n) to precision prec.
+func atanInv(prec uint, n int64) *big.Float {
+	n2 := fromInt(prec, n*n)
+	term := quo(prec, fromInt(prec, 1), fromInt(prec, n))
+	sum := newFloat(prec).Set(term)
+	for k := int64(1); ; k++ {
+		term = quo(prec, term, n2)
+		t := quo(prec, term, fromInt(prec, 2*k+1))
+		if negligible(prec, t, sum) {
+			return sum
+		}
+		if k%2 == 1 {
+			sum = sub(prec, sum, t)
+		} else {
+			sum = add(prec, sum, t)
+		}
+	}
+}
+
+// ln2 returns the natural logarithm of 2 to precision prec, computed as 2 atanh(1/3).
+func ln2(prec uint) *big.Float {
+	wp := prec + guardBits
+	third := quo(wp, fromInt(wp, 1), fromInt(wp, 3))
+	return newFloat(prec).Mul(fromInt(wp, 2), atanhSeries(wp, third))
+}
+
+// atanhSeries returns atanh(z) to precision prec for |z| < 1, summing its series z + z³/3 + z⁵/5 + ....
+func atanhSeries(prec uint, z *big.Float) *big.Float {
+	z2 := mul(prec, z, z)
+	pow := newFloat(prec).Set(z)
+	sum := newFloat(prec).Set(z)
+	for k := int64(1); ; k++ {
+		pow = mul(prec, pow, z2)
+		t := quo(prec, pow, fromInt(prec, 2*k+1))
+		if negligible(prec, t, sum) {
+			return sum
+		}
+		sum = add(prec, sum, t)
+	}
+}
+
+// sincos returns the sine and cosine of x to precision prec.
+func sincos(prec uint, x *big.Float) (sin, cos *big.Float) {
+	// Reduce x modulo π/2 with enough bits of π to leave prec bits of the remainder.
+	wp := prec + guardBits
+	if e := x.MantExp(nil); e > 0 {
+		wp += uint(e)
+	}
+	halfPi := newFloat(wp).SetMantExp(pi(wp), -1)
+	q := quo(wp, x, halfPi)
+	if q.Sign() < 0 {
+		q.Sub(q, fromFloat64(wp, 0.5))
+	} else {
+		q.Add(q, fromFloat64(wp, 0.5))
+	}
+	k, _ := q.Int(nil)
+	r := sub(wp, x, mul(wp, newFloat(wp).SetInt(k), halfPi))
+	wp = prec + guardBits
+
+	// Halve the remainder, sum the series of its sine, and undo the halving with the double-angle formulas.
+	s := 0
+	if e := r.MantExp(nil); e > -halvings {
+		s = e + halvings
+	}
+	h := newFloat(wp).SetMantExp(r, -s)
+	h2 := mul(wp, h, h)
+	term := newFloat(wp).Set(h)
+	sn := newFloat(wp).Set(h)
+	for n := int64(1); ; n++ {
+		term = quo(wp, mul(wp, term, h2), fromInt(wp, -(2*n)*(2*n+1)))
+		if negligible(wp, term, sn) {
+			break
+		}
+		sn = add(wp, sn, term)
+	}
+	cs := sqrt(wp, sub(wp, fromInt(wp, 1), mul(wp, sn, sn)))
+	for ; s > 0; s-- {
+		sn, cs = newFloat(wp).SetMantExp(mul(wp, sn, cs), 1), sub(wp, fromInt(wp, 1), newFloat(wp).SetMantExp(mul(wp, sn, sn), 1))
+	}
+
+	switch new(big.Int).And(k, big.NewInt(3)).Int64() {
+	case 1:
+		sn, cs = cs, sn.Neg(sn)
+	case 2:
+		sn, cs = sn.Neg(sn), cs.Neg(cs)
+	case 3:
+		sn, cs = cs.Neg(cs), sn
+	}
+	return newFloat(prec).Set(sn), newFloat(prec).Set(cs)
+}
+
+// atan returns the arctangent of x to precision prec.
+func atan(prec uint, x *big.Float) *big.Float {
+	wp := prec + guardBits
+	if x.Sign() < 0 {
+		return newFloat(prec).Neg(atan(prec, newFloat(wp).Neg(x)))
+	}
+	if x.Sign() == 0 {
+		return newFloat(prec)
+	}
+	one := fromInt(wp, 1)
+	if x.Cmp(one) > 0 {
+		// atan(x) = π/2 - atan(1/x).
+		halfPi := newFloat(wp).SetMantExp(pi(wp), -1)
+		return newFloat(prec).Sub(halfPi, atan(wp, quo(wp, one, x)))
+	}
+
+	// Halve the angle by atan(x) = 2 atan(x/(1 + sqrt(1 + x²))), then sum the series x - x³/3 + x⁵/5 - ....
+	z := newFloat(wp).Set(x)
+	for n := 0; n < halvings; n++ {
+		z = quo(wp, z, add(wp, one, sqrt(wp, add(wp, one, mul(wp, z, z)))))
+	}
+	z2 := mul(wp, z, z)
+	pow := newFloat(wp).Set(z)
+	sum := newFloat(wp).Set(z)
+	for k := int64(1); ; k++ {
+		pow = mul(wp, pow, z2)
+		t := quo(wp, pow, fromInt(wp, 2*k+1))
+		if negligible(wp, t, sum) {
+			break
+		}
+		if k%2 == 1 {
+			sum = sub(wp, sum, t)
+		} else {
+			sum = add(wp, sum, t)
+		}
+	}
+	return newFloat(prec).SetMantExp(sum, halvings)
+}
+
+// atan2 returns the angle of the vector (x, y) to precision prec, in the interval [-π, π], like math.Atan2.
+func atan2(prec uint, y, x *big.Float) *big.Float {
+	wp := prec + guardBits
+	switch {
+	case x.Sign() > 0:
+		return atan(prec, quo(wp, y, x))
+	case x.Sign() < 0:
+		a := atan(wp, quo(wp, y, x))
+		if y.Signbit() {
+			return newFloat(prec).Sub(a, pi(wp))
+		}
+		return newFloat(prec).Add(a, pi(wp))
+	case y.Sign() == 0:
+		return newFloat(prec)
+	}
+	halfPi := newFloat(prec).SetMantExp(pi(wp), -1)
+	if y.Sign() < 0 {
+		halfPi.Neg(halfPi)
+	}
+	return halfPi
+}
+
+// exp returns e^x to precision prec.
+func exp(prec uint, x *big.Float) *big.Float {
+	// Reduce x by a multiple k of ln(2), halve the remainder, sum its series, and undo the halving by squaring.
+	wp := prec + guardBits
+	if e := x.MantExp(nil); e > 0 {
+		wp += uint(e)
+	}
+	l := ln2(wp)
+	k, _ := quo(wp, x, l).Int64()
+	r := sub(wp, x, mul(wp, fromInt(wp, k), l))
+	h := newFloat(wp).SetMantExp(r, -halvings)
+	term := fromInt(wp, 1)
+	sum := fromInt(wp, 1)
+	for n := int64(1); ; n++ {
+		term = quo(wp, mul(wp, term, h), fromInt(wp, n))
+		if negligible(wp, term, sum) {
+			break
+		}
+		sum = add(wp, sum, term)
+	}
+	for n := 0; n < halvings; n++ {
+		sum = mul(wp, sum, sum)
+	}
+	return newFloat(prec).SetMantExp(sum, int(k))
+}
+
+// log returns the natural logarithm of x > 0 to precision prec.
+func log(prec uint, x *big.Float) *big.Float {
+	// With x == m × 2^e for m in [1/√2, √2), log(x) = e ln(2) + 2 atanh((m - 1)/(m + 1)).
+	wp := prec + guardBits
+	m := newFloat(wp)
+	e := x.MantExp(m)
+	if m.Cmp(fromFloat64(wp, 0.7071067811865476)) < 0 {
+		m.SetMantExp(m, 1)
+		e--
+	}
+	one := fromInt(wp, 1)
+	z := quo(wp, sub(wp, m, one), add(wp, m, one))
+	l := newFloat(wp).SetMantExp(atanhSeries(wp, z), 1)
+	return newFloat(prec).Add(l, mul(wp, fromInt(wp, int64(e)), ln2(wp)))
+}
+
+// asinh returns the inverse hyperbolic sine of x to precision prec.
+func asinh(prec uint, x *big.Float) *big.Float {
+	wp := prec + guardBits
+	if x.Sign() < 0 {
+		return newFloat(prec).Neg(asinh(prec, newFloat(wp).Neg(x)))
+	}
+	one := fromInt(wp, 1)
+	r := sqrt(wp, add(wp, one, mul(wp, x, x)))
+	if x.Cmp(fromFloat64(wp, 0.5)) < 0 {
+		// asinh(x) = atanh(x/sqrt(1 + x²)) avoids the cancellation of log(1 + x) for small x.
+		return newFloat(prec).Set(atanhSeries(wp, quo(wp, x, r)))
+	}
+	return log(prec, add(wp, x, r))
+}
+
+// sinhcosh returns the hyperbolic sine and cosine of x to precision prec.
+func sinhcosh(prec uint, x *big.Float) (sinh, cosh *big.Float) {
+	wp := prec + guardBits
+	one := fromInt(wp, 1)
+	if x.Cmp(fromFloat64(wp, -0.5)) > 0 && x.Cmp(fromFloat64(wp, 0.5)) < 0 {
+		// Sum the series x + x³/3! + x⁵/5! + ... to avoid the cancellation of (e^x - e^-x)/2 for small x.
+		x2 := mul(wp, x, x)
+		term := newFloat(wp).Set(x)
+		sum := newFloat(wp).Set(x)
+		for n := int64(1); ; n++ {
+			term = quo(wp, mul(wp, term, x2), fromInt(wp, (2*n)*(2*n+1)))
+			if negligible(wp, term, sum) {
+				break
+			}
+			sum = add(wp, sum, term)
+		}
+		return newFloat(prec).Set(sum), sqrt(prec, add(wp, one, mul(wp, sum, sum)))
+	}
+	e := exp(wp, x)
+	inv := quo(wp, one, e)
+	return newFloat(prec).SetMantExp(sub(wp, e, inv), -1), newFloat(prec).SetMantExp(add(wp, e, inv), -1)
+}
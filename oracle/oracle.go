@@ -0,0 +1,424 @@
+/*
+Package oracle evaluates generalized Mercator projections in arbitrary-precision arithmetic.
+
+A Projection computes the projection of a location, or the unprojection of a point, as defined by a
+gm.GeneralizedMercator, carrying out every step of the computation with math/big floating-point numbers of a chosen
+precision instead of float64 values. It is intended as a reference against which to measure the rounding error
+of the float64 implementation: in the tests of code that depends on the accuracy of a projection, or on
+the locations and points of an application's own data, with ProjectError and UnprojectError.
+
+A Projection takes the locations of the poles from PolePoints, exactly as the float64 implementation uses them,
+and its other parameters from the text encoding produced by MarshalText. Approximations that the float64
+implementation makes deliberately, such as taking locations within its tolerance of a pole to be the pole,
+are not errors of precision, and a Projection does not make them, except that poles that the float64 implementation
+takes to be antipodes are exact antipodes: the negative pole is the antipode of the positive pole.
+*/
+package oracle
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/dkmccandless/gm"
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// seamTolerance is the greatest difference in projective longitude from the seam at which the float64 implementation
+// places a location on the seam, unless the seam side is gm.SeamAsComputed.
+const seamTolerance = 1e-14
+
+// outKeys maps the text encoding keys of the coefficients of the output transformation to their indices in out.
+var outKeys = map[string]int{"m11": 0, "m12": 1, "m21": 2, "m22": 3, "fe": 4, "fn": 5}
+
+// A Projection is a generalized Mercator projection evaluated in arbitrary-precision arithmetic.
+type Projection struct {
+	g *gm.GeneralizedMercator
+
+	// prec is the precision of results, and wp is the working precision of intermediate values.
+	prec, wp uint
+
+	// pos and neg are the unit vectors of the poles, and i, j, and k are the basis of the projection operations,
+	// constructed from them as by the float64 implementation.
+	pos, neg, i, j, k vector
+
+	// dinv is the reciprocal of the tangent distance, or 0 if the poles are antipodes.
+	dinv *big.Float
+
+	// e is the square of 1 minus the flattening, by which the tangent of a latitude is multiplied to give that of
+	// the corresponding latitude on the reference sphere.
+	e *big.Float
+
+	// scale is the product of the radius and the scale factor, and x0 and cut are as in gm.GeneralizedMercator.
+	scale, x0, cut *big.Float
+	side           gm.SeamSide
+
+	// policy is the treatment of invalid locations.
+	policy gm.LatLngPolicy
+
+	// yMax is the y coordinate before scaling at which the projection is truncated, or nil if it is not truncated.
+	yMax *big.Float
+
+	// out holds the coefficients a, b, c, d, e, f of the output transformation.
+	out [6]*big.Float
+}
+
+// New returns a Projection that evaluates the projection g with prec bits of precision, which must be at least 53.
+// Each intermediate value is computed with additional guard bits of precision, so that the results are accurate
+// to nearly prec bits except where the projection itself is ill-conditioned, as at a pole. A precision of 128 bits
+// or more suffices to measure the error of float64 results. New returns an error if the text encoding of g
+// has a parameter that New does not recognize.
+func New(g *gm.GeneralizedMercator, prec uint) (*Projection, error) {
+	if prec < 53 {
+		return nil, fmt.Errorf("oracle: precision %d less than 53 bits", prec)
+	}
+	wp := prec + guardBits
+	p := &Projection{g: g, prec: prec, wp: wp, side: gm.SeamAsComputed}
+	radius, k0, flattening := 1.0, 1.0, 0.0
+	var x0, cut, psiMax float64
+	out := [6]float64{1, 0, 0, 1, 0, 0}
+
+	text, err := g.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	// The text encoding begins with the locations of the poles, which New takes from PolePoints instead.
+	fields := strings.Split(string(text), ";")[2:]
+	for _, f := range fields {
+		key, val, _ := strings.Cut(f, "=")
+		v, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("oracle: parameter %q: %v", key, err)
+		}
+		switch key {
+		case "r":
+			radius = v
+		case "k0":
+			k0 = v
+		case "f":
+			flattening = v
+		case "x0":
+			x0 = v
+		case "s":
+			// As in WithSeam, the encoded value is the projective longitude of the seam.
+			cut = math.Remainder(v-math.Pi, 2*math.Pi)
+		case "ss":
+			p.side = gm.SeamSide(v)
+		case "llp":
+			p.policy = gm.LatLngPolicy(v)
+		case "t":
+			psiMax = v
+		case "ep", "tol", "xp":
+			// Extended precision and the tolerance affect only the float64 implementation's approximations,
+			// and whether the poles are exact only the poles themselves, which PolePoints reports.
+		default:
+			n, ok := outKeys[key]
+			if !ok {
+				return nil, fmt.Errorf("oracle: unknown parameter %q", key)
+			}
+			out[n] = v
+		}
+	}
+
+	pos, neg := g.PolePoints()
+	p.pos, p.neg = p.vector(pos.X, pos.Y, pos.Z).normalize(wp), p.vector(neg.X, neg.Y, neg.Z).normalize(wp)
+	if g.IsAntipodalPoles() {
+		// Poles that the float64 implementation takes to be antipodes are exact antipodes.
+		p.neg = p.pos.scale(wp, fromInt(wp, -1))
+	}
+	p.k = p.pos.sub(wp, p.neg).normalize(wp)
+	if g.IsAntipodalPoles() {
+		p.dinv = newFloat(wp)
+		switch {
+		case pos.X == 0:
+			p.i = p.vector(1, 0, 0)
+		case pos.Z == 0:
+			p.i = p.vector(0, 0, 1)
+		default:
+			// The cross product of (0, Z, 0) with Pos.
+			x, z := p.pos[0], p.pos[2]
+			p.i = vector{mul(wp, z, z), newFloat(wp), newFloat(wp).Neg(mul(wp, z, x))}.normalize(wp)
+		}
+	} else {
+		p.i = p.pos.add(wp, p.neg).normalize(wp)
+		p.dinv = p.i.dot(wp, p.pos)
+	}
+	p.j = p.k.cross(wp, p.i)
+
+	p.e = fromFloat64(wp, 1-flattening)
+	p.e.Mul(p.e, p.e)
+	p.scale = mul(wp, fromFloat64(wp, radius), fromFloat64(wp, k0))
+	p.x0, p.cut = fromFloat64(wp, x0), fromFloat64(wp, cut)
+	if psiMax != 0 {
+		sin, cos := sincos(wp, fromFloat64(wp, psiMax))
+		p.yMax = asinh(wp, quo(wp, sin, cos))
+	}
+	for n, v := range out {
+		p.out[n] = fromFloat64(wp, v)
+	}
+	return p, nil
+}
+
+// Prec returns the precision in bits of the results of p.
+func (p *Projection) Prec() uint { return p.prec }
+
+// Project returns the projection of ll, whose latitude and longitude are taken to be exact.
+// An invalid location is normalized if the policy of the projection is gm.LatLngNormalized, and otherwise
+// taken as given; ProjectError reports NaN for a location that the policy rejects.
+// The poles project to points with infinite coordinates, unless the projection is truncated,
+// as do locations less than 2^-prec radians from a pole, which are indistinguishable from it at the working precision.
+func (p *Projection) Project(ll s2.LatLng) (x, y *big.Float) {
+	wp := p.wp
+	if p.policy == gm.LatLngNormalized && !ll.IsValid() {
+		ll = ll.Normalized()
+	}
+	sinLat, cosLat := sincos(wp, fromFloat64(wp, float64(ll.Lat)))
+	sinLng, cosLng := sincos(wp, fromFloat64(wp, float64(ll.Lng)))
+	// The direction (cos(φ), (1-f)² sin(φ)) has the latitude on the reference sphere corresponding to φ.
+	return p.projectVector(vector{mul(wp, cosLat, cosLng), mul(wp, cosLat, sinLng), mul(wp, p.e, sinLat)})
+}
+
+// ProjectPoint returns the projection of pt, whose coordinates are taken to be exact. pt need not be unit length, but must not be zero.
+func (p *Projection) ProjectPoint(pt s2.Point) (x, y *big.Float) {
+	return p.projectVector(p.vector(pt.X, pt.Y, pt.Z))
+}
+
+// projectVector returns the projection of the direction P.
+func (p *Projection) projectVector(P vector) (x, y *big.Float) {
+	wp := p.wp
+	P = P.normalize(wp)
+	a, b, c := P.dot(wp, p.i), P.dot(wp, p.j), P.dot(wp, p.k)
+
+	// The coordinates u, b, w of P in the basis rotated by β around the j axis, where cos(β) and sin(β)
+	// are proportional to 1 - a/d and c/d.
+	u, w := a, c
+	if p.dinv.Sign() != 0 {
+		cos, sin := sub(wp, fromInt(wp, 1), mul(wp, a, p.dinv)), mul(wp, c, p.dinv)
+		r := sqrt(wp, add(wp, mul(wp, cos, cos), mul(wp, sin, sin)))
+		u = quo(wp, sub(wp, mul(wp, a, cos), mul(wp, c, sin)), r)
+		w = quo(wp, add(wp, mul(wp, c, cos), mul(wp, a, sin)), r)
+	}
+
+	// tan(ψ) is the ratio of w to the distance from the k' axis, and y == asinh(tan(ψ)).
+	rho := sqrt(wp, add(wp, mul(wp, u, u), mul(wp, b, b)))
+	if rho.Sign() == 0 || rho.MantExp(nil) < -int(p.prec) {
+		x, y = p.cut, newFloat(wp).SetInf(w.Sign() < 0)
+	} else {
+		x, y = p.wrap(atan2(wp, b, u)), asinh(wp, quo(wp, w, rho))
+	}
+	if p.yMax != nil {
+		if y.Cmp(p.yMax) > 0 {
+			y = p.yMax
+		} else if y.Cmp(newFloat(wp).Neg(p.yMax)) < 0 {
+			y = newFloat(wp).Neg(p.yMax)
+		}
+	}
+	return p.output(mul(wp, x, p.scale), mulInf(wp, y, p.scale))
+}
+
+// wrap reduces the projective longitude x, measured from the i axis, to the horizontal extent of the map,
+// as the float64 implementation does.
+func (p *Projection) wrap(x *big.Float) *big.Float {
+	wp := p.wp
+	twoPi := newFloat(wp).SetMantExp(pi(wp), 1)
+	t := sub(wp, sub(wp, x, p.x0), p.cut)
+	// Reduce t to [-π, π] by subtracting the nearest multiple of 2π, rounding halves to even as math.Remainder does.
+	q := quo(wp, t, twoPi)
+	n, _ := q.Int(nil)
+	if f := sub(wp, q, newFloat(wp).SetInt(n)); f.Abs(f).Cmp(fromFloat64(wp, 0.5)) > 0 || f.Cmp(fromFloat64(wp, 0.5)) == 0 && n.Bit(0) == 1 {
+		n.Add(n, big.NewInt(int64(q.Sign())))
+	}
+	t = sub(wp, t, mul(wp, newFloat(wp).SetInt(n), twoPi))
+	if p.side != gm.SeamAsComputed {
+		limit := sub(wp, pi(wp), fromFloat64(wp, seamTolerance))
+		if newFloat(wp).Abs(t).Cmp(limit) > 0 {
+			t = pi(wp)
+			if p.side == gm.SeamNegative {
+				t.Neg(t)
+			}
+		}
+	}
+	return add(wp, t, p.cut)
+}
+
+// output returns the image of the scaled point (x, y) under the output transformation, rounded to the precision of p.
+func (p *Projection) output(x, y *big.Float) (ox, oy *big.Float) {
+	a, b, c, d, e, f := p.out[0], p.out[1], p.out[2], p.out[3], p.out[4], p.out[5]
+	return newFloat(p.prec).Set(affine(p.wp, a, b, e, x, y)), newFloat(p.prec).Set(affine(p.wp, c, d, f, x, y))
+}
+
+// affine returns a*x + b*y + e, where y may be infinite. As in the float64 implementation,
+// a zero coefficient of an infinite coordinate contributes nothing.
+func affine(prec uint, a, b, e, x, y *big.Float) *big.Float {
+	if y.IsInf() {
+		if b.Sign() == 0 {
+			return add(prec, mul(prec, a, x), e)
+		}
+		return newFloat(prec).SetInf(y.Sign()*b.Sign() < 0)
+	}
+	return add(prec, add(prec, mul(prec, a, x), mul(prec, b, y)), e)
+}
+
+// mulInf returns x*y, where x may be infinite and y is positive.
+func mulInf(prec uint, x, y *big.Float) *big.Float {
+	if x.IsInf() {
+		return newFloat(prec).Set(x)
+	}
+	return mul(prec, x, y)
+}
+
+// Unproject returns the latitude and longitude, in radians, of the location to which pt unprojects.
+// The coordinates of pt are taken to be exact. A point with an infinite coordinate unprojects to a pole,
+// as in the float64 implementation.
+func (p *Projection) Unproject(pt r2.Point) (lat, lng *big.Float) {
+	P := p.unprojectVector(pt)
+	wp := p.wp
+	h := sqrt(wp, add(wp, mul(wp, P[0], P[0]), mul(wp, P[1], P[1])))
+	return atan2(p.prec, P[2], mul(wp, p.e, h)), atan2(p.prec, P[1], P[0])
+}
+
+// UnprojectPoint returns the unit vector of the point on the reference sphere to which pt unprojects.
+func (p *Projection) UnprojectPoint(pt r2.Point) (x, y, z *big.Float) {
+	P := p.unprojectVector(pt)
+	return newFloat(p.prec).Set(P[0]), newFloat(p.prec).Set(P[1]), newFloat(p.prec).Set(P[2])
+}
+
+// unprojectVector returns the unit vector of the point to which pt unprojects.
+func (p *Projection) unprojectVector(pt r2.Point) vector {
+	wp := p.wp
+	a, b, c, d := p.out[0], p.out[1], p.out[2], p.out[3]
+	det := sub(wp, mul(wp, a, d), mul(wp, b, c))
+	if math.IsInf(pt.X, 0) || math.IsInf(pt.Y, 0) {
+		// Only the direction of a point at infinity is significant.
+		x, y := fromInt(wp, int64(infSign(pt.X))), fromInt(wp, int64(infSign(pt.Y)))
+		if quo(wp, sub(wp, mul(wp, a, y), mul(wp, c, x)), det).Sign() < 0 {
+			return p.neg
+		}
+		return p.pos
+	}
+
+	// Invert the output transformation and the scaling.
+	dx := sub(wp, fromFloat64(wp, pt.X), p.out[4])
+	dy := sub(wp, fromFloat64(wp, pt.Y), p.out[5])
+	s := mul(wp, det, p.scale)
+	x := add(wp, quo(wp, sub(wp, mul(wp, d, dx), mul(wp, b, dy)), s), p.x0)
+	y := quo(wp, sub(wp, mul(wp, a, dy), mul(wp, c, dx)), s)
+
+	// With tan(ψ) == sinh(y), sin(ψ) == tanh(y) and cos(ψ) == 1/cosh(y).
+	sinh, cosh := sinhcosh(wp, y)
+	sinPsi, cosPsi := quo(wp, sinh, cosh), quo(wp, fromInt(wp, 1), cosh)
+	sinX, cosX := sincos(wp, x)
+
+	// The coordinates of the point in the rotated basis, rotated back by β around the j axis.
+	u := mul(wp, cosPsi, cosX)
+	pa, pb, pc := u, mul(wp, cosPsi, sinX), sinPsi
+	if p.dinv.Sign() != 0 {
+		sinBeta := mul(wp, sinPsi, p.dinv)
+		cosBeta := sqrt(wp, sub(wp, fromInt(wp, 1), mul(wp, sinBeta, sinBeta)))
+		pa = add(wp, mul(wp, u, cosBeta), mul(wp, sinPsi, sinBeta))
+		pc = sub(wp, mul(wp, sinPsi, cosBeta), mul(wp, u, sinBeta))
+	}
+	return p.i.scale(wp, pa).add(wp, p.j.scale(wp, pb)).add(wp, p.k.scale(wp, pc))
+}
+
+// infSign returns the sign of v if it is infinite, or else 0.
+func infSign(v float64) int {
+	switch {
+	case math.IsInf(v, 1):
+		return 1
+	case math.IsInf(v, -1):
+		return -1
+	}
+	return 0
+}
+
+// ProjectError returns the distance in the plane, in projected units, between the projection of ll by the float64
+// implementation and its exact projection. A location on the seam may project to either edge of the map;
+// the distance is measured to the nearer copy of the exact projection. The error is infinite if exactly one
+// of the projections is infinite, as it is for locations that the float64 implementation takes to be at a pole.
+func (p *Projection) ProjectError(ll s2.LatLng) float64 {
+	got := p.g.Project(ll)
+	x, y := p.Project(ll)
+	fx, _ := x.Float64()
+	fy, _ := y.Float64()
+	if !math.IsInf(fx, 0) && !math.IsInf(fy, 0) && !math.IsInf(got.X, 0) && !math.IsInf(got.Y, 0) {
+		got = p.g.WrapDestination(r2.Point{X: fx, Y: fy}, got)
+	}
+	return math.Hypot(diff(p.wp, got.X, x), diff(p.wp, got.Y, y))
+}
+
+// diff returns the absolute difference between got and want, or 0 if they are the same infinity.
+func diff(prec uint, got float64, want *big.Float) float64 {
+	switch {
+	case math.IsNaN(got):
+		return math.NaN()
+	case math.IsInf(got, 0) || want.IsInf():
+		if math.IsInf(got, 0) && want.IsInf() && (got > 0) == (want.Sign() > 0) {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	d, _ := sub(prec, fromFloat64(prec, got), want).Float64()
+	return math.Abs(d)
+}
+
+// UnprojectError returns the angle on the reference sphere between the unprojection of pt by the float64
+// implementation and its exact unprojection.
+func (p *Projection) UnprojectError(pt r2.Point) s1.Angle {
+	got := p.g.UnprojectPoint(pt)
+	want := p.unprojectVector(pt)
+	wp := p.wp
+	g := p.vector(got.X, got.Y, got.Z).normalize(wp)
+	// The angle is twice the arcsine of half the chord between the unit vectors.
+	chord, _ := g.sub(wp, want).norm(wp).Float64()
+	return s1.Angle(2 * math.Asin(math.Min(1, chord/2)))
+}
+
+// A vector is a vector in three dimensions with big.Float components.
+type vector [3]*big.Float
+
+// vector returns the vector with the given float64 components at the working precision of p.
+func (p *Projection) vector(x, y, z float64) vector {
+	return vector{fromFloat64(p.wp, x), fromFloat64(p.wp, y), fromFloat64(p.wp, z)}
+}
+
+func (v vector) add(prec uint, u vector) vector {
+	return vector{add(prec, v[0], u[0]), add(prec, v[1], u[1]), add(prec, v[2], u[2])}
+}
+
+func (v vector) sub(prec uint, u vector) vector {
+	return vector{sub(prec, v[0], u[0]), sub(prec, v[1], u[1]), sub(prec, v[2], u[2])}
+}
+
+func (v vector) scale(prec uint, s *big.Float) vector {
+	return vector{mul(prec, v[0], s), mul(prec, v[1], s), mul(prec, v[2], s)}
+}
+
+func (v vector) dot(prec uint, u vector) *big.Float {
+	return add(prec, add(prec, mul(prec, v[0], u[0]), mul(prec, v[1], u[1])), mul(prec, v[2], u[2]))
+}
+
+func (v vector) cross(prec uint, u vector) vector {
+	return vector{
+		sub(prec, mul(prec, v[1], u[2]), mul(prec, v[2], u[1])),
+		sub(prec, mul(prec, v[2], u[0]), mul(prec, v[0], u[2])),
+		sub(prec, mul(prec, v[0], u[1]), mul(prec, v[1], u[0])),
+	}
+}
+
+func (v vector) norm(prec uint) *big.Float {
+	return sqrt(prec, v.dot(prec, v))
+}
+
+// normalize returns the unit vector in the direction of v, which must not be zero.
+func (v vector) normalize(prec uint) vector {
+	n := v.norm(prec)
+	if n.Sign() == 0 {
+		panic("oracle: zero vector")
+	}
+	return vector{quo(prec, v[0], n), quo(prec, v[1], n), quo(prec, v[2], n)}
+}
@@ -0,0 +1,182 @@
+package oracle
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/dkmccandless/gm"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+const prec = 128
+
+func TestElementary(t *testing.T) {
+	f := func(x *big.Float) float64 {
+		v, _ := x.Float64()
+		return v
+	}
+	for _, x := range []float64{0, 1e-20, 1e-8, 0.3, 0.5, 1, 1.5, 2, math.Pi / 2, 3, -4, 10, 1e3} {
+		b := fromFloat64(prec, x)
+		sin, cos := sincos(prec, b)
+		if got, want := f(sin), math.Sin(x); math.Abs(got-want) > 4e-16*math.Max(1, math.Abs(want)) {
+			t.Errorf("sin(%v): got %v, want %v", x, got, want)
+		}
+		if got, want := f(cos), math.Cos(x); math.Abs(got-want) > 4e-16 {
+			t.Errorf("cos(%v): got %v, want %v", x, got, want)
+		}
+		if got := f(sub(prec, add(prec, mul(prec, sin, sin), mul(prec, cos, cos)), fromInt(prec, 1))); math.Abs(got) > 1e-36 {
+			t.Errorf("sincos(%v): sin² + cos² - 1 == %v", x, got)
+		}
+		if got, want := f(atan(prec, b)), math.Atan(x); math.Abs(got-want) > 4e-16*math.Abs(want) {
+			t.Errorf("atan(%v): got %v, want %v", x, got, want)
+		}
+		if got, want := f(asinh(prec, b)), math.Asinh(x); math.Abs(got-want) > 4e-16*math.Abs(want) {
+			t.Errorf("asinh(%v): got %v, want %v", x, got, want)
+		}
+		if math.Abs(x) < 100 {
+			sinh, cosh := sinhcosh(prec, b)
+			if got, want := f(sinh), math.Sinh(x); math.Abs(got-want) > 4e-16*math.Abs(want) {
+				t.Errorf("sinh(%v): got %v, want %v", x, got, want)
+			}
+			if got, want := f(cosh), math.Cosh(x); math.Abs(got-want) > 4e-16*want {
+				t.Errorf("cosh(%v): got %v, want %v", x, got, want)
+			}
+			if got, want := f(asinh(prec, sinh)), x; math.Abs(got-want) > 1e-30*math.Max(1, math.Abs(want)) {
+				t.Errorf("asinh(sinh(%v)): got %v", x, got)
+			}
+		}
+		if x > 0 {
+			if got, want := f(log(prec, b)), math.Log(x); math.Abs(got-want) > 4e-16*math.Max(1, math.Abs(want)) {
+				t.Errorf("log(%v): got %v, want %v", x, got, want)
+			}
+			if got := f(log(prec, exp(prec, b))); math.Abs(got-x) > 1e-30*math.Max(1, x) {
+				t.Errorf("log(exp(%v)): got %v", x, got)
+			}
+		}
+	}
+	for _, test := range []struct{ y, x, want float64 }{
+		{0, 1, 0}, {1, 0, math.Pi / 2}, {-1, 0, -math.Pi / 2}, {1, -1, 3 * math.Pi / 4}, {-1, -1, -3 * math.Pi / 4},
+	} {
+		if got := f(atan2(prec, fromFloat64(prec, test.y), fromFloat64(prec, test.x))); math.Abs(got-test.want) > 4e-16 {
+			t.Errorf("atan2(%v, %v): got %v, want %v", test.y, test.x, got, test.want)
+		}
+	}
+	// The leading digits of π.
+	if got, want := pi(200).Text('f', 50), "3.14159265358979323846264338327950288419716939937511"; got != want {
+		t.Errorf("pi: got %v, want %v", got, want)
+	}
+}
+
+var projections = []*gm.GeneralizedMercator{
+	gm.New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+	gm.New(s2.LatLngFromDegrees(0, 45), s2.LatLngFromDegrees(0, -135)),
+	gm.New(s2.LatLngFromDegrees(37.7, -122.4), s2.LatLngFromDegrees(-37.7, 57.6), gm.WithRadius(6371008.8), gm.WithScaleFactor(0.9996)),
+	gm.New(s2.LatLngFromDegrees(37.7, -122.4), s2.LatLngFromDegrees(-10, 60), gm.WithCentralLongitude(0.5), gm.WithSeam(1), gm.WithSeamSide(gm.SeamNegative)),
+	gm.New(s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2), gm.WithGeodeticLatitude(gm.WGS84Flattening), gm.WithTruncation(1.4)),
+	gm.New(s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30), gm.WithAffine(2, 1, -1, 3, 100, -50)),
+}
+
+var locations = []s2.LatLng{
+	s2.LatLngFromDegrees(0, 0),
+	s2.LatLngFromDegrees(10, 20),
+	s2.LatLngFromDegrees(-45, 170),
+	s2.LatLngFromDegrees(70, -100),
+	s2.LatLngFromDegrees(-80, -30),
+	s2.LatLngFromDegrees(33.3, 44.4),
+}
+
+func TestProject(t *testing.T) {
+	for _, g := range projections {
+		p, err := New(g, prec)
+		if err != nil {
+			t.Fatalf("New(%v): %v", g, err)
+		}
+		// The float64 implementation agrees to within a small multiple of the precision of its results.
+		tol := 1e-12 * g.Project(s2.LatLngFromDegrees(0, 90)).Norm()
+		for _, ll := range locations {
+			if e := p.ProjectError(ll); e > tol {
+				x, y := p.Project(ll)
+				t.Errorf("%v: Project(%v): got %v, want (%v, %v)", g, ll, g.Project(ll), x, y)
+			}
+			pt := g.Project(ll)
+			if e := p.UnprojectError(pt); e > 1e-14 {
+				lat, lng := p.Unproject(pt)
+				t.Errorf("%v: Unproject(%v): got %v, want (%v, %v)", g, pt, g.Unproject(pt), lat, lng)
+			}
+		}
+		pos, neg := g.PolePoints()
+		if g.IsAntipodalPoles() {
+			neg = s2.Point{Vector: pos.Mul(-1)}
+		}
+		for _, pole := range []s2.Point{pos, neg} {
+			got := g.ProjectPoint(pole)
+			if x, y := p.ProjectPoint(pole); diff(prec, got.X, x) > tol || diff(prec, got.Y, y) > tol {
+				t.Errorf("%v: ProjectPoint(%v): got %v, want (%v, %v)", g, pole, got, x, y)
+			}
+		}
+	}
+}
+
+func mustFloat64(x *big.Float) float64 {
+	v, _ := x.Float64()
+	return v
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, g := range projections {
+		p, err := New(g, 256)
+		if err != nil {
+			t.Fatalf("New(%v): %v", g, err)
+		}
+		for _, ll := range locations {
+			pt := g.Project(ll)
+			lat, lng := p.Unproject(pt)
+			ll := s2.LatLng{Lat: s1.Angle(mustFloat64(lat)), Lng: s1.Angle(mustFloat64(lng))}
+			// The float64 location is within an ulp of the exact one, so its exact projection
+			// is within a corresponding distance of pt.
+			x, y := p.Project(ll)
+			if d := math.Hypot(mustFloat64(x)-pt.X, mustFloat64(y)-pt.Y); d > 1e-14*g.Project(s2.LatLngFromDegrees(0, 90)).Norm() {
+				t.Errorf("%v: Project(Unproject(%v)): got (%v, %v)", g, pt, x, y)
+			}
+		}
+	}
+}
+
+func TestProjectErrorNearPole(t *testing.T) {
+	g := gm.New(s2.LatLngFromDegrees(37.7, -122.4), s2.LatLngFromDegrees(-10, 60))
+	p, err := New(g, prec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos, _ := g.Poles()
+	// The rounding error of the float64 implementation grows approximately as 1e-16/δ at a distance δ from a pole,
+	// and extended precision removes it.
+	ext, err := New(gm.New(s2.LatLngFromDegrees(37.7, -122.4), s2.LatLngFromDegrees(-10, 60), gm.WithExtendedPrecision(1e-3)), prec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var max float64
+	for _, delta := range []float64{1e-9, 1e-10, 1e-11} {
+		for _, ll := range []s2.LatLng{{Lat: pos.Lat + s1.Angle(delta), Lng: pos.Lng}, {Lat: pos.Lat, Lng: pos.Lng + s1.Angle(delta)}} {
+			max = math.Max(max, p.ProjectError(ll))
+			if e := ext.ProjectError(ll); e > 1e-14 {
+				t.Errorf("ProjectError(%v) with extended precision: got %v", ll, e)
+			}
+		}
+	}
+	if max < 1e-8 {
+		t.Errorf("greatest ProjectError near the pole: got %v", max)
+	}
+}
+
+func TestNew(t *testing.T) {
+	g := projections[0]
+	if _, err := New(g, 52); err == nil {
+		t.Errorf("New(%v, 52): got nil error", g)
+	}
+	if p, err := New(g, 100); err != nil || p.Prec() != 100 {
+		t.Errorf("New(%v, 100): got %v, %v", g, p, err)
+	}
+}
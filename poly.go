@@ -0,0 +1,135 @@
+package gm
+
+import (
+	"errors"
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// maxPolyDegree is the greatest degree in w² of the polynomial of a PolyProjector.
+const maxPolyDegree = 64
+
+// polySamples is the number of values of w at which NewPolyProjector measures the error of a polynomial.
+const polySamples = 4096
+
+// A PolyProjector approximates Project within a band of generalized latitudes by evaluating a polynomial in place of
+// the transcendental function that gives the projected y coordinate, for applications that trade exactness for throughput.
+// Since the generalized latitude ψ of a point follows from its coordinates in the rotated basis as sin(ψ),
+// and y = atanh(sin(ψ)), the polynomial approximates y/w as a function of w², where w = sin(ψ),
+// in a Chebyshev series. The projective longitude is computed exactly.
+type PolyProjector struct {
+	gm     *GeneralizedMercator
+	psiMax s1.Angle
+	maxErr float64
+
+	// sinMax is the sine of psiMax, and coeffs are the Chebyshev coefficients of y/w
+	// as a function of 2w²/sinMax² - 1 on [-1, 1].
+	sinMax float64
+	coeffs []float64
+}
+
+// NewPolyProjector returns a PolyProjector for the locations whose generalized latitudes are within psiMax
+// of the generalized equator, whose projections are within maxErr of those of Project in the plane, in projected units,
+// such as meters for a projection constructed with WithRadius(6371008.8). The error is measured at 4096 evenly spaced
+// values of sin(ψ), and the polynomial is chosen so that it is at most half of maxErr at each.
+// Locations outside the band are projected exactly.
+//
+// Wider bands and smaller errors require polynomials of higher degree: the degree grows with the logarithm of the
+// reciprocal of maxErr and steeply as psiMax approaches π/2. NewPolyProjector returns an error if psiMax is not in
+// the interval (0, π/2), if maxErr is not positive, or if no polynomial of degree at most 64 in w² achieves maxErr.
+func (gm *GeneralizedMercator) NewPolyProjector(psiMax s1.Angle, maxErr float64) (*PolyProjector, error) {
+	if !(psiMax > 0 && psiMax < math.Pi/2) {
+		return nil, errors.New("gm: invalid polynomial band")
+	}
+	if !(maxErr > 0) {
+		return nil, errors.New("gm: invalid polynomial error bound")
+	}
+	p := &PolyProjector{gm: gm, psiMax: psiMax, maxErr: maxErr, sinMax: math.Sin(float64(psiMax))}
+
+	// An error of δ in y displaces the projected point by δ times the scale and the norm of the image of the y axis
+	// under the output transformation.
+	tol := maxErr / 2 / (gm.radius * gm.k0 * math.Hypot(gm.out.b, gm.out.d))
+	for n := 2; n <= maxPolyDegree; n++ {
+		p.coeffs = chebyshevFit(func(t float64) float64 {
+			w := p.sinMax * math.Sqrt((t+1)/2)
+			if w == 0 {
+				return 1
+			}
+			return math.Atanh(w) / w
+		}, n)
+		if p.fitError() <= tol {
+			return p, nil
+		}
+	}
+	return nil, errors.New("gm: polynomial error bound not achievable")
+}
+
+// chebyshevFit returns the coefficients of the Chebyshev series of degree n that interpolates f
+// at the Chebyshev nodes of the first kind on [-1, 1], with the constant term halved.
+func chebyshevFit(f func(t float64) float64, n int) []float64 {
+	m := n + 1
+	values := make([]float64, m)
+	for j := range values {
+		values[j] = f(math.Cos(math.Pi * (float64(j) + 0.5) / float64(m)))
+	}
+	coeffs := make([]float64, m)
+	for k := range coeffs {
+		var sum float64
+		for j, v := range values {
+			sum += v * math.Cos(math.Pi*float64(k)*(float64(j)+0.5)/float64(m))
+		}
+		coeffs[k] = 2 * sum / float64(m)
+	}
+	coeffs[0] /= 2
+	return coeffs
+}
+
+// fitError returns the greatest error of the polynomial of p in y at the sample values of w.
+func (p *PolyProjector) fitError() float64 {
+	var max float64
+	for n := 0; n <= polySamples; n++ {
+		w := p.sinMax * float64(n) / polySamples
+		if e := math.Abs(p.y(w) - math.Atanh(w)); e > max || math.IsNaN(e) {
+			max = e
+		}
+	}
+	return max
+}
+
+// y returns the approximate projected y coordinate before scaling of the points with sin(ψ) == w,
+// evaluating the Chebyshev series by Clenshaw's recurrence.
+func (p *PolyProjector) y(w float64) float64 {
+	t := 2*w*w/(p.sinMax*p.sinMax) - 1
+	var b1, b2 float64
+	for k := len(p.coeffs) - 1; k > 0; k-- {
+		b1, b2 = 2*t*b1-b2+p.coeffs[k], b1
+	}
+	return w * (t*b1 - b2 + p.coeffs[0])
+}
+
+// PsiMax returns the greatest generalized latitude at which p approximates the projection.
+func (p *PolyProjector) PsiMax() s1.Angle { return p.psiMax }
+
+// MaxError returns the bound on the error of p with which it was constructed.
+func (p *PolyProjector) MaxError() float64 { return p.maxErr }
+
+// Degree returns the degree in w² of the polynomial of p.
+func (p *PolyProjector) Degree() int { return len(p.coeffs) - 1 }
+
+// Project converts ll to a projected 2D point, approximately if it is within the band of p.
+func (p *PolyProjector) Project(ll s2.LatLng) r2.Point {
+	return p.ProjectPoint(p.gm.pointFromLatLng(ll))
+}
+
+// ProjectPoint converts a point on the reference sphere to a projected 2D point, approximately if it is within the band of p.
+func (p *PolyProjector) ProjectPoint(pt s2.Point) r2.Point {
+	gm := p.gm
+	u, b, w := gm.rotated(pt.Vector)
+	if !(math.Abs(w) <= p.sinMax) {
+		return gm.ProjectPoint(pt)
+	}
+	return gm.out.apply(gm.toPlaneY(math.Atan2(b, u), p.y(w)))
+}
@@ -0,0 +1,77 @@
+package gm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestPolyProjector(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{
+			New(pos, neg, WithRadius(6371008.8)),
+			New(pos, neg, WithRadius(6371008.8), WithCentralLongitude(0.5), WithSeam(1), WithTruncation(1)),
+			New(pos, neg, WithAffine(0, -2, 2, 0, 2, 3)),
+		} {
+			for _, psiMax := range []s1.Angle{0.5, 1, 1.3} {
+				for _, maxErr := range []float64{1, 1e-3} {
+					p, err := gm.NewPolyProjector(psiMax, maxErr)
+					if err != nil {
+						t.Fatalf("NewPolyProjector(%v, %v, %v): %v", gm, psiMax, maxErr, err)
+					}
+					if p.PsiMax() != psiMax || p.MaxError() != maxErr {
+						t.Errorf("NewPolyProjector(%v, %v, %v): got band %v and error %v", gm, psiMax, maxErr, p.PsiMax(), p.MaxError())
+					}
+					for n := 0; n < 500; n++ {
+						P := s2.Point{randomPoint(rnd).Normalize()}
+						got, want := p.ProjectPoint(P), gm.ProjectPoint(P)
+						_, psi := gm.EquatorPoint(P)
+						switch {
+						case math.Abs(float64(psi)) > float64(psiMax):
+							if got != want {
+								t.Errorf("%v.NewPolyProjector(%v).ProjectPoint(%v) outside the band: got %v, want %v", gm, psiMax, P, got, want)
+							}
+						case gm.WrapDestination(want, got).Sub(want).Norm() > maxErr:
+							t.Errorf("%v.NewPolyProjector(%v, %v).ProjectPoint(%v): got %v, want %v", gm, psiMax, maxErr, P, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPolyProjectorInvalid(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, test := range []struct {
+		psiMax s1.Angle
+		maxErr float64
+	}{
+		{0, 1e-3},
+		{math.Pi / 2, 1e-3},
+		{1, 0},
+		{1, math.NaN()},
+		{1.5707, 1e-12},
+	} {
+		if _, err := gm.NewPolyProjector(test.psiMax, test.maxErr); err == nil {
+			t.Errorf("NewPolyProjector(%v, %v): got nil error", test.psiMax, test.maxErr)
+		}
+	}
+}
+
+func BenchmarkPolyProjector(b *testing.B) {
+	gm := New(s2.LatLng{Lat: math.Pi / 3}, s2.LatLng{Lat: -math.Pi / 3}, WithRadius(6371008.8))
+	p, err := gm.NewPolyProjector(1, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Project(s2.LatLng{Lat: math.Pi / 4, Lng: 3 * math.Pi / 4})
+	}
+}
@@ -0,0 +1,345 @@
+package gm
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// ProjectLoop projects the region enclosed by l, on the left of its vertex chain, and returns it as a set of rings,
+// cut along the seam so that no edge jumps between the left and right edges of the map. Each ring is a closed
+// sequence of points whose last point connects to its first. The rings are oriented like l: counterclockwise around
+// the projected region and clockwise around parts of the map that it excludes, so that the region consists of
+// the points with a nonzero winding number.
+//
+// Since the poles project to infinity, projected y coordinates are clamped to the interval [-maxY, maxY],
+// which must be positive and finite. A loop that encloses a pole projects to a band that extends to the top
+// or bottom of the strip |y| <= maxY, and a loop that encloses the whole seam projects to the strip minus the
+// projection of its complement. ProjectLoop returns nil if l is empty.
+func (gm *GeneralizedMercator) ProjectLoop(l *s2.Loop, maxY float64) [][]r2.Point {
+	switch {
+	case l.IsEmpty():
+		return nil
+	case l.IsFull():
+		return gm.outRings([][]r2.Point{gm.strip(maxY)}, 0)
+	}
+	return gm.outRings(gm.projectRings([][]s2.Point{l.Vertices()}, l.ContainsPoint, maxY), 0)
+}
+
+// ProjectPolygon is like ProjectLoop, but projects the interior of p. Shells project to counterclockwise rings
+// and holes to clockwise rings.
+func (gm *GeneralizedMercator) ProjectPolygon(p *s2.Polygon, maxY float64) [][]r2.Point {
+	return gm.outRings(gm.polygonRings(p, maxY), 0)
+}
+
+// polygonRings projects the interior of p as in ProjectPolygon, but before the output transformation,
+// with x coordinates measured from the middle of the map.
+func (gm *GeneralizedMercator) polygonRings(p *s2.Polygon, maxY float64) [][]r2.Point {
+	switch {
+	case p.IsEmpty():
+		return nil
+	case p.IsFull():
+		return [][]r2.Point{gm.strip(maxY)}
+	}
+	var rings [][]s2.Point
+	for _, l := range p.Loops() {
+		ring := make([]s2.Point, l.NumVertices())
+		for n := range ring {
+			ring[n] = l.OrientedVertex(n)
+		}
+		rings = append(rings, ring)
+	}
+	return gm.projectRings(rings, p.ContainsPoint, maxY)
+}
+
+// strip returns the counterclockwise ring bounding the map strip |y| <= maxY before any output transformation,
+// with x coordinates measured from the middle of the map.
+func (gm *GeneralizedMercator) strip(maxY float64) []r2.Point {
+	e := math.Pi * gm.radius * gm.k0
+	return []r2.Point{{X: -e, Y: -maxY}, {X: e, Y: -maxY}, {X: e, Y: maxY}, {X: -e, Y: maxY}}
+}
+
+// A ringPiece is a part of a ring between two crossings of the seam, projected before any output transformation.
+// Its first and last points are on the edges of the map.
+type ringPiece []r2.Point
+
+// projectRings projects the region on the left of the vertex chains of rings, whose interior is reported by contains,
+// before the output transformation.
+func (gm *GeneralizedMercator) projectRings(rings [][]s2.Point, contains func(s2.Point) bool, maxY float64) [][]r2.Point {
+	var closed [][]r2.Point
+	var pieces []ringPiece
+	for _, ring := range rings {
+		c, p := gm.cutRing(ring, maxY)
+		if c != nil {
+			closed = append(closed, c)
+		}
+		pieces = append(pieces, p...)
+	}
+	if len(pieces) == 0 {
+		// No ring crosses the seam, so the seam is entirely inside or outside the region.
+		if contains(gm.seamProbe(rings)) {
+			closed = append([][]r2.Point{gm.strip(maxY)}, closed...)
+		}
+		return closed
+	}
+	return append(gm.stitch(pieces, maxY), closed...)
+}
+
+// seamProbe returns a point on the seam that is not on any edge of rings, if possible, so that testing it
+// determines whether a region bounded by rings that do not cross the seam contains the seam.
+func (gm *GeneralizedMercator) seamProbe(rings [][]s2.Point) s2.Point {
+	x := gm.x0 + gm.cut + math.Pi
+	for n := 0; n < 16; n++ {
+		// Try generalized latitudes alternating about the equator: 0, 0.1, -0.1, 0.2, ...
+		psi := float64((n+1)/2) * 0.1
+		if n%2 == 0 {
+			psi = -psi
+		}
+		p := s2.Point{gm.fromGeneralized(x, psi)}
+		if !nearRings(p, rings, 1e-9) {
+			return p
+		}
+	}
+	return s2.Point{gm.fromGeneralized(x, 0)}
+}
+
+// nearRings reports whether p is within d of any edge of rings.
+func nearRings(p s2.Point, rings [][]s2.Point, d s1.Angle) bool {
+	for _, ring := range rings {
+		for n := range ring {
+			if s2.DistanceFromSegment(p, ring[n], ring[(n+1)%len(ring)]) <= d {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cutRing projects ring, clamping y coordinates to [-maxY, maxY]. If ring does not cross the seam,
+// cutRing returns its projection as a closed ring; otherwise it returns the pieces between crossings.
+func (gm *GeneralizedMercator) cutRing(ring []s2.Point, maxY float64) (closed []r2.Point, pieces []ringPiece) {
+	e := math.Pi * gm.radius * gm.k0
+	clamp := func(y float64) float64 { return math.Max(-maxY, math.Min(y, maxY)) }
+	n := len(ring)
+	pts := make([]r2.Point, n)
+	sides := make([]float64, n)
+	inherit := make([]bool, n)
+	onSeam := make([]bool, n)
+	for m, v := range ring {
+		pts[m] = gm.projectCentered(v)
+		pts[m].Y = clamp(pts[m].Y)
+		sin, cos, ok := gm.seamSide(v)
+		switch {
+		case !ok:
+			inherit[m] = true
+		case math.Abs(sin) < epsilon:
+			// A vertex on the seam or the middle of the map is on the same side as its predecessor.
+			inherit[m] = true
+			onSeam[m] = cos < 0
+		case sin > 0:
+			sides[m] = 1
+		default:
+			sides[m] = -1
+		}
+	}
+	first := -1
+	for m := range sides {
+		if !inherit[m] {
+			first = m
+			break
+		}
+	}
+	for k := 1; k <= n; k++ {
+		if m := (first + k + n) % n; inherit[m] {
+			if first < 0 {
+				sides[m] = 1
+			} else {
+				sides[m] = sides[(m+n-1)%n]
+			}
+		}
+	}
+
+	// vertexPts returns the projection of vertex m. A pole projects to the segment of the top or bottom edge
+	// of the strip between the x coordinates of its neighbors, since the edges meeting there follow lines of constant x
+	// if the poles are antipodes.
+	vertexPts := func(m int) []r2.Point {
+		p := pts[m]
+		switch {
+		case onSeam[m]:
+			p.X = sides[m] * e
+		case math.IsInf(gm.projectCentered(ring[m]).Y, 0):
+			return []r2.Point{{X: pts[(m+n-1)%n].X, Y: p.Y}, {X: pts[(m+1)%n].X, Y: p.Y}}
+		}
+		return []r2.Point{p}
+	}
+
+	// Find the crossings, in order of the edges on which they occur.
+	type crossing struct {
+		edge int
+		y    float64
+	}
+	var crossings []crossing
+	for m := range ring {
+		next := (m + 1) % n
+		if sides[m] == sides[next] {
+			continue
+		}
+		if onSeam[m] {
+			crossings = append(crossings, crossing{m, pts[m].Y})
+			continue
+		}
+		if c, ok := gm.seamCrossing(ring[m], ring[next]); ok {
+			crossings = append(crossings, crossing{m, clamp(gm.projectCentered(c).Y)})
+		}
+	}
+	if len(crossings) == 0 {
+		for m := range ring {
+			closed = append(closed, vertexPts(m)...)
+		}
+		return closed, nil
+	}
+
+	// Each piece begins at a crossing and continues through the vertices up to the next crossing.
+	// A vertex on the seam at which the ring crosses it is replaced by the crossing point.
+	for k, c := range crossings {
+		end := crossings[(k+1)%len(crossings)]
+		piece := ringPiece{{X: sides[(c.edge+1)%n] * e, Y: c.y}}
+		for m := (c.edge + 1) % n; ; m = (m + 1) % n {
+			if m != end.edge || !onSeam[m] {
+				piece = append(piece, vertexPts(m)...)
+			}
+			if m == end.edge {
+				break
+			}
+		}
+		piece = append(piece, r2.Point{X: sides[end.edge] * e, Y: end.y})
+		pieces = append(pieces, piece)
+	}
+	return nil, pieces
+}
+
+// stitch joins pieces into closed rings along the boundary of the map strip |y| <= maxY.
+// Since each piece has the region on its left, the boundary of the region continues from the end of each piece
+// counterclockwise around the strip to the start of the next piece.
+func (gm *GeneralizedMercator) stitch(pieces []ringPiece, maxY float64) [][]r2.Point {
+	e := math.Pi * gm.radius * gm.k0
+	perimeter := 4*maxY + 4*e
+	// param returns the counterclockwise distance along the boundary of the strip from its lower right corner to p,
+	// which is on the right or left edge.
+	param := func(p r2.Point) float64 {
+		if p.X > 0 {
+			return p.Y + maxY
+		}
+		return 2*maxY + 2*e + maxY - p.Y
+	}
+	corners := []struct {
+		t float64
+		p r2.Point
+	}{
+		{0, r2.Point{X: e, Y: -maxY}},
+		{2 * maxY, r2.Point{X: e, Y: maxY}},
+		{2*maxY + 2*e, r2.Point{X: -e, Y: maxY}},
+		{4*maxY + 2*e, r2.Point{X: -e, Y: -maxY}},
+	}
+	dist := func(from, to float64) float64 {
+		d := math.Mod(to-from, perimeter)
+		if d < 0 {
+			d += perimeter
+		}
+		return d
+	}
+
+	var rings [][]r2.Point
+	used := make([]bool, len(pieces))
+	for start := range pieces {
+		if used[start] {
+			continue
+		}
+		var ring []r2.Point
+		for cur := start; ; {
+			used[cur] = true
+			ring = append(ring, pieces[cur]...)
+			te := param(pieces[cur][len(pieces[cur])-1])
+			next, best := -1, math.Inf(1)
+			for k, p := range pieces {
+				if used[k] && k != start {
+					continue
+				}
+				if d := dist(te, param(p[0])); d < best {
+					next, best = k, d
+				}
+			}
+			// Add the corners of the strip passed on the way to the next piece.
+			var passed []int
+			for k, c := range corners {
+				if d := dist(te, c.t); d > 0 && d < best {
+					passed = append(passed, k)
+				}
+			}
+			sort.Slice(passed, func(a, b int) bool { return dist(te, corners[passed[a]].t) < dist(te, corners[passed[b]].t) })
+			for _, k := range passed {
+				ring = append(ring, corners[k].p)
+			}
+			if next == start {
+				break
+			}
+			cur = next
+		}
+		rings = append(rings, ring)
+	}
+	return rings
+}
+
+// outRings translates rings by dx and applies the output transformation to them in place,
+// reversing them if it reverses orientation.
+func (gm *GeneralizedMercator) outRings(rings [][]r2.Point, dx float64) [][]r2.Point {
+	reverse := gm.out.a*gm.out.d-gm.out.b*gm.out.c < 0
+	for _, ring := range rings {
+		for n, p := range ring {
+			ring[n] = gm.fromCentered(r2.Point{X: p.X + dx, Y: p.Y})
+		}
+		if reverse {
+			for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+				ring[i], ring[j] = ring[j], ring[i]
+			}
+		}
+	}
+	return rings
+}
+
+// RingsContainPoint reports whether the projected point p lies in the region bounded by rings, as returned
+// by ProjectLoop or ProjectPolygon with the same maxY. Points are first translated by whole periods into the map,
+// so that a point beyond an edge of the map is tested against the parts of the region at the opposite edge,
+// and points beyond the strip |y| <= maxY are tested at its top or bottom edge, where a region that contains
+// a pole extends. The test therefore agrees with the ContainsPoint method of the loop or polygon wherever
+// the rings follow its projected edges, except on its boundary.
+func (gm *GeneralizedMercator) RingsContainPoint(rings [][]r2.Point, maxY float64, p r2.Point) bool {
+	e := math.Pi * gm.radius * gm.k0
+	// nudge keeps the point strictly inside the strip so that it is not on the parts of rings along its boundary.
+	nudge := 1e-9 * math.Min(e, maxY)
+	q := gm.out.invert(p)
+	q.X -= gm.cut * gm.radius * gm.k0
+	q.X = math.Remainder(q.X, 2*e)
+	q.X = math.Max(-e+nudge, math.Min(q.X, e-nudge))
+	q.Y = math.Max(-maxY+nudge, math.Min(q.Y, maxY-nudge))
+	var w int
+	for _, ring := range rings {
+		for n := range ring {
+			a := gm.out.invert(ring[n])
+			b := gm.out.invert(ring[(n+1)%len(ring)])
+			a.X -= gm.cut * gm.radius * gm.k0
+			b.X -= gm.cut * gm.radius * gm.k0
+			cross := b.Sub(a).Cross(q.Sub(a))
+			switch {
+			case a.Y <= q.Y && b.Y > q.Y && cross > 0:
+				w++
+			case a.Y > q.Y && b.Y <= q.Y && cross < 0:
+				w--
+			}
+		}
+	}
+	return w != 0
+}
@@ -0,0 +1,276 @@
+package gm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// loop returns the loop with vertices at the given latitudes and longitudes in degrees.
+func loop(lls ...[2]float64) *s2.Loop {
+	return s2.LoopFromPoints(polyline(lls...))
+}
+
+// rectLoop returns a loop with short edges that approximates the boundary of the rectangle of latitudes and longitudes
+// from lat0, lng0 to lat1, lng1 in degrees, counterclockwise, so that its projected edges are nearly straight.
+func rectLoop(lat0, lng0, lat1, lng1 float64) *s2.Loop {
+	var lls [][2]float64
+	for lng := lng0; lng < lng1; lng += 2 {
+		lls = append(lls, [2]float64{lat0, lng})
+	}
+	for lat := lat0; lat < lat1; lat += 2 {
+		lls = append(lls, [2]float64{lat, lng1})
+	}
+	for lng := lng1; lng > lng0; lng -= 2 {
+		lls = append(lls, [2]float64{lat1, lng})
+	}
+	for lat := lat1; lat > lat0; lat -= 2 {
+		lls = append(lls, [2]float64{lat, lng0})
+	}
+	return loop(lls...)
+}
+
+// inverted inverts l in place and returns it.
+func inverted(l *s2.Loop) *s2.Loop {
+	l.Invert()
+	return l
+}
+
+// nearBoundary reports whether p is within d of the boundary of l.
+func nearBoundary(l *s2.Loop, p s2.Point, d s1.Angle) bool {
+	for n := 0; n < l.NumVertices(); n++ {
+		if s2.DistanceFromSegment(p, l.Vertex(n), l.Vertex(n+1)) < d {
+			return true
+		}
+	}
+	return false
+}
+
+// winding returns the sum of the winding numbers of rings around p.
+func winding(rings [][]r2.Point, p r2.Point) int {
+	var w int
+	for _, ring := range rings {
+		for n := range ring {
+			a, b := ring[n], ring[(n+1)%len(ring)]
+			cross := (b.X-a.X)*(p.Y-a.Y) - (p.X-a.X)*(b.Y-a.Y)
+			switch {
+			case a.Y <= p.Y && b.Y > p.Y && cross > 0:
+				w++
+			case a.Y > p.Y && b.Y <= p.Y && cross < 0:
+				w--
+			}
+		}
+	}
+	return w
+}
+
+// signedArea returns the signed area of ring, positive if it is counterclockwise.
+func signedArea(ring []r2.Point) float64 {
+	var a float64
+	for n := range ring {
+		p, q := ring[n], ring[(n+1)%len(ring)]
+		a += p.X*q.Y - q.X*p.Y
+	}
+	return a / 2
+}
+
+func TestProjectLoop(t *testing.T) {
+	const maxY = 3
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	deg := func(d float64) float64 { return d * pi / 180 }
+	north, south := r2.Point{Y: 2.9}, r2.Point{Y: -2.9}
+	for _, test := range []struct {
+		name   string
+		gm     *GeneralizedMercator
+		l      *s2.Loop
+		rings  int
+		in     []r2.Point
+		out    []r2.Point
+		ccwAll bool
+	}{
+		{
+			"small", mercator, loop([2]float64{0, 0}, [2]float64{0, 10}, [2]float64{10, 10}, [2]float64{10, 0}),
+			1, []r2.Point{{X: deg(5), Y: 0.05}}, []r2.Point{{X: deg(15), Y: 0.05}, north, south}, true,
+		},
+		{
+			"across the seam", mercator, loop([2]float64{-10, 170}, [2]float64{-10, -170}, [2]float64{10, -170}, [2]float64{10, 170}),
+			2, []r2.Point{{X: deg(175)}, {X: deg(-175)}}, []r2.Point{{}, north, south}, true,
+		},
+		{
+			"around the north pole", mercator, loop([2]float64{60, 45}, [2]float64{60, 135}, [2]float64{60, -135}, [2]float64{60, -45}),
+			1, []r2.Point{north, {X: deg(179.9), Y: 2.5}}, []r2.Point{{}, south}, true,
+		},
+		{
+			"around the south pole", mercator, inverted(loop([2]float64{60, 45}, [2]float64{60, 135}, [2]float64{60, -135}, [2]float64{60, -45})),
+			1, []r2.Point{{}, south, {X: deg(-179.9)}}, []r2.Point{north}, true,
+		},
+		{
+			"around the seam", mercator, inverted(loop([2]float64{0, 0}, [2]float64{0, 10}, [2]float64{10, 10}, [2]float64{10, 0})),
+			2, []r2.Point{{X: deg(15), Y: 0.05}, north, south}, []r2.Point{{X: deg(5), Y: 0.05}}, false,
+		},
+		{
+			"vertex on the seam", mercator, loop([2]float64{-10, 170}, [2]float64{0, 180}, [2]float64{-10, -170}, [2]float64{10, -170}, [2]float64{10, 170}),
+			2, []r2.Point{{X: deg(175)}, {X: deg(-175)}}, []r2.Point{{}, north, south}, true,
+		},
+		{
+			"vertex at the pole", mercator, loop([2]float64{60, 0}, [2]float64{60, 120}, [2]float64{90, 0}),
+			1, []r2.Point{{X: deg(60), Y: 2.5}}, []r2.Point{{X: deg(-60), Y: 2.5}, {}}, true,
+		},
+		{
+			"full", mercator, s2.FullLoop(),
+			1, []r2.Point{{}, north, south}, nil, true,
+		},
+		{
+			"reflected", New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithAffine(-1, 0, 0, 1, 0, 0)),
+			loop([2]float64{60, 45}, [2]float64{60, 135}, [2]float64{60, -135}, [2]float64{60, -45}),
+			1, []r2.Point{north}, []r2.Point{{}, south}, true,
+		},
+	} {
+		got := test.gm.ProjectLoop(test.l, maxY)
+		if len(got) != test.rings {
+			t.Errorf("ProjectLoop(%v, %s): got %d rings, want %d: %v", test.gm, test.name, len(got), test.rings, got)
+			continue
+		}
+		for _, p := range test.in {
+			if w := winding(got, p); w != 1 {
+				t.Errorf("ProjectLoop(%v, %s): got winding number %d around %v, want 1", test.gm, test.name, w, p)
+			}
+		}
+		for _, p := range test.out {
+			if w := winding(got, p); w != 0 {
+				t.Errorf("ProjectLoop(%v, %s): got winding number %d around %v, want 0", test.gm, test.name, w, p)
+			}
+		}
+		if test.ccwAll {
+			for _, ring := range got {
+				if signedArea(ring) <= 0 {
+					t.Errorf("ProjectLoop(%v, %s): got clockwise ring %v", test.gm, test.name, ring)
+				}
+			}
+		}
+	}
+	if got := mercator.ProjectLoop(s2.EmptyLoop(), maxY); got != nil {
+		t.Errorf("ProjectLoop(%v, empty): got %v, want nil", mercator, got)
+	}
+
+	// In every projection, projected loops contain the projections of the points that the loops contain.
+	for _, test := range projTests {
+		gm := New(test.gm.Poles())
+		for _, l := range []*s2.Loop{
+			rectLoop(-30, 100, 30, 260),
+			inverted(rectLoop(-30, 100, 30, 260)),
+			rectLoop(20, 20, 40, 40),
+			rectLoop(50, 170, 70, 200),
+			rectLoop(-85, -60, -20, 60),
+		} {
+			rings := gm.ProjectLoop(l, 10)
+			for lat := -80.0; lat <= 80; lat += 7 {
+				for lng := -175.0; lng < 180; lng += 11 {
+					ll := s2.LatLngFromDegrees(lat, lng)
+					p := gm.Project(ll)
+					if math.Abs(p.Y) > 9 || math.Abs(math.Abs(p.X)-pi) < 0.05 {
+						continue
+					}
+					// Skip points near the loop's boundary, where the projected edges are only approximate.
+					if nearBoundary(l, s2.PointFromLatLng(ll), 3*s1.Degree) {
+						continue
+					}
+					want := 0
+					if l.ContainsPoint(s2.PointFromLatLng(ll)) {
+						want = 1
+					}
+					if w := winding(rings, p); w != want {
+						t.Errorf("ProjectLoop(%v, %v): got winding number %d around %v (%v), want %d", gm, l, w, p, ll, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestProjectPolygon(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	shell := loop([2]float64{-40, 150}, [2]float64{-40, -150}, [2]float64{40, -150}, [2]float64{40, 150})
+	hole := loop([2]float64{-10, 170}, [2]float64{-10, -170}, [2]float64{10, -170}, [2]float64{10, 170})
+	p := s2.PolygonFromLoops([]*s2.Loop{shell, hole})
+	got := gm.ProjectPolygon(p, 3)
+	deg := func(d float64) float64 { return d * pi / 180 }
+	for _, test := range []struct {
+		p    r2.Point
+		want int
+	}{
+		{r2.Point{X: deg(160)}, 1},
+		{r2.Point{X: deg(-160)}, 1},
+		{r2.Point{X: deg(175)}, 0},
+		{r2.Point{X: deg(-175)}, 0},
+		{r2.Point{}, 0},
+		{r2.Point{X: deg(160), Y: 2.5}, 0},
+	} {
+		if w := winding(got, test.p); w != test.want {
+			t.Errorf("ProjectPolygon(%v, %v): got winding number %d around %v, want %d", gm, p, w, test.p, test.want)
+		}
+	}
+	if got := gm.ProjectPolygon(&s2.Polygon{}, 3); got != nil {
+		t.Errorf("ProjectPolygon(%v, empty): got %v, want nil", gm, got)
+	}
+}
+
+func TestRingsContainPoint(t *testing.T) {
+	const maxY = 3
+	rnd := rand.New(rand.NewSource(1))
+	for _, gm := range []*GeneralizedMercator{
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeam(2), WithAffine(0, -2, 2, 0, 5, 1)),
+		New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150), WithAffine(-1, 0, 0, 1, 0, 0)),
+	} {
+		for _, p := range []*s2.Polygon{
+			s2.PolygonFromLoops([]*s2.Loop{rectLoop(-10, 170, 10, 190)}),
+			s2.PolygonFromLoops([]*s2.Loop{rectLoop(-20, -40, 30, 60), rectLoop(0, 0, 10, 10)}),
+			s2.PolygonFromLoops([]*s2.Loop{inverted(rectLoop(-30, -30, 30, 30))}),
+			s2.PolygonFromLoops([]*s2.Loop{rectLoop(60, -180, 88, 180)}),
+		} {
+			rings := gm.ProjectPolygon(p, maxY)
+			for n := 0; n < 500; n++ {
+				pt := s2.Point{randomPoint(rnd).Normalize()}
+				q := gm.ProjectPoint(pt)
+				if y := gm.out.invert(q).Y; math.Abs(y) >= maxY {
+					continue
+				}
+				near := false
+				for _, l := range p.Loops() {
+					near = near || nearBoundary(l, pt, 0.05)
+				}
+				if near {
+					continue
+				}
+				want := p.ContainsPoint(pt)
+				for _, r := range []r2.Point{q, q.Add(gm.WrapDistance()), q.Sub(gm.WrapDistance().Mul(2))} {
+					if got := gm.RingsContainPoint(rings, maxY, r); got != want {
+						t.Errorf("RingsContainPoint(%v, %v, %v): got %v, want %v", gm, p, r, got, want)
+					}
+				}
+			}
+		}
+	}
+
+	// Points beyond the strip are in a region that contains the pole.
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	rings := gm.ProjectLoop(s2.LoopFromCell(s2.CellFromCellID(s2.CellIDFromFace(2))), maxY)
+	for _, test := range []struct {
+		p    r2.Point
+		want bool
+	}{
+		{r2.Point{X: 1, Y: 10}, true},
+		{r2.Point{X: 1, Y: math.Inf(1)}, true},
+		{r2.Point{X: 1, Y: -10}, false},
+		{r2.Point{X: 1 + 2*pi, Y: 2}, true},
+	} {
+		if got := gm.RingsContainPoint(rings, maxY, test.p); got != test.want {
+			t.Errorf("RingsContainPoint(%v, %v): got %v, want %v", rings, test.p, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package gm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// errNotConformal is returned when a projection whose poles are not antipodes has no PROJ equivalent.
+var errNotConformal = errors.New("gm: poles are not antipodes; no equivalent PROJ projection")
+
+// ProjString returns a PROJ definition of an equivalent projection of the sphere of radius gm.Radius().
+// If the poles are the North and South Poles, the definition uses the Mercator projection (+proj=merc),
+// reflected by +axis=wsu if the positive pole is the South Pole. Otherwise, if the poles are antipodes,
+// it uses the Hotine oblique Mercator projection (+proj=omerc) centered on the point that projects to the origin,
+// with the central line running toward increasing x (+alpha) and no rectification to north (+gamma=90).
+// ProjString returns an error if the poles are not antipodes, if latitudes are geodetic,
+// if the output transformation is not a translation, if WithSeam has moved the seam, if WithSeamSide
+// has fixed the edge to which the seam projects, or if the projection is truncated by WithTruncation,
+// since none of these cases has an equivalent spherical projection in PROJ.
+func (gm *GeneralizedMercator) ProjString() (string, error) {
+	if !math.IsInf(gm.d, 1) {
+		return "", errNotConformal
+	}
+	if gm.flattening != 0 {
+		return "", errors.New("gm: geodetic latitudes have no equivalent spherical PROJ projection")
+	}
+	if !gm.isTranslation() {
+		return "", errLinearTransform
+	}
+	if gm.cut != 0 {
+		return "", errSeam
+	}
+	if gm.side != SeamAsComputed {
+		return "", errSeamSide
+	}
+	if gm.psiMax != 0 {
+		return "", errTruncated
+	}
+	var b strings.Builder
+	if gm.IsNormalMercator() {
+		b.WriteString("+proj=merc")
+		writeParam(&b, "lon_0", gm.centralMeridian())
+		if gm.k.Z < 0 {
+			b.WriteString(" +axis=wsu")
+		}
+	} else {
+		lat0, lonc, alpha := gm.obliqueCenter()
+		b.WriteString("+proj=omerc")
+		writeParam(&b, "lat_0", lat0)
+		writeParam(&b, "lonc", lonc)
+		writeParam(&b, "alpha", alpha)
+		writeParam(&b, "gamma", 90)
+	}
+	writeParam(&b, "k_0", gm.k0)
+	x0, y0 := gm.falseOrigin()
+	writeParam(&b, "x_0", x0)
+	writeParam(&b, "y_0", y0)
+	writeParam(&b, "R", gm.radius)
+	return b.String(), nil
+}
+
+// errLinearTransform is returned when a projection with a linear output transformation has no PROJ equivalent.
+var errLinearTransform = errors.New("gm: output transformation is not a translation; no equivalent PROJ projection")
+
+// errSeam is returned when a projection whose seam is not opposite the central line has no PROJ equivalent.
+var errSeam = errors.New("gm: seam is not opposite the central line; no equivalent PROJ projection")
+
+// errSeamSide is returned when a projection that places the seam on a chosen edge of the map has no PROJ equivalent.
+var errSeamSide = errors.New("gm: seam side is fixed; no equivalent PROJ projection")
+
+// errTruncated is returned when a truncated projection has no PROJ equivalent.
+var errTruncated = errors.New("gm: projection is truncated; no equivalent PROJ projection")
+
+// isTranslation reports whether the output transformation of gm is a translation.
+func (gm *GeneralizedMercator) isTranslation() bool {
+	t := gm.out
+	return t.a == 1 && t.b == 0 && t.c == 0 && t.d == 1
+}
+
+// falseOrigin returns the false easting and northing of an equivalent PROJ or EPSG projection,
+// in which the false origin is added before the axes are reflected if the positive pole is the South Pole.
+func (gm *GeneralizedMercator) falseOrigin() (x0, y0 float64) {
+	if gm.IsNormalMercator() && gm.k.Z < 0 {
+		return -gm.out.e, -gm.out.f
+	}
+	return gm.out.e, gm.out.f
+}
+
+// centralMeridian returns the longitude in degrees of the meridian that projects to x = 0
+// if the poles are the North and South Poles.
+func (gm *GeneralizedMercator) centralMeridian() float64 {
+	lon0 := gm.x0
+	if gm.k.Z < 0 {
+		// x increases westward from the meridian of longitude -x0.
+		lon0 = -lon0
+	}
+	return math.Remainder(lon0, 2*math.Pi) * 180 / math.Pi
+}
+
+// obliqueCenter returns the latitude and longitude in degrees of the point that projects to the origin
+// and the azimuth in degrees, clockwise from north, of the direction of increasing x at that point.
+func (gm *GeneralizedMercator) obliqueCenter() (lat, lng, azimuth float64) {
+	var (
+		C = gm.fromGeneralized(gm.x0, 0)
+		// T is the direction of increasing x at C.
+		T  = gm.k.Cross(C)
+		ll = s2.LatLngFromPoint(s2.Point{C})
+
+		sinLat, cosLat = math.Sincos(float64(ll.Lat))
+		sinLng, cosLng = math.Sincos(float64(ll.Lng))
+		east           = r3.Vector{X: -sinLng, Y: cosLng}
+		north          = r3.Vector{X: -sinLat * cosLng, Y: -sinLat * sinLng, Z: cosLat}
+	)
+	return ll.Lat.Degrees(), ll.Lng.Degrees(), math.Atan2(T.Dot(east), T.Dot(north)) * 180 / math.Pi
+}
+
+// formatParam formats a parameter value, rounded to 12 decimal places
+// to suppress floating-point noise from the basis computations.
+func formatParam(v float64) string {
+	// Adding zero normalizes negative zero.
+	return strconv.FormatFloat(math.Round(v*1e12)/1e12+0, 'f', -1, 64)
+}
+
+// writeParam writes the PROJ parameter +key=v to b, preceded by a space.
+func writeParam(b *strings.Builder, key string, v float64) {
+	b.WriteString(" +")
+	b.WriteString(key)
+	b.WriteByte('=')
+	b.WriteString(formatParam(v))
+}
+
+// ParseProj returns the projection described by a PROJ definition of the Mercator (+proj=merc)
+// or Hotine oblique Mercator (+proj=omerc) projection of a sphere, such as those produced by ProjString.
+// The sphere's radius must be given by +R, or by +a with an equal +b if any.
+// An omerc definition must specify +gamma=90, or omit +gamma with +alpha=90, so that the output is not rectified.
+// ParseProj returns an error for any other projection, for an ellipsoid, and for any unsupported parameter or value.
+func ParseProj(s string) (*GeneralizedMercator, error) {
+	var (
+		vals  = make(map[string]float64)
+		flags = make(map[string]string)
+	)
+	for _, f := range strings.Fields(s) {
+		if !strings.HasPrefix(f, "+") {
+			return nil, fmt.Errorf("gm: parsing %q: malformed parameter %q", s, f)
+		}
+		key, val, hasVal := strings.Cut(f[1:], "=")
+		if _, ok := flags[key]; ok {
+			return nil, fmt.Errorf("gm: parsing %q: duplicate parameter +%s", s, key)
+		}
+		flags[key] = val
+		switch key {
+		case "proj", "axis", "units", "type":
+		case "no_defs", "wktext":
+			if hasVal {
+				return nil, fmt.Errorf("gm: parsing %q: unexpected value for +%s", s, key)
+			}
+		case "lat_0", "lon_0", "lonc", "alpha", "gamma", "k_0", "k", "x_0", "y_0", "R", "a", "b":
+			v, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return nil, fmt.Errorf("gm: parsing %q: +%s: %v", s, key, err)
+			}
+			vals[key] = v
+		default:
+			return nil, fmt.Errorf("gm: parsing %q: unsupported parameter +%s", s, key)
+		}
+	}
+
+	if u, ok := flags["units"]; ok && u != "m" {
+		return nil, fmt.Errorf("gm: parsing %q: unsupported units %q", s, u)
+	}
+	if t, ok := flags["type"]; ok && t != "crs" {
+		return nil, fmt.Errorf("gm: parsing %q: unsupported type %q", s, t)
+	}
+	k0 := 1.0
+	for _, key := range []string{"k", "k_0"} {
+		if v, ok := vals[key]; ok {
+			k0 = v
+		}
+	}
+
+	r, ok := vals["R"]
+	if !ok {
+		a, okA := vals["a"]
+		if b, okB := vals["b"]; !okA || okB && b != a {
+			return nil, fmt.Errorf("gm: parsing %q: want a sphere specified by +R or +a", s)
+		}
+		r = a
+	}
+	opts := []Option{WithRadius(r), WithScaleFactor(k0)}
+	deg := func(key string) float64 { return vals[key] * math.Pi / 180 }
+
+	var gm *GeneralizedMercator
+	var err error
+	switch proj := flags["proj"]; proj {
+	case "merc":
+		for _, key := range []string{"lat_0", "lonc", "alpha", "gamma"} {
+			if _, ok := vals[key]; ok {
+				return nil, fmt.Errorf("gm: parsing %q: unsupported parameter +%s for merc", s, key)
+			}
+		}
+		north, south := s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)
+		switch axis := flags["axis"]; axis {
+		case "", "enu":
+			opts = append(opts, WithCentralLongitude(deg("lon_0")), WithFalseOrigin(vals["x_0"], vals["y_0"]))
+			gm, err = newFromLatLngs(north, south, opts...)
+		case "wsu":
+			opts = append(opts, WithCentralLongitude(-deg("lon_0")), WithFalseOrigin(-vals["x_0"], -vals["y_0"]))
+			gm, err = newFromLatLngs(south, north, opts...)
+		default:
+			return nil, fmt.Errorf("gm: parsing %q: unsupported axis %q", s, axis)
+		}
+	case "omerc":
+		if _, ok := vals["lon_0"]; ok {
+			return nil, fmt.Errorf("gm: parsing %q: unsupported parameter +lon_0 for omerc", s)
+		}
+		if axis := flags["axis"]; axis != "" && axis != "enu" {
+			return nil, fmt.Errorf("gm: parsing %q: unsupported axis %q", s, axis)
+		}
+		alpha, okAlpha := vals["alpha"]
+		if !okAlpha {
+			return nil, fmt.Errorf("gm: parsing %q: missing +alpha", s)
+		}
+		if gamma, ok := vals["gamma"]; ok && gamma != 90 || !ok && alpha != 90 {
+			return nil, fmt.Errorf("gm: parsing %q: unsupported rectified grid; want +gamma=90", s)
+		}
+		center := s2.LatLngFromDegrees(vals["lat_0"], vals["lonc"])
+		if !center.IsValid() {
+			return nil, fmt.Errorf("gm: parsing %q: invalid center %v", s, center)
+		}
+		opts = append(opts, WithFalseOrigin(vals["x_0"], vals["y_0"]))
+		gm, err = newCentered(center, s1.Angle(deg("alpha")), opts...)
+	default:
+		return nil, fmt.Errorf("gm: parsing %q: unsupported projection %q", s, proj)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gm: parsing %q: %v", s, err)
+	}
+	return gm, nil
+}
+
+// WKT returns an OGC Well-Known Text (WKT2:2019) description of an equivalent projected coordinate reference system
+// on a sphere of radius gm.Radius() metres, using the same methods and parameters as ProjString:
+// Mercator (variant A) if the poles are the North and South Poles, and otherwise Hotine Oblique Mercator (variant B)
+// with an angle of 90° from the rectified to the skew grid, so that the axes are those of the unrectified projection.
+// WKT returns an error in the same cases as ProjString.
+func (gm *GeneralizedMercator) WKT() (string, error) {
+	if !math.IsInf(gm.d, 1) {
+		return "", errNotConformal
+	}
+	if gm.flattening != 0 {
+		return "", errors.New("gm: geodetic latitudes have no equivalent spherical coordinate reference system")
+	}
+	if !gm.isTranslation() {
+		return "", errLinearTransform
+	}
+	if gm.cut != 0 {
+		return "", errSeam
+	}
+	if gm.side != SeamAsComputed {
+		return "", errSeamSide
+	}
+	if gm.psiMax != 0 {
+		return "", errTruncated
+	}
+	x0, y0 := gm.falseOrigin()
+	const (
+		degree = `ANGLEUNIT["degree",0.0174532925199433]`
+		metre  = `LENGTHUNIT["metre",1]`
+		unity  = `SCALEUNIT["unity",1]`
+	)
+	param := func(name string, v float64, unit string, id int) string {
+		return fmt.Sprintf(`PARAMETER["%s",%s,%s,ID["EPSG",%d]]`, name, formatParam(v), unit, id)
+	}
+	var method string
+	var ps []string
+	var axes [2]string
+	if gm.IsNormalMercator() {
+		method = `METHOD["Mercator (variant A)",ID["EPSG",9804]]`
+		ps = []string{
+			param("Latitude of natural origin", 0, degree, 8801),
+			param("Longitude of natural origin", gm.centralMeridian(), degree, 8802),
+			param("Scale factor at natural origin", gm.k0, unity, 8805),
+			param("False easting", x0, metre, 8806),
+			param("False northing", y0, metre, 8807),
+		}
+		axes = [2]string{`"easting (E)",east`, `"northing (N)",north`}
+		if gm.k.Z < 0 {
+			axes = [2]string{`"westing (W)",west`, `"southing (S)",south`}
+		}
+	} else {
+		lat0, lonc, alpha := gm.obliqueCenter()
+		method = `METHOD["Hotine Oblique Mercator (variant B)",ID["EPSG",9815]]`
+		ps = []string{
+			param("Latitude of projection centre", lat0, degree, 8811),
+			param("Longitude of projection centre", lonc, degree, 8812),
+			param("Azimuth of initial line", alpha, degree, 8813),
+			param("Angle from Rectified to Skew Grid", 90, degree, 8814),
+			param("Scale factor on initial line", gm.k0, unity, 8815),
+			param("Easting at projection centre", x0, metre, 8816),
+			param("Northing at projection centre", y0, metre, 8817),
+		}
+		axes = [2]string{`"(X)",unspecified`, `"(Y)",unspecified`}
+	}
+	return fmt.Sprintf(`PROJCRS["Generalized Mercator",`+
+		`BASEGEOGCRS["Unknown based on sphere",`+
+		`DATUM["Unknown based on sphere",ELLIPSOID["Sphere",%s,0,%s]],`+
+		`PRIMEM["Greenwich",0,%s]],`+
+		`CONVERSION["Generalized Mercator",%s,%s],`+
+		`CS[Cartesian,2],AXIS[%s,ORDER[1],%s],AXIS[%s,ORDER[2],%s]]`,
+		formatParam(gm.radius), metre, degree, method, strings.Join(ps, ","), axes[0], metre, axes[1], metre), nil
+}
@@ -0,0 +1,177 @@
+package gm
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestProjString(t *testing.T) {
+	for _, test := range []struct {
+		gm   *GeneralizedMercator
+		want string
+	}{
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+			"+proj=merc +lon_0=0 +k_0=1 +x_0=0 +y_0=0 +R=1",
+		},
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithRadius(6378137), WithCentralLongitude(pi/2)),
+			"+proj=merc +lon_0=90 +k_0=1 +x_0=0 +y_0=0 +R=6378137",
+		},
+		{
+			New(s2.LatLngFromDegrees(-90, 0), s2.LatLngFromDegrees(90, 0), WithCentralLongitude(pi/2)),
+			"+proj=merc +lon_0=-90 +axis=wsu +k_0=1 +x_0=0 +y_0=0 +R=1",
+		},
+		{
+			NewCentered(s2.LatLngFromDegrees(30, 60), 45*s1.Degree),
+			"+proj=omerc +lat_0=30 +lonc=60 +alpha=45 +gamma=90 +k_0=1 +x_0=0 +y_0=0 +R=1",
+		},
+		{
+			New(s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 180)),
+			"+proj=omerc +lat_0=90 +lonc=0 +alpha=-90 +gamma=90 +k_0=1 +x_0=0 +y_0=0 +R=1",
+		},
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithScaleFactor(0.9996)),
+			"+proj=merc +lon_0=0 +k_0=0.9996 +x_0=0 +y_0=0 +R=1",
+		},
+		{
+			New(s2.LatLngFromDegrees(-90, 0), s2.LatLngFromDegrees(90, 0), WithFalseOrigin(500000, -100)),
+			"+proj=merc +lon_0=0 +axis=wsu +k_0=1 +x_0=-500000 +y_0=100 +R=1",
+		},
+	} {
+		got, err := test.gm.ProjString()
+		if err != nil {
+			t.Errorf("ProjString(%v): %v", test.gm, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ProjString(%v): got %q, want %q", test.gm, got, test.want)
+		}
+	}
+	for _, gm := range []*GeneralizedMercator{
+		New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0)),
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithGeodeticLatitude(WGS84Flattening)),
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithAffine(0, 1, -1, 0, 0, 0)),
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeam(0)),
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeamSide(SeamPositive)),
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithTruncation(80*math.Pi/180)),
+		New(s2.LatLngFromDegrees(40, 10), s2.LatLngFromDegrees(-40, -170), WithTruncation(1)),
+	} {
+		if s, err := gm.ProjString(); err == nil {
+			t.Errorf("ProjString(%v): got %q, want error", gm, s)
+		}
+	}
+}
+
+func TestParseProj(t *testing.T) {
+	for _, gm := range []*GeneralizedMercator{
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithRadius(6378137), WithCentralLongitude(pi/2)),
+		New(s2.LatLngFromDegrees(-90, 0), s2.LatLngFromDegrees(90, 0), WithCentralLongitude(pi/2)),
+		NewCentered(s2.LatLngFromDegrees(30, 60), 45*s1.Degree),
+		NewCentered(s2.LatLngFromDegrees(-33.9, 151.2), -120*s1.Degree, WithRadius(6371000)),
+		NewCentered(s2.LatLngFromDegrees(45, -100), 30*s1.Degree, WithRadius(6371000), WithScaleFactor(0.9996)),
+		NewCentered(s2.LatLngFromDegrees(45, -100), 30*s1.Degree, WithRadius(6371000), WithFalseOrigin(500000, 200000)),
+		New(s2.LatLngFromDegrees(-90, 0), s2.LatLngFromDegrees(90, 0), WithRadius(6371000), WithFalseOrigin(500000, 200000)),
+	} {
+		s, err := gm.ProjString()
+		if err != nil {
+			t.Fatalf("ProjString(%v): %v", gm, err)
+		}
+		got, err := ParseProj(s)
+		if err != nil {
+			t.Errorf("ParseProj(%q): %v", s, err)
+			continue
+		}
+		if got.pos.Angle(gm.pos) > 1e-9 || got.neg.Angle(gm.neg) > 1e-9 || math.Abs(got.x0-gm.x0) > 1e-9 || got.radius != gm.radius || got.k0 != gm.k0 || got.out != gm.out {
+			t.Errorf("ParseProj(%q): got %v, want %v", s, got, gm)
+		}
+		for _, ll := range []s2.LatLng{s2.LatLngFromDegrees(10, 20), s2.LatLngFromDegrees(-40, 100)} {
+			if p, q := got.Project(ll), gm.Project(ll); p.Sub(q).Norm() > 1e-9*gm.radius {
+				t.Errorf("ParseProj(%q).Project(%v): got %v, want %v", s, ll, p, q)
+			}
+		}
+	}
+
+	want := NewCentered(s2.LatLngFromDegrees(0, 10), 90*s1.Degree, WithRadius(2))
+	if got, err := ParseProj("+proj=omerc +lat_0=0 +lonc=10 +alpha=90 +R=2 +units=m +no_defs +type=crs"); err != nil {
+		t.Errorf("ParseProj: %v", err)
+	} else if p, q := got.Project(s2.LatLngFromDegrees(20, 30)), want.Project(s2.LatLngFromDegrees(20, 30)); p.Sub(q).Norm() > 1e-12 {
+		t.Errorf("ParseProj: got %v, want %v", got, want)
+	}
+
+	for _, s := range []string{
+		"",
+		"+proj=tmerc +lat_0=0 +lon_0=0 +R=1",
+		"+proj=merc +lon_0=0",
+		"+proj=merc +lon_0=0 +ellps=WGS84",
+		"+proj=merc +lon_0=0 +a=6378137 +b=6356752.3",
+		"+proj=merc +lon_0=0 +R=1 +k_0=0",
+		"+proj=merc +lon_0=0 +R=1 +lat_ts=30",
+		"+proj=merc +lon_0=x +R=1",
+		"+proj=merc +lon_0=0 +lon_0=1 +R=1",
+		"+proj=merc +lon_0=0 +R=1 +axis=neu",
+		"proj=merc +lon_0=0 +R=1",
+		"+proj=omerc +lat_0=30 +lonc=60 +alpha=45 +R=1",
+		"+proj=omerc +lat_0=30 +lonc=60 +alpha=45 +gamma=0 +R=1",
+		"+proj=omerc +lat_0=30 +lonc=60 +gamma=90 +R=1",
+		"+proj=omerc +lat_0=30 +lonc=60 +alpha=45 +gamma=90 +R=1 +no_uoff",
+	} {
+		if gm, err := ParseProj(s); err == nil {
+			t.Errorf("ParseProj(%q): got %v, want error", s, gm)
+		}
+	}
+}
+
+func TestWKT(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithRadius(6378137), WithCentralLongitude(pi/2))
+	want := `PROJCRS["Generalized Mercator",` +
+		`BASEGEOGCRS["Unknown based on sphere",` +
+		`DATUM["Unknown based on sphere",ELLIPSOID["Sphere",6378137,0,LENGTHUNIT["metre",1]]],` +
+		`PRIMEM["Greenwich",0,ANGLEUNIT["degree",0.0174532925199433]]],` +
+		`CONVERSION["Generalized Mercator",METHOD["Mercator (variant A)",ID["EPSG",9804]],` +
+		`PARAMETER["Latitude of natural origin",0,ANGLEUNIT["degree",0.0174532925199433],ID["EPSG",8801]],` +
+		`PARAMETER["Longitude of natural origin",90,ANGLEUNIT["degree",0.0174532925199433],ID["EPSG",8802]],` +
+		`PARAMETER["Scale factor at natural origin",1,SCALEUNIT["unity",1],ID["EPSG",8805]],` +
+		`PARAMETER["False easting",0,LENGTHUNIT["metre",1],ID["EPSG",8806]],` +
+		`PARAMETER["False northing",0,LENGTHUNIT["metre",1],ID["EPSG",8807]]],` +
+		`CS[Cartesian,2],AXIS["easting (E)",east,ORDER[1],LENGTHUNIT["metre",1]],AXIS["northing (N)",north,ORDER[2],LENGTHUNIT["metre",1]]]`
+	if got, err := gm.WKT(); err != nil || got != want {
+		t.Errorf("WKT(%v): got %q, %v, want %q", gm, got, err, want)
+	}
+
+	gm = NewCentered(s2.LatLngFromDegrees(30, 60), 45*s1.Degree)
+	got, err := gm.WKT()
+	if err != nil {
+		t.Fatalf("WKT(%v): %v", gm, err)
+	}
+	for _, sub := range []string{
+		`METHOD["Hotine Oblique Mercator (variant B)",ID["EPSG",9815]]`,
+		`PARAMETER["Latitude of projection centre",30,`,
+		`PARAMETER["Longitude of projection centre",60,`,
+		`PARAMETER["Azimuth of initial line",45,`,
+		`PARAMETER["Angle from Rectified to Skew Grid",90,`,
+	} {
+		if !strings.Contains(got, sub) {
+			t.Errorf("WKT(%v): got %q, want it to contain %q", gm, got, sub)
+		}
+	}
+	if strings.Count(got, "[") != strings.Count(got, "]") {
+		t.Errorf("WKT(%v): unbalanced brackets in %q", gm, got)
+	}
+
+	for _, gm := range []*GeneralizedMercator{
+		New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0)),
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeamSide(SeamNegative)),
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithTruncation(80*math.Pi/180)),
+		New(s2.LatLngFromDegrees(40, 10), s2.LatLngFromDegrees(-40, -170), WithTruncation(1)),
+	} {
+		if s, err := gm.WKT(); err == nil {
+			t.Errorf("WKT(%v): got %q, want error", gm, s)
+		}
+	}
+}
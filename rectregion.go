@@ -0,0 +1,243 @@
+package gm
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// rectTolerance is the angle on the reference sphere within which a ProjectedRect approximates the boundary
+// of its preimage when computing its bounds.
+const rectTolerance = 1e-7
+
+// A ProjectedRect is the region of the reference sphere that projects into a rectangle in the plane,
+// such as a viewport or a map tile. It implements s2.Region, so that it can be used in s2 queries.
+//
+// Since the projection is periodic, a location belongs to the region if any of its projections,
+// which differ by whole multiples of WrapDistance, lies in the rectangle. A rectangle that extends past
+// the edge of the map therefore includes locations on the other side of the seam.
+type ProjectedRect struct {
+	gm   *GeneralizedMercator
+	rect r2.Rect
+	cap  s2.Cap
+	ll   s2.Rect
+}
+
+// UnprojectRect returns the region of the reference sphere whose projection lies in rect.
+func (gm *GeneralizedMercator) UnprojectRect(rect r2.Rect) *ProjectedRect {
+	r := &ProjectedRect{gm: gm, rect: rect, cap: s2.EmptyCap(), ll: s2.EmptyRect()}
+	if rect.IsEmpty() {
+		return r
+	}
+
+	// The preimage is connected, so it is bounded by the boundary of its preimage except where it contains
+	// the points that the bounds exclude.
+	var boundary s2.Polyline
+	vertices := rect.Vertices()
+	for n := range vertices {
+		a, b := vertices[n], vertices[(n+1)%4]
+		line := gm.UnprojectSegment(a, b, rectTolerance)
+		boundary = append(boundary, line[:len(line)-1]...)
+	}
+	boundary = append(boundary, boundary[0])
+
+	bounder := s2.NewRectBounder()
+	for _, p := range boundary {
+		bounder.AddPoint(p)
+	}
+	r.ll = bounder.RectBound()
+	lat := math.Max(math.Abs(r.ll.Lat.Lo), math.Abs(r.ll.Lat.Hi)) + rectTolerance
+	lng := s1.FullInterval()
+	if lat < math.Pi/2 {
+		lng = r.ll.Lng.Expanded(rectTolerance / math.Cos(lat))
+	}
+	r.ll = s2.Rect{Lat: r.ll.Lat.Expanded(rectTolerance).Intersection(s2.FullRect().Lat), Lng: lng}
+	if north := s2.PointFromCoords(0, 0, 1); r.ContainsPoint(north) {
+		r.ll = s2.Rect{Lat: r.ll.Lat.AddPoint(math.Pi / 2), Lng: s1.FullInterval()}
+	}
+	if south := s2.PointFromCoords(0, 0, -1); r.ContainsPoint(south) {
+		r.ll = s2.Rect{Lat: r.ll.Lat.AddPoint(-math.Pi / 2), Lng: s1.FullInterval()}
+	}
+
+	// A cap around the preimage of the center of rect bounds the preimage unless the preimage contains its antipode.
+	r.cap = r.ll.CapBound()
+	center := gm.UnprojectPoint(rect.Center())
+	if !r.ContainsPoint(s2.Point{center.Mul(-1)}) {
+		c := s2.CapFromPoint(center)
+		for _, p := range boundary {
+			c = c.AddPoint(p)
+		}
+		if c = c.Expanded(rectTolerance); c.Radius() < r.cap.Radius() {
+			r.cap = c
+		}
+	}
+	return r
+}
+
+// Rect returns the rectangle in the plane whose preimage is r.
+func (r *ProjectedRect) Rect() r2.Rect { return r.rect }
+
+// ContainsPoint reports whether any projection of p lies in the rectangle.
+func (r *ProjectedRect) ContainsPoint(p s2.Point) bool {
+	if r.rect.IsEmpty() {
+		return false
+	}
+	q := r.gm.ProjectPoint(p)
+	lo, hi := r.gm.shifts(r.rect, r2.RectFromPoints(q))
+	for k := lo; k <= hi; k++ {
+		if r.rect.ContainsPoint(q.Add(r.gm.WrapDistance().Mul(float64(k)))) {
+			return true
+		}
+	}
+	return false
+}
+
+// IntersectsCell reports whether the region may intersect the cell c. It compares the rectangle with bounds of the
+// projection of c, so it may report true for cells near the rectangle that do not intersect the region.
+func (r *ProjectedRect) IntersectsCell(c s2.Cell) bool {
+	for _, b := range r.gm.generalizedRects(r.gm.capGeneralizedBound(c.CapBound())) {
+		lo, hi := r.gm.shifts(r.rect, b)
+		for k := lo; k <= hi; k++ {
+			if r.rect.Intersects(r.gm.shifted(b, k)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ContainsCell reports whether the region contains the cell c. It compares the rectangle with bounds of the
+// projection of c, so it may report false for cells near the edges of the rectangle that the region contains.
+func (r *ProjectedRect) ContainsCell(c s2.Cell) bool {
+	bounds := r.gm.generalizedRects(r.gm.capGeneralizedBound(c.CapBound()))
+	if len(bounds) == 0 {
+		return false
+	}
+next:
+	for _, b := range bounds {
+		lo, hi := r.gm.shifts(r.rect, b)
+		for k := lo; k <= hi; k++ {
+			if r.rect.Contains(r.gm.shifted(b, k)) {
+				continue next
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// CapBound returns a cap that contains the region.
+func (r *ProjectedRect) CapBound() s2.Cap { return r.cap }
+
+// RectBound returns a latitude-longitude rectangle that contains the region.
+func (r *ProjectedRect) RectBound() s2.Rect { return r.ll }
+
+// CellUnionBound returns cells whose union contains the region.
+func (r *ProjectedRect) CellUnionBound() []s2.CellID { return r.cap.CellUnionBound() }
+
+// RandomPoint returns a point chosen from the uniform distribution on r, using rnd as the source of randomness.
+// Unlike unprojecting points chosen uniformly in r.Rect(), which favors the parts of the region near the poles,
+// the probability of choosing a point from any part of the region is proportional to its true area.
+//
+// If the poles are antipodes and the output transformation does not rotate or shear the map, the point is
+// computed directly. Otherwise RandomPoint samples a cap that bounds the region until it finds a point
+// in the region, so it is slow if the region is much smaller than its bounding cap.
+// If the projection is truncated and the rectangle reaches the limit of the map, the region includes
+// the locations beyond the truncation latitude, which project onto the limit, and RandomPoint samples them too.
+// RandomPoint panics if r is empty.
+func (r *ProjectedRect) RandomPoint(rnd *rand.Rand) s2.Point {
+	gm, rect := r.gm, r.rect
+	if rect.IsEmpty() {
+		panic("gm: empty rect")
+	}
+	if math.IsInf(gm.d, 1) && gm.out.b == 0 && gm.out.c == 0 {
+		// The area of the sphere between the curves of constant generalized latitude ψ0 and ψ1 and of constant
+		// projective longitude x0 and x1 is (x1-x0)(sin ψ1 - sin ψ0), and sin ψ = tanh(y) at projected y,
+		// so x and tanh(y) are uniformly distributed.
+		s := gm.radius * gm.k0
+		t := gm.out
+		b := r2.RectFromPoints(
+			r2.Point{X: (rect.X.Lo - t.e) / (t.a * s), Y: (rect.Y.Lo - t.f) / (t.d * s)},
+			r2.Point{X: (rect.X.Hi - t.e) / (t.a * s), Y: (rect.Y.Hi - t.f) / (t.d * s)},
+		)
+		lo, hi := math.Tanh(b.Y.Lo), math.Tanh(b.Y.Hi)
+		if gm.psiMax != 0 {
+			// The locations beyond the truncation latitude project onto the limit of the map.
+			yMax := yFromPsi(gm.psiMax)
+			if b.Y.Lo > yMax || b.Y.Hi < -yMax {
+				panic("gm: empty rect")
+			}
+			if b.Y.Lo <= -yMax {
+				lo = -1
+			}
+			if b.Y.Hi >= yMax {
+				hi = 1
+			}
+		}
+		x := b.X.Lo + rnd.Float64()*math.Min(b.X.Length(), 2*math.Pi)
+		sin := lo + rnd.Float64()*(hi-lo)
+		return s2.Point{gm.fromGeneralized(x+gm.x0, math.Asin(sin))}
+	}
+
+	c := r.CapBound()
+	u := c.Center().Ortho()
+	v := c.Center().Cross(u)
+	for {
+		// The area of a cap is proportional to its height, so the height of the point above the base of c
+		// is uniformly distributed.
+		z := 1 - rnd.Float64()*c.Height()
+		sin, cos := math.Sincos(2 * math.Pi * rnd.Float64())
+		w := math.Sqrt(math.Max(0, 1-z*z))
+		p := s2.Point{c.Center().Mul(z).Add(u.Mul(cos * w)).Add(v.Mul(sin * w)).Normalize()}
+		if r.ContainsPoint(p) {
+			return p
+		}
+	}
+}
+
+// shifts returns the range of whole numbers of periods by which b can be translated to intersect a.
+func (gm *GeneralizedMercator) shifts(a, b r2.Rect) (lo, hi int) {
+	w := gm.WrapDistance()
+	along := func(r r2.Rect) (lo, hi float64) {
+		lo, hi = math.Inf(1), math.Inf(-1)
+		for _, v := range r.Vertices() {
+			var d float64
+			if w.X != 0 {
+				d += v.X * w.X
+			}
+			if w.Y != 0 {
+				d += v.Y * w.Y
+			}
+			lo, hi = math.Min(lo, d), math.Max(hi, d)
+		}
+		return lo, hi
+	}
+	alo, ahi := along(a)
+	blo, bhi := along(b)
+	klo, khi := math.Ceil((alo-bhi)/w.Dot(w)), math.Floor((ahi-blo)/w.Dot(w))
+	if math.IsInf(klo, 0) || math.IsInf(khi, 0) || math.IsNaN(klo) || math.IsNaN(khi) {
+		// b is unbounded along the period, so every translation is equivalent.
+		return 0, 0
+	}
+	return int(klo), int(khi)
+}
+
+// shifted returns r translated by k periods.
+func (gm *GeneralizedMercator) shifted(r r2.Rect, k int) r2.Rect {
+	d := gm.WrapDistance().Mul(float64(k))
+	return r2.RectFromPoints(r.Lo().Add(d), r.Hi().Add(d))
+}
+
+// RectCovering returns a union of cells that covers the region of the reference sphere whose projection lies in rect,
+// as computed by coverer, so that s2-indexed data in a viewport or map tile can be queried by cell ID.
+// If coverer is nil, RectCovering uses an s2.RegionCoverer with a limit of 8 cells. Since ProjectedRect.IntersectsCell
+// is conservative, the covering may include cells near the edges of the region that do not intersect it.
+func (gm *GeneralizedMercator) RectCovering(rect r2.Rect, coverer *s2.RegionCoverer) s2.CellUnion {
+	if coverer == nil {
+		coverer = &s2.RegionCoverer{MaxLevel: s2.MaxLevel, LevelMod: 1, MaxCells: 8}
+	}
+	return coverer.Covering(gm.UnprojectRect(rect))
+}
@@ -0,0 +1,176 @@
+package gm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s2"
+)
+
+func TestUnprojectRect(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{
+			New(pos, neg),
+			New(pos, neg, WithSeam(1), WithAffine(0, -2, 2, 0, 1, 3)),
+		} {
+			for _, rect := range []r2.Rect{
+				rect(-0.5, -0.3, 0.4, 0.6),
+				rect(2.5, -1, 4, 1),
+				rect(-7, 1.5, 7, 2.5),
+				rect(-1, -4, 1, 4),
+			} {
+				// Express the rectangle in the output coordinates.
+				rect = r2.RectFromPoints(gm.fromCentered(rect.Lo()), gm.fromCentered(rect.Hi()))
+				r := gm.UnprojectRect(rect)
+				if got := r.Rect(); got != rect {
+					t.Errorf("UnprojectRect(%v, %v).Rect(): got %v", gm, rect, got)
+				}
+				cells := s2.CellUnion(r.CellUnionBound())
+				cells.Normalize()
+				for n := 0; n < 500; n++ {
+					p := s2.Point{randomPoint(rnd).Normalize()}
+					in := false
+					q := gm.ProjectPoint(p)
+					for k := -3; k <= 3; k++ {
+						in = in || rect.ContainsPoint(q.Add(gm.WrapDistance().Mul(float64(k))))
+					}
+					if got := r.ContainsPoint(p); got != in {
+						t.Errorf("UnprojectRect(%v, %v).ContainsPoint(%v): got %v, want %v", gm, rect, q, got, in)
+					}
+					if !in {
+						continue
+					}
+					if !r.CapBound().ContainsPoint(p) {
+						t.Errorf("UnprojectRect(%v, %v).CapBound(): got %v, which does not contain %v", gm, rect, r.CapBound(), p)
+					}
+					if !r.RectBound().ContainsPoint(p) {
+						t.Errorf("UnprojectRect(%v, %v).RectBound(): got %v, which does not contain %v", gm, rect, r.RectBound(), s2.LatLngFromPoint(p))
+					}
+					if !cells.ContainsPoint(p) {
+						t.Errorf("UnprojectRect(%v, %v).CellUnionBound(): does not contain %v", gm, rect, s2.LatLngFromPoint(p))
+					}
+					c := s2.CellFromPoint(p)
+					c = s2.CellFromCellID(c.ID().Parent(4 + rnd.Intn(6)))
+					if !r.IntersectsCell(c) {
+						t.Errorf("UnprojectRect(%v, %v).IntersectsCell(%v): got false, want true", gm, rect, c.ID())
+					}
+				}
+				// Cells that the region contains consist of points that it contains.
+				for n := 0; n < 50; n++ {
+					c := s2.CellFromCellID(s2.CellFromPoint(s2.Point{randomPoint(rnd).Normalize()}).ID().Parent(6))
+					if !r.ContainsCell(c) {
+						continue
+					}
+					for m := 0; m < 4; m++ {
+						if !r.ContainsPoint(c.Vertex(m)) {
+							t.Errorf("UnprojectRect(%v, %v).ContainsCell(%v): got true, but vertex %d is outside", gm, rect, c.ID(), m)
+						}
+					}
+				}
+			}
+		}
+	}
+	// The region contains a cell well inside the rectangle.
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	r := gm.UnprojectRect(rect(-0.5, -0.5, 0.5, 0.5))
+	if c := s2.CellFromCellID(s2.CellIDFromLatLng(s2.LatLngFromDegrees(0, 0)).Parent(8)); !r.ContainsCell(c) {
+		t.Errorf("UnprojectRect(%v, %v).ContainsCell(%v): got false, want true", gm, r.Rect(), c.ID())
+	}
+	if r := gm.UnprojectRect(r2.EmptyRect()); r.ContainsPoint(s2.PointFromCoords(1, 0, 0)) || !r.CapBound().IsEmpty() {
+		t.Errorf("UnprojectRect(%v, empty): got %v", gm, r.CapBound())
+	}
+}
+
+func TestRectCovering(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg, WithRadius(2))
+		for _, rect := range []r2.Rect{
+			rect(-0.5, -0.3, 0.4, 0.6),
+			rect(5, -2, 8, 2),
+		} {
+			for _, coverer := range []*s2.RegionCoverer{nil, {MaxLevel: 12, LevelMod: 1, MaxCells: 20}} {
+				covering := gm.RectCovering(rect, coverer)
+				maxCells := 8
+				if coverer != nil {
+					maxCells = coverer.MaxCells
+				}
+				if len(covering) == 0 || len(covering) > maxCells {
+					t.Errorf("RectCovering(%v, %v, %v): got %d cells", gm, rect, coverer, len(covering))
+				}
+				r := gm.UnprojectRect(rect)
+				for n := 0; n < 300; n++ {
+					p := s2.Point{randomPoint(rnd).Normalize()}
+					if r.ContainsPoint(p) && !covering.ContainsPoint(p) {
+						t.Errorf("RectCovering(%v, %v, %v): does not contain %v", gm, rect, coverer, gm.ProjectPoint(p))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestProjectedRectRandomPoint(t *testing.T) {
+	const samples = 20000
+	rnd := rand.New(rand.NewSource(1))
+	for _, test := range []struct {
+		gm   *GeneralizedMercator
+		rect r2.Rect
+	}{
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), rect(0, 0, 1, 1)},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), rect(2.5, -1, 3.5, 2)},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithRadius(2), WithAffine(-1, 0, 0, 3, 1, 1)), rect(-2, -3, 1, 5)},
+		{New(s2.LatLngFromDegrees(30, 40), s2.LatLngFromDegrees(-30, -140), WithAffine(0, -1, 1, 0, 0, 0)), rect(0, 0, 1, 1)},
+		{New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150)), rect(-0.5, 0, 0.5, 1.5)},
+	} {
+		// The fraction of the samples in the lower half of the rectangle estimates the fraction of its true area there.
+		lower := test.rect
+		lower.Y.Hi = lower.Y.Center()
+		lv, v := lower.Vertices(), test.rect.Vertices()
+		want := test.gm.RingsArea([][]r2.Point{lv[:]}, 1e-8) / test.gm.RingsArea([][]r2.Point{v[:]}, 1e-8)
+		region := test.gm.UnprojectRect(test.rect)
+		lowerRegion := test.gm.UnprojectRect(lower)
+		var n int
+		for m := 0; m < samples; m++ {
+			p := region.RandomPoint(rnd)
+			if !region.ContainsPoint(p) {
+				t.Errorf("RandomPoint(%v, %v): got %v outside the region", test.gm, test.rect, p)
+				break
+			}
+			if lowerRegion.ContainsPoint(p) {
+				n++
+			}
+		}
+		// The standard deviation of the fraction is at most 0.5/sqrt(samples), about 0.0035.
+		if got := float64(n) / samples; math.Abs(got-want) > 0.015 {
+			t.Errorf("RandomPoint(%v, %v): got fraction %v in the lower half, want %v", test.gm, test.rect, got, want)
+		}
+	}
+
+	// A rectangle that reaches the limit of a truncated map includes the locations beyond the truncation latitude,
+	// which are sampled in proportion to their area.
+	const psiMax = 1
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithTruncation(psiMax))
+	r := rect(0, 0.5, 1, 1.5)
+	region := gm.UnprojectRect(r)
+	want := (1 - math.Sin(psiMax)) / (1 - math.Tanh(0.5))
+	var n int
+	for m := 0; m < samples; m++ {
+		p := region.RandomPoint(rnd)
+		if !region.ContainsPoint(p) {
+			t.Errorf("RandomPoint(%v, %v): got %v outside the region", gm, r, p)
+			break
+		}
+		if _, psi := gm.EquatorPoint(p); psi > psiMax {
+			n++
+		}
+	}
+	if got := float64(n) / samples; math.Abs(got-want) > 0.015 {
+		t.Errorf("RandomPoint(%v, %v): got fraction %v beyond the truncation latitude, want %v", gm, r, got, want)
+	}
+}
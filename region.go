@@ -0,0 +1,143 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r1"
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// RegionBounds returns rectangles in the plane whose union contains the projection of the region r.
+// The bounds are computed from r.CapBound and the caps bounding the cells of r.CellUnionBound, so they are not tight,
+// but they follow the shape of the region more closely than any single cap. If the projection of r straddles
+// the seam, RegionBounds returns two rectangles, one at each edge of the map; if r contains a pole,
+// the rectangles extend to infinity. RegionBounds returns nil if r is empty.
+func (gm *GeneralizedMercator) RegionBounds(r s2.Region) []r2.Rect {
+	return gm.generalizedRects(gm.regionGeneralizedBound(r))
+}
+
+// regionGeneralizedBound returns intervals containing the projective longitudes, measured from the middle of the map,
+// and the generalized latitudes of the points of r.
+func (gm *GeneralizedMercator) regionGeneralizedBound(r s2.Region) (s1.Interval, r1.Interval) {
+	xs, psis := gm.capGeneralizedBound(r.CapBound())
+	if xs.IsEmpty() || psis.IsEmpty() {
+		return s1.EmptyInterval(), r1.EmptyInterval()
+	}
+	cellXs, cellPsis := s1.EmptyInterval(), r1.EmptyInterval()
+	for _, id := range r.CellUnionBound() {
+		x, psi := gm.capGeneralizedBound(s2.CellFromCellID(id).CapBound())
+		cellXs, cellPsis = cellXs.Union(x), cellPsis.Union(psi)
+	}
+	if !cellXs.IsEmpty() {
+		xs, psis = xs.Intersection(cellXs), psis.Intersection(cellPsis)
+	}
+	return xs, psis
+}
+
+// generalizedRects returns rectangles in the plane whose union is the projection of the locations
+// whose projective longitudes, measured from the middle of the map, are in xs and whose generalized latitudes are in psis.
+func (gm *GeneralizedMercator) generalizedRects(xs s1.Interval, psis r1.Interval) []r2.Rect {
+	if xs.IsEmpty() || psis.IsEmpty() {
+		return nil
+	}
+	s := gm.radius * gm.k0
+	y := func(psi float64) float64 {
+		if math.Abs(psi) == math.Pi/2 {
+			return math.Copysign(math.Inf(1), psi)
+		}
+		return yFromPsi(psi) * s
+	}
+	lo, hi := y(psis.Lo), y(psis.Hi)
+	rect := func(x0, x1 float64) r2.Rect {
+		return r2.RectFromPoints(
+			gm.fromCentered(r2.Point{X: x0 * s, Y: lo}), gm.fromCentered(r2.Point{X: x1 * s, Y: lo}),
+			gm.fromCentered(r2.Point{X: x1 * s, Y: hi}), gm.fromCentered(r2.Point{X: x0 * s, Y: hi}),
+		)
+	}
+	if xs.IsInverted() {
+		return []r2.Rect{rect(-math.Pi, xs.Hi), rect(xs.Lo, math.Pi)}
+	}
+	return []r2.Rect{rect(xs.Lo, xs.Hi)}
+}
+
+// capGeneralizedBound returns intervals containing the projective longitudes, measured from the middle of the map,
+// and the generalized latitudes of the points of c.
+func (gm *GeneralizedMercator) capGeneralizedBound(c s2.Cap) (s1.Interval, r1.Interval) {
+	switch {
+	case c.IsEmpty():
+		return s1.EmptyInterval(), r1.EmptyInterval()
+	case c.ContainsPoint(s2.Point{gm.pos}) && c.ContainsPoint(s2.Point{gm.neg}):
+		return s1.FullInterval(), r1.Interval{Lo: -math.Pi / 2, Hi: math.Pi / 2}
+	}
+	// Since the generalized coordinates have no extremes except at the poles,
+	// their extremes over the cap lie on its boundary.
+	center, r := c.Center(), float64(c.Radius())
+	u := s2.Point{center.Ortho()}
+	v := s2.Point{center.Cross(u.Vector)}
+	boundary := func(theta float64) r3.Vector {
+		sin, cos := math.Sincos(theta)
+		return center.Mul(math.Cos(r)).Add(u.Mul(cos * math.Sin(r))).Add(v.Mul(sin * math.Sin(r)))
+	}
+	const samples = 64
+	xs, psis := make([]float64, samples+1), make([]float64, samples+1)
+	for n := range xs {
+		x, psi := gm.generalized(boundary(2 * math.Pi * float64(n) / samples))
+		xs[n], psis[n] = x-gm.x0-gm.cut, psi
+		if n > 0 {
+			xs[n] += 2 * math.Pi * math.Round((xs[n-1]-xs[n])/(2*math.Pi))
+		}
+	}
+	// refine returns the extreme value of f, which is sign times a coordinate, near the sample with index n.
+	refine := func(n int, f func(theta float64) float64) float64 {
+		lo, hi := 2*math.Pi*float64(n-1)/samples, 2*math.Pi*float64(n+1)/samples
+		return f(goldenMax(f, lo, hi))
+	}
+	// margin pads the intervals to allow for rounding error.
+	const margin = 1e-9
+	psi := r1.EmptyInterval()
+	for n, p := range psis {
+		psi = psi.AddPoint(p)
+		if n == 0 || n == samples {
+			continue
+		}
+		for _, sign := range []float64{1, -1} {
+			if sign*p >= sign*psis[n-1] && sign*p >= sign*psis[n+1] {
+				f := func(theta float64) float64 { _, psi := gm.generalized(boundary(theta)); return sign * psi }
+				psi = psi.AddPoint(sign * refine(n, f))
+			}
+		}
+	}
+	psi = psi.Expanded(margin).Intersection(r1.Interval{Lo: -math.Pi / 2, Hi: math.Pi / 2})
+	switch {
+	case c.ContainsPoint(s2.Point{gm.pos}):
+		return s1.FullInterval(), r1.Interval{Lo: psi.Lo, Hi: math.Pi / 2}
+	case c.ContainsPoint(s2.Point{gm.neg}):
+		return s1.FullInterval(), r1.Interval{Lo: -math.Pi / 2, Hi: psi.Hi}
+	}
+	x := r1.EmptyInterval()
+	for n, p := range xs {
+		x = x.AddPoint(p)
+		if n == 0 || n == samples {
+			continue
+		}
+		for _, sign := range []float64{1, -1} {
+			if sign*p >= sign*xs[n-1] && sign*p >= sign*xs[n+1] {
+				// The refined extreme is unwrapped to be near the sample.
+				f := func(theta float64) float64 {
+					gx, _ := gm.generalized(boundary(theta))
+					gx -= gm.x0 + gm.cut
+					return sign * (gx + 2*math.Pi*math.Round((p-gx)/(2*math.Pi)))
+				}
+				x = x.AddPoint(sign * refine(n, f))
+			}
+		}
+	}
+	x = x.Expanded(margin)
+	if x.Length() >= 2*math.Pi {
+		return s1.FullInterval(), psi
+	}
+	return s1.IntervalFromEndpoints(math.Remainder(x.Lo, 2*math.Pi), math.Remainder(x.Hi, 2*math.Pi)), psi
+}
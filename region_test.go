@@ -0,0 +1,103 @@
+package gm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestRegionBounds(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	capAt := func(lat, lng, r float64) s2.Cap {
+		return s2.CapFromCenterAngle(s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lng)), s1.Angle(r)*s1.Degree)
+	}
+	for _, test := range []struct {
+		name  string
+		r     s2.Region
+		rects int
+		infY  int
+	}{
+		{"empty", s2.EmptyCap(), 0, 0},
+		{"small", capAt(0, 0, 10), 1, 0},
+		{"across the seam", capAt(0, 180, 10), 2, 0},
+		{"around the north pole", capAt(90, 0, 10), 1, 1},
+		{"full", s2.FullCap(), 1, -1},
+		{"polygon", s2.PolygonFromLoops([]*s2.Loop{rectLoop(-10, 170, 10, 190)}), 2, 0},
+	} {
+		got := mercator.RegionBounds(test.r)
+		if len(got) != test.rects {
+			t.Errorf("RegionBounds(%v, %s): got %v, want %d rectangles", mercator, test.name, got, test.rects)
+			continue
+		}
+		for _, rect := range got {
+			switch inf := math.IsInf(rect.Y.Hi, 1); {
+			case test.infY == 1 && (!inf || math.IsInf(rect.Y.Lo, -1)),
+				test.infY == -1 && (!inf || !math.IsInf(rect.Y.Lo, -1)),
+				test.infY == 0 && (inf || math.IsInf(rect.Y.Lo, -1)):
+				t.Errorf("RegionBounds(%v, %s): got %v", mercator, test.name, rect)
+			}
+		}
+	}
+	// The bounds of a small cap are close to its extent.
+	got := mercator.RegionBounds(capAt(0, 0, 10))
+	if x := got[0].X; x.Lo > -10*pi/180 || x.Hi < 10*pi/180 || x.Lo < -0.5 || x.Hi > 0.5 {
+		t.Errorf("RegionBounds(%v, small): got %v", mercator, got)
+	}
+
+	// In every projection, the bounds contain the projections of the points of the region.
+	rnd := rand.New(rand.NewSource(1))
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{
+			New(pos, neg),
+			New(pos, neg, WithSeam(1), WithAffine(0, -1, 1, 0, 2, 3)),
+		} {
+			for _, r := range []s2.Region{
+				capAt(10, 20, 15),
+				capAt(-30, 170, 25),
+				capAt(70, -100, 30),
+				s2.CellFromCellID(s2.CellIDFromLatLng(s2.LatLngFromDegrees(40, -175)).Parent(4)),
+				s2.PolygonFromLoops([]*s2.Loop{rectLoop(-20, 100, 30, 260)}),
+			} {
+				rects := gm.RegionBounds(r)
+				cap := r.CapBound()
+				for n := 0; n < 200; n++ {
+					p := s2.Point{cap.Center().Add(s2.Point{randomPoint(rnd)}.Mul(float64(cap.Radius()))).Normalize()}
+					if !r.ContainsPoint(p) {
+						continue
+					}
+					q := gm.ProjectPoint(p)
+					if !inAnyRect(rects, q) {
+						t.Errorf("RegionBounds(%v, %v): got %v, which do not contain %v (%v)", gm, r, rects, q, s2.LatLngFromPoint(p))
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// randomPoint returns a point uniformly distributed in the unit ball.
+func randomPoint(rnd *rand.Rand) r3.Vector {
+	for {
+		v := r3.Vector{X: 2*rnd.Float64() - 1, Y: 2*rnd.Float64() - 1, Z: 2*rnd.Float64() - 1}
+		if v.Norm2() <= 1 {
+			return v
+		}
+	}
+}
+
+// inAnyRect reports whether any of rects contains p, allowing for rounding error.
+func inAnyRect(rects []r2.Rect, p r2.Point) bool {
+	for _, rect := range rects {
+		if rect.ExpandedByMargin(1e-9).ContainsPoint(p) {
+			return true
+		}
+	}
+	return false
+}
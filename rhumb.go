@@ -0,0 +1,186 @@
+package gm
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A generalized rhumb line is a curve on the sphere that projects to a straight line. If the poles are antipodal,
+// it crosses every generalized meridian at the same angle, and generalizes the loxodrome of the Mercator projection.
+
+// rhumbTolerance is the tolerance in radians to which the length of a rhumb line is computed numerically.
+const rhumbTolerance = 1e-8
+
+// rhumbSegment returns the projections of a and b before scaling and any output transformation, in radians,
+// with the x coordinate of the projection of b adjusted by a multiple of 2π to make it nearest to that of a,
+// so that the segment between them takes the shorter way around the projection.
+// The projections are not truncated: a rhumb line is straight in the untruncated projection,
+// and truncation would move the ends of one beyond the truncation latitude.
+func (gm *GeneralizedMercator) rhumbSegment(a, b s2.LatLng) (pa, pb r2.Point) {
+	g := *gm
+	g.psiMax = 0
+	s := gm.radius * gm.k0
+	pa, pb = g.project(a).Mul(1/s), g.project(b).Mul(1/s)
+	if isFinite(pa) && isFinite(pb) {
+		pb.X += 2 * math.Pi * math.Round((pa.X-pb.X)/(2*math.Pi))
+	}
+	return pa, pb
+}
+
+// RhumbCourse returns the course of the generalized rhumb line from a to b: the angle from the direction
+// toward Pos along the generalized meridian through a to the direction of the rhumb line, measured toward
+// increasing projected x, in the interval [-π, π]. The rhumb line takes the shorter way around the projection.
+// Courses are measured in the plane of the projection before any output transformation; they are equal
+// to the angles on the sphere only if the poles are antipodal, since the projection is otherwise not conformal.
+// The course to Pos is 0 and the course to Neg is π. RhumbCourse returns 0 if a and b project to the same point.
+func (gm *GeneralizedMercator) RhumbCourse(a, b s2.LatLng) s1.Angle {
+	pa, pb := gm.rhumbSegment(a, b)
+	switch {
+	case math.IsInf(pb.Y, 1), math.IsInf(pa.Y, -1) && !math.IsInf(pb.Y, -1):
+		return 0
+	case math.IsInf(pb.Y, -1), math.IsInf(pa.Y, 1):
+		return math.Pi
+	}
+	d := pb.Sub(pa)
+	return s1.Angle(math.Atan2(d.X, d.Y))
+}
+
+// RhumbDistance returns the length on the unit sphere of the generalized rhumb line from a to b,
+// which takes the shorter way around the projection. Multiply by Radius to obtain the length on the reference sphere.
+// If the poles are antipodal, the length is computed exactly; otherwise it is computed numerically.
+func (gm *GeneralizedMercator) RhumbDistance(a, b s2.LatLng) s1.Angle {
+	pa, pb := gm.rhumbSegment(a, b)
+	if pa == pb {
+		return 0
+	}
+	if math.IsInf(pa.Y, 0) && math.IsInf(pb.Y, 0) {
+		// The rhumb line between the poles is a generalized meridian.
+		return gm.meridianLength(0, -math.Pi/2, math.Pi/2)
+	}
+	if math.IsInf(pa.Y, 0) {
+		pa, pb = pb, pa
+	}
+	psiA := psiFromY(pa.Y)
+	if math.IsInf(pb.Y, 0) {
+		return gm.meridianLength(pa.X, psiA, math.Copysign(math.Pi/2, pb.Y))
+	}
+	if !gm.IsAntipodalPoles() {
+		f := func(t float64) s2.Point {
+			p := pa.Mul(1 - t).Add(pb.Mul(t))
+			return s2.Point{gm.fromGeneralized(p.X+gm.x0, psiFromY(p.Y))}
+		}
+		line := tessellate(f, 0, 1, rhumbTolerance)
+		return line.Length()
+	}
+	// Along a loxodrome, the generalized latitude changes at the rate cos(course) per unit length,
+	// and y changes at the rate cos(course) / cos(ψ).
+	d := pb.Sub(pa)
+	var ratio float64
+	if math.Abs(d.Y) < 1e-9 {
+		ratio = math.Cos(psiFromY((pa.Y + pb.Y) / 2))
+	} else {
+		ratio = (psiFromY(pb.Y) - psiA) / d.Y
+	}
+	return s1.Angle(ratio * d.Norm())
+}
+
+// meridianLength returns the length on the unit sphere of the generalized meridian at projective longitude x,
+// measured from the central line, between generalized latitudes psi0 and psi1.
+func (gm *GeneralizedMercator) meridianLength(x, psi0, psi1 float64) s1.Angle {
+	if gm.IsAntipodalPoles() {
+		return s1.Angle(math.Abs(psi1 - psi0))
+	}
+	f := func(psi float64) s2.Point { return s2.Point{gm.fromGeneralized(x+gm.x0, psi)} }
+	line := tessellate(f, psi0, psi1, rhumbTolerance)
+	return line.Length()
+}
+
+// RhumbInterpolate returns the location on the generalized rhumb line from a to b, taking the shorter way
+// around the projection, whose projection is the given fraction of the way from the projection of a
+// to that of b. Fractions less than 0 or greater than 1 extrapolate along the rhumb line.
+// The fraction is of the distance in the plane, which is proportional to the distance along the rhumb line
+// on the sphere only if the poles are antipodal. A rhumb line to a pole is treated as ending very close to the pole.
+func (gm *GeneralizedMercator) RhumbInterpolate(f float64, a, b s2.LatLng) s2.LatLng {
+	pa, pb := gm.rhumbSegment(a, b)
+	pa, pb = gm.clampRhumb(pa, pb), gm.clampRhumb(pb, pa)
+	return gm.fromPlane(pa.Mul(1 - f).Add(pb.Mul(f)))
+}
+
+// fromPlane returns the location that projects to p before scaling and any output transformation.
+func (gm *GeneralizedMercator) fromPlane(p r2.Point) s2.LatLng {
+	return gm.latLngFromPoint(s2.Point{gm.fromGeneralized(p.X+gm.x0, psiFromY(p.Y))})
+}
+
+// RhumbMidpoint returns the location halfway along the generalized rhumb line from a to b in the plane,
+// as by RhumbInterpolate.
+func (gm *GeneralizedMercator) RhumbMidpoint(a, b s2.LatLng) s2.LatLng {
+	return gm.RhumbInterpolate(0.5, a, b)
+}
+
+// RhumbIntersections returns the locations at which the generalized rhumb line from a0 to a1 crosses the one
+// from b0 to b1, each taking the shorter way around the projection. Since the projection wraps around,
+// the rhumb lines may cross more than once, and the intersections are returned in order along the first.
+// Rhumb lines that overlap along a common segment are not considered to cross.
+// A rhumb line to a pole is treated as ending very close to the pole.
+func (gm *GeneralizedMercator) RhumbIntersections(a0, a1, b0, b1 s2.LatLng) []s2.LatLng {
+	p0, p1 := gm.rhumbSegment(a0, a1)
+	q0, q1 := gm.rhumbSegment(b0, b1)
+	p0, p1, q0, q1 = gm.clampRhumb(p0, p1), gm.clampRhumb(p1, p0), gm.clampRhumb(q0, q1), gm.clampRhumb(q1, q0)
+	var ts []float64
+	// The x coordinates of each segment span less than the period, so only nearby copies of the second can cross the first.
+	for k := -2.0; k <= 2; k++ {
+		shift := r2.Point{X: 2 * math.Pi * k}
+		if t, ok := segmentIntersection(p0, p1, q0.Add(shift), q1.Add(shift)); ok {
+			ts = append(ts, t)
+		}
+	}
+	sort.Float64s(ts)
+	var lls []s2.LatLng
+	for n, t := range ts {
+		if n > 0 && t-ts[n-1] < 1e-12 {
+			continue
+		}
+		lls = append(lls, gm.fromPlane(p0.Mul(1-t).Add(p1.Mul(t))))
+	}
+	return lls
+}
+
+// rhumbMaxY returns the y coordinate, before scaling, at which a rhumb line to a pole is treated as ending.
+// It is that of the generalized latitude gm.tol from ±π/2, since locations within the tolerance of a pole
+// are taken to be at the pole, or epsilon from it if the tolerance is smaller.
+func (gm *GeneralizedMercator) rhumbMaxY() float64 {
+	// y = asinh(tan(π/2 - t)) = asinh(1/tan(t)).
+	return math.Asinh(1 / math.Tan(math.Max(gm.tol, epsilon)))
+}
+
+// clampRhumb returns p, or if p is a pole, the point at ±rhumbMaxY on the generalized meridian of the other end q
+// of the rhumb line.
+func (gm *GeneralizedMercator) clampRhumb(p, q r2.Point) r2.Point {
+	if !math.IsInf(p.Y, 0) {
+		return p
+	}
+	if math.IsInf(q.Y, 0) {
+		return r2.Point{X: p.X, Y: math.Copysign(gm.rhumbMaxY(), p.Y)}
+	}
+	return r2.Point{X: q.X, Y: math.Copysign(gm.rhumbMaxY(), p.Y)}
+}
+
+// segmentIntersection returns the fraction of the distance along the segment from p0 to p1 at which it crosses
+// the segment from q0 to q1, and reports whether they cross. Parallel segments are not considered to cross.
+func segmentIntersection(p0, p1, q0, q1 r2.Point) (float64, bool) {
+	d, e := p1.Sub(p0), q1.Sub(q0)
+	den := d.Cross(e)
+	if den == 0 {
+		return 0, false
+	}
+	w := q0.Sub(p0)
+	t, u := w.Cross(e)/den, w.Cross(d)/den
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return 0, false
+	}
+	return t, true
+}
@@ -0,0 +1,222 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestRhumbCourse(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, test := range []struct {
+		a, b s2.LatLng
+		want s1.Angle
+	}{
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(10, 0), 0},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(-10, 0), pi},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 10), pi / 2},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, -10), -pi / 2},
+		{s2.LatLngFromDegrees(0, 170), s2.LatLngFromDegrees(0, -170), pi / 2},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(math.Atan(math.Sinh(0.1))*180/pi, 0.1*180/pi), pi / 4},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(90, 0), 0},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(-90, 0), pi},
+		{s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(0, 0), pi},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 0), 0},
+	} {
+		if got := mercator.RhumbCourse(test.a, test.b); math.Abs(float64(got-test.want)) > 1e-12 {
+			t.Errorf("RhumbCourse(%v, %v, %v): got %v, want %v", mercator, test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestRhumbDistance(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, test := range []struct {
+		a, b s2.LatLng
+		want s1.Angle
+	}{
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(10, 0), 10 * s1.Degree},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 10), 10 * s1.Degree},
+		{s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(60, 10), 5 * s1.Degree},
+		{s2.LatLngFromDegrees(60, 175), s2.LatLngFromDegrees(60, -175), 5 * s1.Degree},
+		{s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(90, 0), 60 * s1.Degree},
+		{s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), 180 * s1.Degree},
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 0), 0},
+		// A loxodrome at 45° covers √2 times its change in latitude.
+		{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(math.Atan(math.Sinh(0.1))*180/pi, 0.1*180/pi), s1.Angle(math.Sqrt2 * math.Atan(math.Sinh(0.1)))},
+	} {
+		if got := mercator.RhumbDistance(test.a, test.b); math.Abs(float64(got-test.want)) > 1e-12 {
+			t.Errorf("RhumbDistance(%v, %v, %v): got %v, want %v", mercator, test.a, test.b, got, test.want)
+		}
+	}
+
+	// The numerical computation agrees with the exact one, and the distance is symmetric.
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg)
+		for _, ps := range [][2]s2.LatLng{
+			{s2.LatLngFromDegrees(10, 20), s2.LatLngFromDegrees(-30, 50)},
+			{s2.LatLngFromDegrees(-40, -100), s2.LatLngFromDegrees(45, 60)},
+			{s2.LatLngFromDegrees(5, 5), pos},
+		} {
+			a, b := ps[0], ps[1]
+			got := gm.RhumbDistance(a, b)
+			if rev := gm.RhumbDistance(b, a); math.Abs(float64(got-rev)) > 1e-9 {
+				t.Errorf("RhumbDistance(%v, %v, %v): got %v, reversed %v", gm, a, b, got, rev)
+			}
+			if got < s2.PointFromLatLng(a).Distance(s2.PointFromLatLng(b))-1e-12 {
+				t.Errorf("RhumbDistance(%v, %v, %v): got %v, less than the geodesic distance", gm, a, b, got)
+			}
+			if gm.IsAntipodalPoles() {
+				pa, pb := gm.rhumbSegment(a, b)
+				var want s1.Angle
+				if math.IsInf(pb.Y, 0) {
+					want = gm.meridianLength(pa.X, psiFromY(pa.Y), pi/2)
+				} else {
+					f := func(t float64) s2.Point {
+						p := pa.Mul(1 - t).Add(pb.Mul(t))
+						return s2.Point{gm.fromGeneralized(p.X+gm.x0, psiFromY(p.Y))}
+					}
+					line := tessellate(f, 0, 1, rhumbTolerance)
+					want = line.Length()
+				}
+				if math.Abs(float64(got-want)) > 1e-7 {
+					t.Errorf("RhumbDistance(%v, %v, %v): got %v, want %v", gm, a, b, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestRhumbIntersections(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, test := range []struct {
+		a0, a1, b0, b1 s2.LatLng
+		want           []s2.LatLng
+	}{
+		{
+			s2.LatLngFromDegrees(0, -10), s2.LatLngFromDegrees(0, 10),
+			s2.LatLngFromDegrees(-10, 0), s2.LatLngFromDegrees(10, 0),
+			[]s2.LatLng{s2.LatLngFromDegrees(0, 0)},
+		},
+		{
+			s2.LatLngFromDegrees(0, -10), s2.LatLngFromDegrees(0, 10),
+			s2.LatLngFromDegrees(5, 0), s2.LatLngFromDegrees(10, 0),
+			nil,
+		},
+		{
+			// The rhumb lines cross on the other side of the seam.
+			s2.LatLngFromDegrees(20, 170), s2.LatLngFromDegrees(20, -170),
+			s2.LatLngFromDegrees(10, -175), s2.LatLngFromDegrees(30, -175),
+			[]s2.LatLng{s2.LatLngFromDegrees(20, -175)},
+		},
+		{
+			s2.LatLngFromDegrees(20, 170), s2.LatLngFromDegrees(20, -170),
+			s2.LatLngFromDegrees(10, 175), s2.LatLngFromDegrees(30, 175),
+			[]s2.LatLng{s2.LatLngFromDegrees(20, 175)},
+		},
+		{
+			// A rhumb line to a pole follows a meridian.
+			s2.LatLngFromDegrees(60, -10), s2.LatLngFromDegrees(60, 10),
+			s2.LatLngFromDegrees(0, 5), s2.LatLngFromDegrees(90, 0),
+			[]s2.LatLng{s2.LatLngFromDegrees(60, 5)},
+		},
+		{
+			// Parallel rhumb lines do not cross.
+			s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(10, 10),
+			s2.LatLngFromDegrees(0, 1), s2.LatLngFromDegrees(10, 11),
+			nil,
+		},
+	} {
+		got := mercator.RhumbIntersections(test.a0, test.a1, test.b0, test.b1)
+		if len(got) != len(test.want) {
+			t.Errorf("RhumbIntersections(%v, %v, %v, %v): got %v, want %v", test.a0, test.a1, test.b0, test.b1, got, test.want)
+			continue
+		}
+		for n := range got {
+			if !s2.PointFromLatLng(got[n]).ApproxEqual(s2.PointFromLatLng(test.want[n])) {
+				t.Errorf("RhumbIntersections(%v, %v, %v, %v): got %v, want %v", test.a0, test.a1, test.b0, test.b1, got, test.want)
+			}
+		}
+	}
+
+	// In every projection, the intersection projects onto both rhumb lines.
+	for _, test := range projTests {
+		gm := New(test.gm.Poles())
+		a0, a1 := gm.Unproject(r2.Point{X: -1, Y: -0.5}), gm.Unproject(r2.Point{X: 1, Y: 0.5})
+		b0, b1 := gm.Unproject(r2.Point{X: -0.5, Y: 1}), gm.Unproject(r2.Point{X: 0.5, Y: -1})
+		got := gm.RhumbIntersections(a0, a1, b0, b1)
+		if len(got) != 1 || !ptApproxEqual(gm.Project(got[0]), r2.Point{}) {
+			t.Errorf("RhumbIntersections(%v): got %v, want the unprojection of the origin", gm, got)
+		}
+	}
+}
+
+func TestRhumbInterpolate(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, test := range []struct {
+		f    float64
+		a, b s2.LatLng
+		want s2.LatLng
+	}{
+		{0, s2.LatLngFromDegrees(10, 20), s2.LatLngFromDegrees(-30, 50), s2.LatLngFromDegrees(10, 20)},
+		{1, s2.LatLngFromDegrees(10, 20), s2.LatLngFromDegrees(-30, 50), s2.LatLngFromDegrees(-30, 50)},
+		{0.5, s2.LatLngFromDegrees(0, 20), s2.LatLngFromDegrees(0, 50), s2.LatLngFromDegrees(0, 35)},
+		{0.25, s2.LatLngFromDegrees(40, 170), s2.LatLngFromDegrees(40, -170), s2.LatLngFromDegrees(40, 175)},
+		{0.75, s2.LatLngFromDegrees(40, 170), s2.LatLngFromDegrees(40, -170), s2.LatLngFromDegrees(40, -175)},
+		{2, s2.LatLngFromDegrees(0, 20), s2.LatLngFromDegrees(0, 30), s2.LatLngFromDegrees(0, 40)},
+		{0.5, s2.LatLngFromDegrees(-60, 10), s2.LatLngFromDegrees(60, 10), s2.LatLngFromDegrees(0, 10)},
+		// Latitude varies along a meridian in proportion to y, not to the angle.
+		{0.5, s2.LatLngFromDegrees(0, 10), s2.LatLngFromDegrees(60, 10), s2.LatLng{Lat: s1.Angle(psiFromY(yFromPsi(pi/3) / 2)), Lng: 10 * s1.Degree}},
+	} {
+		got := mercator.RhumbInterpolate(test.f, test.a, test.b)
+		if !s2.PointFromLatLng(got).ApproxEqual(s2.PointFromLatLng(test.want)) {
+			t.Errorf("RhumbInterpolate(%v, %v, %v): got %v, want %v", test.f, test.a, test.b, got, test.want)
+		}
+	}
+	for _, test := range projTests {
+		gm := New(test.gm.Poles())
+		a, b := gm.Unproject(r2.Point{X: -1, Y: -0.5}), gm.Unproject(r2.Point{X: 2, Y: 1})
+		if got := gm.RhumbMidpoint(a, b); !ptApproxEqual(gm.Project(got), r2.Point{X: 0.5, Y: 0.25}) {
+			t.Errorf("RhumbMidpoint(%v, %v, %v): got %v, which projects to %v", gm, a, b, got, gm.Project(got))
+		}
+	}
+}
+
+func TestRhumbTruncation(t *testing.T) {
+	// Truncation affects only projected coordinates, not the rhumb lines, even beyond the truncation latitude.
+	for _, poles := range [][2]s2.LatLng{
+		{s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)},
+		{s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2)},
+	} {
+		gm, truncated := New(poles[0], poles[1]), New(poles[0], poles[1], WithTruncation(0.5))
+		a0, a1 := gm.FromGeneralized(-1, 0.2), gm.FromGeneralized(1, 1.2)
+		b0, b1 := gm.FromGeneralized(1, 0.7), gm.FromGeneralized(-0.5, 1.3)
+		if got, want := truncated.RhumbCourse(a0, a1), gm.RhumbCourse(a0, a1); math.Abs(float64(got-want)) > 1e-12 {
+			t.Errorf("RhumbCourse(%v, %v, %v): got %v, want %v", truncated, a0, a1, got, want)
+		}
+		if got, want := truncated.RhumbDistance(a0, a1), gm.RhumbDistance(a0, a1); math.Abs(float64(got-want)) > 1e-12 {
+			t.Errorf("RhumbDistance(%v, %v, %v): got %v, want %v", truncated, a0, a1, got, want)
+		}
+		if got, want := truncated.RhumbMidpoint(a0, a1), gm.RhumbMidpoint(a0, a1); !llApproxEqual(got, want) {
+			t.Errorf("RhumbMidpoint(%v, %v, %v): got %v, want %v", truncated, a0, a1, got, want)
+		}
+		got, want := truncated.RhumbIntersections(a0, a1, b0, b1), gm.RhumbIntersections(a0, a1, b0, b1)
+		if len(got) != 1 || len(want) != 1 || !llApproxEqual(got[0], want[0]) {
+			t.Errorf("RhumbIntersections(%v, %v, %v, %v, %v): got %v, want %v", truncated, a0, a1, b0, b1, got, want)
+		}
+	}
+
+	// A rhumb line to a pole ends within the tolerance of the pole.
+	for _, tol := range []float64{0, 1e-9, 1e-6} {
+		gm := New(s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2), WithTolerance(tol))
+		pos, _ := gm.Poles()
+		end := gm.RhumbInterpolate(1, s2.LatLngFromDegrees(10, 20), pos)
+		if d, want := float64(s2.PointFromLatLng(end).Distance(s2.PointFromLatLng(pos))), math.Max(tol, epsilon); d > 2*want {
+			t.Errorf("RhumbInterpolate(%v, 1, to pole): got %v, %v from the pole, want at most %v", gm, end, d, want)
+		}
+	}
+}
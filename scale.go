@@ -0,0 +1,85 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s2"
+)
+
+// AreaScale returns the areal scale factor of the projection at ll:
+// the ratio of the area of an infinitesimal region around the projection of ll
+// to the area of the corresponding region on the reference sphere.
+// It returns +Inf at the poles.
+func (gm *GeneralizedMercator) AreaScale(ll s2.LatLng) float64 {
+	P := gm.pointFromLatLng(ll).Vector
+	if gm.near(P, gm.pos) || gm.near(P, gm.neg) {
+		return math.Inf(1)
+	}
+	gx, gy := gm.gradients(P)
+	// For any orthonormal basis (e, n) of the tangent plane at P with e × n = P,
+	// the determinant of the Jacobian is (gx·e)(gy·n) - (gx·n)(gy·e) = (gx × gy)·P.
+	return math.Abs(gx.Cross(gy).Dot(P)) * gm.k0 * gm.k0
+}
+
+// ScaleFactors returns the maximum and minimum linear scale factors of the projection at ll: the semi-axes of
+// the Tissot indicatrix, or the greatest and least ratios of the length of an infinitesimal projected segment
+// through the projection of ll to the length of the corresponding segment on the reference sphere.
+// The projection is conformal, with equal scale factors everywhere, only if the poles are antipodes.
+// In that case the scale factor at generalized latitude ψ is k0 sec ψ, where k0 is gm.ScaleFactor().
+// ScaleFactors returns +Inf, +Inf at the poles.
+func (gm *GeneralizedMercator) ScaleFactors(ll s2.LatLng) (max, min float64) {
+	P := gm.pointFromLatLng(ll).Vector
+	if gm.near(P, gm.pos) || gm.near(P, gm.neg) {
+		return math.Inf(1), math.Inf(1)
+	}
+	gx, gy := gm.gradients(P)
+	e := P.Ortho()
+	n := P.Cross(e)
+	var (
+		a, b = gx.Dot(e), gx.Dot(n)
+		c, d = gy.Dot(e), gy.Dot(n)
+
+		// The singular values of the Jacobian [[a, b], [c, d]] are q+r and |q-r|, where q and r are the magnitudes
+		// of its conformal and anticonformal parts.
+		q = math.Hypot((a+d)/2, (c-b)/2)
+		r = math.Hypot((a-d)/2, (c+b)/2)
+	)
+	return (q + r) * gm.k0, math.Abs(q-r) * gm.k0
+}
+
+// gradients returns the gradients of the projected coordinates x and y with respect to P.
+// The component of each gradient along P itself is irrelevant to derivatives on the sphere.
+func (gm *GeneralizedMercator) gradients(P r3.Vector) (gx, gy r3.Vector) {
+	var (
+		a, b, c = P.Dot(gm.i), P.Dot(gm.j), P.Dot(gm.k)
+
+		// u and s are P·i' and sin(ψ); (ua, uc) and (sa, sc) are their partial derivatives with respect to a and c.
+		u, ua, uc float64
+		s, sa, sc float64
+	)
+	if math.IsInf(gm.d, 1) {
+		u, ua, uc = a, 1, 0
+		s, sa, sc = c, 0, 1
+	} else {
+		// With r = hypot(c, d-a), sin(β) = c/r and cos(β) = (d-a)/r, so that
+		// u = a*cos(β) - c*sin(β) = (a(d-a) - c²)/r and s = a*sin(β) + c*cos(β) = cd/r.
+		d := gm.d
+		r2 := c*c + (d-a)*(d-a)
+		r := math.Sqrt(r2)
+		r3 := r2 * r
+		u = (a*(d-a) - c*c) / r
+		ua = ((d-2*a)*r2 + (a*(d-a)-c*c)*(d-a)) / r3
+		uc = (-2*c*r2 - c*(a*(d-a)-c*c)) / r3
+		s = c * d / r
+		sa = c * d * (d - a) / r3
+		sc = d * (d - a) * (d - a) / r3
+	}
+
+	// x = atan2(b, u) and y = atanh(s).
+	w := 1 / (u*u + b*b)
+	gx = gm.i.Mul(-b * ua * w).Add(gm.j.Mul(u * w)).Add(gm.k.Mul(-b * uc * w))
+	t := 1 / (1 - s*s)
+	gy = gm.i.Mul(sa * t).Add(gm.k.Mul(sc * t))
+	return gx, gy
+}
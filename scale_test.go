@@ -0,0 +1,102 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// scaleTests are projections and locations at which to check derivatives against finite differences.
+var scaleTests = []struct {
+	gm  *GeneralizedMercator
+	lls []s2.LatLng
+}{
+	{
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+		[]s2.LatLng{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(45, 10), s2.LatLngFromDegrees(-70, 120)},
+	},
+	{
+		New(s2.LatLngFromDegrees(30, 40), s2.LatLngFromDegrees(-30, -140)),
+		[]s2.LatLng{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(45, 10), s2.LatLngFromDegrees(-70, 120)},
+	},
+	{
+		New(s2.LatLngFromDegrees(60, 0), s2.LatLngFromDegrees(-60, 0)),
+		[]s2.LatLng{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(45, 10), s2.LatLngFromDegrees(-20, 120)},
+	},
+	{
+		New(s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2)),
+		[]s2.LatLng{s2.LatLngFromDegrees(10, 20), s2.LatLngFromDegrees(45, -10), s2.LatLngFromDegrees(-20, 100)},
+	},
+}
+
+// numericJacobian returns the partial derivatives of gm.Project at ll with respect to
+// eastward and northward displacement on the unit sphere, estimated by central differences.
+func numericJacobian(gm *GeneralizedMercator, ll s2.LatLng) (east, north r2.Point) {
+	const h = 1e-6
+	cos := math.Cos(float64(ll.Lat))
+	diff := func(dlat, dlng float64) r2.Point {
+		p := gm.Project(s2.LatLng{Lat: ll.Lat + s1.Angle(dlat), Lng: ll.Lng + s1.Angle(dlng)})
+		q := gm.Project(s2.LatLng{Lat: ll.Lat - s1.Angle(dlat), Lng: ll.Lng - s1.Angle(dlng)})
+		return p.Sub(q).Mul(1 / (2 * h))
+	}
+	return diff(0, h/cos), diff(h, 0)
+}
+
+func TestAreaScale(t *testing.T) {
+	for _, test := range scaleTests {
+		for _, ll := range test.lls {
+			e, n := numericJacobian(test.gm, ll)
+			want := math.Abs(e.Cross(n))
+			if got := test.gm.AreaScale(ll); math.Abs(got-want) > 1e-6*want {
+				t.Errorf("AreaScale(%v, %v): got %v, want %v", test.gm, ll, got, want)
+			}
+		}
+		pos, neg := test.gm.Poles()
+		if got := test.gm.AreaScale(pos); !math.IsInf(got, 1) {
+			t.Errorf("AreaScale(%v, %v): got %v, want +Inf", test.gm, pos, got)
+		}
+		if got := test.gm.AreaScale(neg); !math.IsInf(got, 1) {
+			t.Errorf("AreaScale(%v, %v): got %v, want +Inf", test.gm, neg, got)
+		}
+	}
+
+	// In the Mercator projection, the areal scale factor is sec²(φ).
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, lat := range []float64{0, 30, 60, -45} {
+		want := 1 / math.Pow(math.Cos(lat*math.Pi/180), 2)
+		if got := gm.AreaScale(s2.LatLngFromDegrees(lat, 50)); math.Abs(got-want) > 1e-12 {
+			t.Errorf("AreaScale(%v, %v°): got %v, want %v", gm, lat, got, want)
+		}
+	}
+}
+
+func TestScaleFactors(t *testing.T) {
+	for _, test := range scaleTests {
+		for _, ll := range test.lls {
+			e, n := numericJacobian(test.gm, ll)
+			sum := e.Norm()*e.Norm() + n.Norm()*n.Norm()
+			det := e.Cross(n)
+			disc := math.Sqrt(sum*sum - 4*det*det)
+			wantMax, wantMin := math.Sqrt((sum+disc)/2), math.Sqrt((sum-disc)/2)
+			gotMax, gotMin := test.gm.ScaleFactors(ll)
+			if math.Abs(gotMax-wantMax) > 1e-6*wantMax || math.Abs(gotMin-wantMin) > 1e-6*wantMax {
+				t.Errorf("ScaleFactors(%v, %v): got %v, %v, want %v, %v", test.gm, ll, gotMax, gotMin, wantMax, wantMin)
+			}
+			if a := test.gm.AreaScale(ll); math.Abs(gotMax*gotMin-a) > 1e-12*a {
+				t.Errorf("ScaleFactors(%v, %v): got product %v, want AreaScale %v", test.gm, ll, gotMax*gotMin, a)
+			}
+		}
+	}
+
+	// The Mercator projection is conformal with scale factor sec(φ).
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, lat := range []float64{0, 30, 60, -45} {
+		want := 1 / math.Cos(lat*math.Pi/180)
+		if max, min := gm.ScaleFactors(s2.LatLngFromDegrees(lat, 50)); math.Abs(max-want) > 1e-12 || math.Abs(min-want) > 1e-12 {
+			t.Errorf("ScaleFactors(%v, %v°): got %v, %v, want %v", gm, lat, max, min, want)
+		}
+	}
+}
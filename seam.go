@@ -0,0 +1,245 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// The seam of the projection is the curve of locations whose projective longitude relative to the middle of the map is ±π,
+// where projected x coordinates jump between the left and right edges of the map. It runs from Pos to Neg.
+// The middle of the map is the central line unless WithSeam places the seam elsewhere.
+
+// A SeamSide determines the edge of the map to which locations on the seam project.
+// Since the seam is where projected x coordinates wrap around, such locations could be placed on either edge.
+type SeamSide int
+
+const (
+	// SeamAsComputed places each location on the seam according to the sign of its computed projective longitude,
+	// which may depend on rounding error.
+	SeamAsComputed SeamSide = iota
+
+	// SeamPositive places locations on the seam on the edge of the map with the greater x coordinate
+	// before any output transformation.
+	SeamPositive
+
+	// SeamNegative places locations on the seam on the edge of the map with the lesser x coordinate
+	// before any output transformation.
+	SeamNegative
+)
+
+// opposite returns the SeamSide that places locations on the seam on the other edge of the map.
+func (s SeamSide) opposite() SeamSide {
+	switch s {
+	case SeamPositive:
+		return SeamNegative
+	case SeamNegative:
+		return SeamPositive
+	}
+	return s
+}
+
+// seamTolerance is the greatest difference in projective longitude from the seam, in radians,
+// at which a location is considered to be on the seam, to allow for rounding error.
+const seamTolerance = 1e-14
+
+// wrap reduces x, a projective longitude relative to the middle of the map, to the interval [-π, π].
+// Unless gm.side is SeamAsComputed, it places values within seamTolerance of the seam exactly on the chosen edge.
+func (gm *GeneralizedMercator) wrap(x float64) float64 {
+	x = math.Remainder(x, 2*math.Pi)
+	if gm.side != SeamAsComputed && math.Abs(x) > math.Pi-seamTolerance {
+		if gm.side == SeamPositive {
+			return math.Pi
+		}
+		return -math.Pi
+	}
+	return x
+}
+
+// seamSide returns the sine and cosine of the projective longitude of p relative to the middle of the map.
+// The sine changes sign across both the middle of the map and the seam, and the cosine distinguishes them.
+// seamSide returns ok == false if p is a pole.
+func (gm *GeneralizedMercator) seamSide(p s2.Point) (sin, cos float64, ok bool) {
+	if gm.near(p.Vector, gm.pos) || gm.near(p.Vector, gm.neg) {
+		return 0, 0, false
+	}
+	x, _ := gm.generalized(p.Vector)
+	sin, cos = math.Sincos(x - gm.x0 - gm.cut)
+	return sin, cos, true
+}
+
+// seamCrossing reports whether the geodesic edge from a to b crosses the seam, and if so, returns the crossing point.
+// Edges with an endpoint at a pole or on the seam are not considered to cross it.
+func (gm *GeneralizedMercator) seamCrossing(a, b s2.Point) (s2.Point, bool) {
+	sa, _, okA := gm.seamSide(a)
+	sb, _, okB := gm.seamSide(b)
+	if !okA || !okB || math.Abs(sa) < epsilon || math.Abs(sb) < epsilon || (sa > 0) == (sb > 0) {
+		return s2.Point{}, false
+	}
+	// The edge crosses either the seam or the middle of the map. Locate the crossing by bisection.
+	lo, hi := 0.0, 1.0
+	var c s2.Point
+	for n := 0; n < 64; n++ {
+		t := (lo + hi) / 2
+		c = s2.Interpolate(t, a, b)
+		s, _, ok := gm.seamSide(c)
+		if !ok {
+			// The edge passes through a pole.
+			return s2.Point{}, false
+		}
+		if (s > 0) == (sa > 0) {
+			lo = t
+		} else {
+			hi = t
+		}
+		if hi-lo < 1e-17 {
+			break
+		}
+	}
+	if _, cos, _ := gm.seamSide(c); cos >= 0 {
+		return s2.Point{}, false
+	}
+	return c, true
+}
+
+// crossesAtVertex reports whether the polyline through a, v, and b crosses the seam at v,
+// which lies on the seam between a and b on opposite sides of it.
+func (gm *GeneralizedMercator) crossesAtVertex(a, v, b s2.Point) bool {
+	sin, cos, ok := gm.seamSide(v)
+	if !ok || math.Abs(sin) >= epsilon || cos >= 0 {
+		return false
+	}
+	sa, _, okA := gm.seamSide(a)
+	sb, _, okB := gm.seamSide(b)
+	return okA && okB && math.Abs(sa) >= epsilon && math.Abs(sb) >= epsilon && (sa > 0) != (sb > 0)
+}
+
+// DoesEdgeCrossSeam reports whether the geodesic edge from a to b crosses the seam, so that its projection
+// would jump between the left and right edges of the map. Edges with an endpoint at a pole or on the seam
+// are not considered to cross it.
+func (gm *GeneralizedMercator) DoesEdgeCrossSeam(a, b s2.Point) bool {
+	_, ok := gm.seamCrossing(a, b)
+	return ok
+}
+
+// EdgeSeamCrossing returns the point at which the geodesic edge from a to b crosses the seam,
+// and reports whether it does so, as determined by DoesEdgeCrossSeam.
+// Splitting the edge at the crossing point yields two edges that each project without a jump.
+func (gm *GeneralizedMercator) EdgeSeamCrossing(a, b s2.Point) (s2.Point, bool) {
+	return gm.seamCrossing(a, b)
+}
+
+// ProjectPolyline projects the vertices of line and returns the resulting polyline as one or more pieces,
+// splitting it wherever an edge crosses the seam, so that no piece jumps between the left and right edges of the map.
+// Each piece that ends at the seam ends with a point on the edge of the map, π times the radius and scale factor
+// from its middle before any output transformation, and the next piece begins at the corresponding point on the opposite edge.
+// Vertices on the seam are placed on the same edge of the map as their neighbors, regardless of WithSeamSide. Vertices at the poles
+// project to points with infinite y coordinates. ProjectPolyline returns nil if line is empty.
+func (gm *GeneralizedMercator) ProjectPolyline(line s2.Polyline) [][]r2.Point {
+	if len(line) == 0 {
+		return nil
+	}
+	edge := math.Pi * gm.radius * gm.k0
+	var pieces [][]r2.Point
+	piece := []r2.Point{gm.projectCentered(line[0])}
+	for n := 1; n < len(line); n++ {
+		if c, ok := gm.seamCrossing(line[n-1], line[n]); ok {
+			x := math.Copysign(edge, piece[len(piece)-1].X)
+			y := gm.projectCentered(c).Y
+			pieces = append(pieces, append(piece, r2.Point{X: x, Y: y}))
+			piece = []r2.Point{{X: -x, Y: y}}
+		}
+		p := gm.projectCentered(line[n])
+		if n+1 < len(line) && gm.crossesAtVertex(line[n-1], line[n], line[n+1]) {
+			// The polyline crosses the seam at a vertex, which ends one piece and begins the next.
+			x := math.Copysign(edge, piece[len(piece)-1].X)
+			pieces = append(pieces, append(piece, r2.Point{X: x, Y: p.Y}))
+			piece = []r2.Point{{X: -x, Y: p.Y}}
+			continue
+		}
+		piece = append(piece, p)
+	}
+	pieces = append(pieces, piece)
+	for _, piece := range pieces {
+		gm.alignSeamVertices(piece)
+		for n, p := range piece {
+			piece[n] = gm.fromCentered(p)
+		}
+	}
+	return pieces
+}
+
+// projectCentered converts p to a projected 2D point before the output transformation,
+// with the x coordinate measured from the middle of the map.
+func (gm *GeneralizedMercator) projectCentered(p s2.Point) r2.Point {
+	q := gm.projectPoint(p)
+	q.X -= gm.cut * gm.radius * gm.k0
+	return q
+}
+
+// fromCentered returns the projected point corresponding to p, whose x coordinate is measured from the middle of the map
+// before the output transformation.
+func (gm *GeneralizedMercator) fromCentered(p r2.Point) r2.Point {
+	p.X += gm.cut * gm.radius * gm.k0
+	return gm.out.apply(p)
+}
+
+// alignSeamVertices moves each point of piece that lies on an edge of the map to the edge nearer its neighbors.
+func (gm *GeneralizedMercator) alignSeamVertices(piece []r2.Point) {
+	edge := math.Pi * gm.radius * gm.k0
+	onSeam := func(p r2.Point) bool { return math.Abs(math.Abs(p.X)-edge) <= 1e-12*edge }
+	for n, p := range piece {
+		if !onSeam(p) {
+			continue
+		}
+		for _, m := range []int{n - 1, n + 1} {
+			if m >= 0 && m < len(piece) && !onSeam(piece[m]) && !math.IsInf(piece[m].Y, 0) {
+				piece[n].X = math.Copysign(edge, piece[m].X)
+				break
+			}
+		}
+	}
+}
+
+// WrapDistance returns the period of the projection in the plane: points p and p + WrapDistance() unproject
+// to the same location. Unless the projection has a rotating or shearing output transformation,
+// the period lies along the x axis, and WrapDistance follows the convention of s2.Projection.
+func (gm *GeneralizedMercator) WrapDistance() r2.Point {
+	t := gm.out
+	t.e, t.f = 0, 0
+	return t.apply(r2.Point{X: 2 * math.Pi * gm.radius * gm.k0})
+}
+
+// WrapDestination returns the point equivalent to b, differing from it by a whole number of periods,
+// that is nearest to a along the direction of the period.
+// The edge from a to the result then takes the shorter way around the projection.
+// If either point has an infinite coordinate, WrapDestination returns b.
+func (gm *GeneralizedMercator) WrapDestination(a, b r2.Point) r2.Point {
+	if !isFinite(a) || !isFinite(b) {
+		return b
+	}
+	w := gm.WrapDistance()
+	n := math.Round(b.Sub(a).Dot(w) / w.Dot(w))
+	return b.Sub(w.Mul(n))
+}
+
+// Unwrap adjusts each point of the track pts after the first by a whole number of periods to make it nearest
+// to its predecessor, so that a track crossing the seam continues past the edge of the projection
+// instead of jumping to the opposite edge. The points are modified in place.
+func (gm *GeneralizedMercator) Unwrap(pts []r2.Point) {
+	for n := 1; n < len(pts); n++ {
+		pts[n] = gm.WrapDestination(pts[n-1], pts[n])
+	}
+}
+
+// Seam returns a polyline from Pos to Neg that approximates the seam to within tolerance, which must be positive.
+// If the poles are antipodal, the seam is half of a great circle, and Seam returns it as two edges
+// that meet at the generalized equator. Otherwise the seam is an arc of a small circle.
+func (gm *GeneralizedMercator) Seam(tolerance s1.Angle) s2.Polyline {
+	x := gm.x0 + gm.cut + math.Pi
+	f := func(psi float64) s2.Point { return s2.Point{gm.fromGeneralized(x, psi)} }
+	line := tessellate(f, math.Pi/2, 0, tolerance)
+	return append(line, tessellate(f, 0, -math.Pi/2, tolerance)[1:]...)
+}
@@ -0,0 +1,232 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s2"
+)
+
+// polyline returns the polyline with vertices at the given latitudes and longitudes in degrees.
+func polyline(lls ...[2]float64) s2.Polyline {
+	var line s2.Polyline
+	for _, ll := range lls {
+		line = append(line, s2.PointFromLatLng(s2.LatLngFromDegrees(ll[0], ll[1])))
+	}
+	return line
+}
+
+func TestProjectPolyline(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, test := range []struct {
+		gm   *GeneralizedMercator
+		line s2.Polyline
+		want [][]r2.Point
+	}{
+		{mercator, nil, nil},
+		{
+			mercator,
+			polyline([2]float64{0, 10}, [2]float64{0, 20}),
+			[][]r2.Point{{{X: 10 * pi / 180}, {X: 20 * pi / 180}}},
+		},
+		{
+			mercator,
+			polyline([2]float64{0, 170}, [2]float64{0, -170}, [2]float64{0, -160}),
+			[][]r2.Point{
+				{{X: 170 * pi / 180}, {X: pi}},
+				{{X: -pi}, {X: -170 * pi / 180}, {X: -160 * pi / 180}},
+			},
+		},
+		{
+			mercator,
+			polyline([2]float64{0, -170}, [2]float64{0, 170}, [2]float64{0, -170}),
+			[][]r2.Point{
+				{{X: -170 * pi / 180}, {X: -pi}},
+				{{X: pi}, {X: 170 * pi / 180}, {X: pi}},
+				{{X: -pi}, {X: -170 * pi / 180}},
+			},
+		},
+		{
+			// A vertex on the seam stays on the side of its neighbor.
+			mercator,
+			polyline([2]float64{0, 170}, [2]float64{0, 180}),
+			[][]r2.Point{{{X: 170 * pi / 180}, {X: pi}}},
+		},
+		{
+			mercator,
+			polyline([2]float64{0, -170}, [2]float64{0, 180}),
+			[][]r2.Point{{{X: -170 * pi / 180}, {X: -pi}}},
+		},
+		{
+			// A polyline that crosses the seam at a vertex is split there.
+			mercator,
+			polyline([2]float64{0, 170}, [2]float64{0, 180}, [2]float64{0, -170}),
+			[][]r2.Point{{{X: 170 * pi / 180}, {X: pi}}, {{X: -pi}, {X: -170 * pi / 180}}},
+		},
+		{
+			New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeam(pi/2)),
+			polyline([2]float64{0, 80}, [2]float64{0, 100}, [2]float64{0, 170}, [2]float64{0, -170}),
+			[][]r2.Point{
+				{{X: 80 * pi / 180}, {X: pi / 2}},
+				{{X: -3 * pi / 2}, {X: -260 * pi / 180}, {X: -190 * pi / 180}, {X: -170 * pi / 180}},
+			},
+		},
+	} {
+		got := test.gm.ProjectPolyline(test.line)
+		if !piecesApproxEqual(got, test.want) {
+			t.Errorf("ProjectPolyline(%v, %v): got %v, want %v", test.gm, test.line, got, test.want)
+		}
+	}
+
+	// In every projection, a densely sampled circle around a pole crosses the seam exactly once.
+	for _, test := range projTests {
+		gm := New(test.gm.Poles())
+		var line s2.Polyline
+		for n := 0; n <= 360; n += 5 {
+			line = append(line, gm.UnprojectPoint(r2.Point{X: (float64(n) + 2.5) * pi / 180, Y: 0.5}))
+		}
+		got := gm.ProjectPolyline(line)
+		if len(got) != 2 {
+			t.Errorf("ProjectPolyline(%v, circle): got %d pieces, want 2", gm, len(got))
+			continue
+		}
+		for _, piece := range got {
+			for n := 1; n < len(piece); n++ {
+				if d := math.Abs(piece[n].X - piece[n-1].X); d > 0.1 || math.Abs(piece[n].Y-0.5) > 1e-3 {
+					t.Errorf("ProjectPolyline(%v, circle): got %v → %v", gm, piece[n-1], piece[n])
+				}
+			}
+		}
+		if end, start := got[0][len(got[0])-1], got[1][0]; end.X != pi || start.X != -pi {
+			t.Errorf("ProjectPolyline(%v, circle): got seam points %v, %v", gm, end, start)
+		}
+	}
+}
+
+func piecesApproxEqual(a, b [][]r2.Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if len(a[n]) != len(b[n]) {
+			return false
+		}
+		for m := range a[n] {
+			if !ptApproxEqual(a[n][m], b[n][m]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestEdgeSeamCrossing(t *testing.T) {
+	for _, test := range []struct {
+		gm    *GeneralizedMercator
+		a, b  s2.LatLng
+		cross bool
+	}{
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), s2.LatLngFromDegrees(10, 170), s2.LatLngFromDegrees(-10, -170), true},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), s2.LatLngFromDegrees(10, -10), s2.LatLngFromDegrees(-10, 10), false},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), s2.LatLngFromDegrees(10, 20), s2.LatLngFromDegrees(-10, 30), false},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), s2.LatLngFromDegrees(10, 180), s2.LatLngFromDegrees(-10, 170), false},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-10, 170), false},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeam(pi/2)), s2.LatLngFromDegrees(0, 80), s2.LatLngFromDegrees(0, 100), true},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithSeam(pi/2)), s2.LatLngFromDegrees(0, 170), s2.LatLngFromDegrees(0, -170), false},
+		{New(s2.LatLngFromDegrees(60, 30), s2.LatLngFromDegrees(-60, 30)), s2.LatLngFromDegrees(0, -140), s2.LatLngFromDegrees(0, -160), true},
+	} {
+		a, b := s2.PointFromLatLng(test.a), s2.PointFromLatLng(test.b)
+		if got := test.gm.DoesEdgeCrossSeam(a, b); got != test.cross {
+			t.Errorf("DoesEdgeCrossSeam(%v, %v, %v): got %v, want %v", test.gm, test.a, test.b, got, test.cross)
+		}
+		c, ok := test.gm.EdgeSeamCrossing(a, b)
+		if ok != test.cross {
+			t.Errorf("EdgeSeamCrossing(%v, %v, %v): got %v, want %v", test.gm, test.a, test.b, ok, test.cross)
+		}
+		if !ok {
+			continue
+		}
+		if d := s2.DistanceFromSegment(c, a, b); d > 1e-14 {
+			t.Errorf("EdgeSeamCrossing(%v, %v, %v): %v is %v from the edge", test.gm, test.a, test.b, c, d)
+		}
+		if x, _ := test.gm.generalized(c.Vector); math.Abs(math.Remainder(x-test.gm.x0-test.gm.cut-pi, 2*pi)) > 1e-12 {
+			t.Errorf("EdgeSeamCrossing(%v, %v, %v): %v is not on the seam", test.gm, test.a, test.b, c)
+		}
+	}
+}
+
+func TestWrapDistance(t *testing.T) {
+	for _, test := range []struct {
+		gm   *GeneralizedMercator
+		want r2.Point
+	}{
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)), r2.Point{X: 2 * math.Pi}},
+		{New(s2.LatLngFromDegrees(30, 0), s2.LatLngFromDegrees(-60, 10), WithRadius(2), WithScaleFactor(0.5)), r2.Point{X: 2 * math.Pi}},
+		{NewWebMercator(), r2.Point{X: 2 * math.Pi * WebMercatorRadius}},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithFalseOrigin(5, -2)), r2.Point{X: 2 * math.Pi}},
+		{New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithAffine(0, -1, 1, 0, 2, 3)), r2.Point{Y: 2 * math.Pi}},
+	} {
+		got := test.gm.WrapDistance()
+		if !ptApproxEqual(got, test.want) {
+			t.Errorf("WrapDistance(%v): got %v, want %v", test.gm, got, test.want)
+		}
+		p := r2.Point{X: 0.3, Y: 0.7}
+		if a, b := test.gm.UnprojectPoint(p), test.gm.UnprojectPoint(p.Add(got)); !a.ApproxEqual(b) {
+			t.Errorf("UnprojectPoint(%v + WrapDistance): got %v, want %v", p, b, a)
+		}
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	for _, gm := range []*GeneralizedMercator{
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)),
+		New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0), WithAffine(0, -1, 1, 0, 2, 3)),
+	} {
+		var pts []r2.Point
+		for lng := 170.0; lng <= 200; lng += 5 {
+			pts = append(pts, gm.Project(s2.LatLngFromDegrees(10, lng)))
+		}
+		gm.Unwrap(pts)
+		start := gm.Project(s2.LatLngFromDegrees(10, 170))
+		w := gm.WrapDistance().Mul(1 / (2 * math.Pi))
+		for n, p := range pts {
+			// Each step of 5 degrees of longitude advances by 5 degrees along the period.
+			if want := start.Add(w.Mul(float64(n) * 5 * math.Pi / 180)); !ptApproxEqual(p, want) {
+				t.Errorf("Unwrap(%v): point %d: got %v, want %v", gm, n, p, want)
+			}
+		}
+	}
+}
+
+func TestSeam(t *testing.T) {
+	const tolerance = 1e-6
+	for _, test := range projTests {
+		for _, gm := range []*GeneralizedMercator{
+			New(test.gm.Poles()),
+			New(test.gm.Poles()).Rotated(1),
+		} {
+			seam := gm.Seam(tolerance)
+			if !seam[0].ApproxEqual(s2.Point{gm.pos}) || !seam[len(seam)-1].ApproxEqual(s2.Point{gm.neg}) {
+				t.Errorf("Seam(%v): got endpoints %v, %v, want %v, %v", gm, seam[0], seam[len(seam)-1], gm.pos, gm.neg)
+			}
+			if gm.IsAntipodalPoles() && len(seam) != 3 {
+				t.Errorf("Seam(%v): got %d vertices, want 3", gm, len(seam))
+			}
+			// The vertices lie on the seam, and so project to the edge of the map.
+			for n, v := range seam[1 : len(seam)-1] {
+				if got := gm.ProjectPoint(v); math.Abs(math.Abs(got.X)-math.Pi) > 1e-12 {
+					t.Errorf("Seam(%v): vertex %d projects to %v", gm, n+1, got)
+				}
+			}
+			// Every point of the seam is near the polyline.
+			for psi := -math.Pi / 2; psi <= math.Pi/2; psi += math.Pi / 360 {
+				p := s2.Point{gm.fromGeneralized(gm.x0+math.Pi, psi)}
+				if d := distanceFromPolyline(p, seam); d > 1.01*tolerance {
+					t.Errorf("Seam(%v): point with generalized latitude %v is %v from the polyline", gm, psi, d)
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package gm
+
+// ShaderUniforms are the parameters of a projection in the single-precision form in which a GPU shader takes them,
+// for reprojecting imagery on the GPU with the same computations as Project and Unproject.
+// The functions in GLSLSource and WGSLSource take them as a struct GMUniforms with the same fields in the same order,
+// named in lower case; laying them out in a uniform buffer according to the rules of the shading language
+// is the responsibility of the host program.
+//
+// The shader functions differ from Project and Unproject in precision, in placing locations on the seam
+// at either edge of the map as computed regardless of any WithSeamSide option, and in not treating the poles
+// specially: their projections and unprojections are undefined or lose all precision, as at a pole of a texture.
+type ShaderUniforms struct {
+	// Basis holds the basis vectors i, j, and k of the projection, one after the other, so that it is the
+	// column-major representation of the matrix whose columns are i, j, and k. It takes a point on the unit sphere
+	// to the basis when applied to the point as a row vector, and back when applied to a column vector.
+	Basis [9]float32
+
+	// DInv is the reciprocal of the tangent distance, or 0 if the poles are antipodal.
+	DInv float32
+
+	// Scale is the factor by which projected coordinates are scaled: the radius of the reference sphere
+	// times the scale factor along the generalized equator.
+	Scale float32
+
+	// X0 is the projective longitude of the central line, measured from the i axis,
+	// and Cut is that of the middle of the map, measured from the central line.
+	X0, Cut float32
+
+	// YMax is the y coordinate before scaling at which the projection is truncated, or 0 if it is not truncated.
+	YMax float32
+
+	// LatScale is the factor by which the tangent of a latitude is multiplied to give that of the corresponding latitude
+	// on the reference sphere: the square of 1 minus the flattening, or 1 if latitudes are spherical.
+	LatScale float32
+
+	// AffineX and AffineY are the coefficients (a, b, e) and (c, d, f) of the output transformation,
+	// which maps (x, y) to (a*x + b*y + e, c*x + d*y + f).
+	AffineX, AffineY [3]float32
+}
+
+// ShaderUniforms returns the parameters of gm for the shader functions in GLSLSource and WGSLSource.
+func (gm *GeneralizedMercator) ShaderUniforms() ShaderUniforms {
+	u := ShaderUniforms{
+		DInv:     float32(gm.dinv),
+		Scale:    float32(gm.radius * gm.k0),
+		X0:       float32(gm.x0),
+		Cut:      float32(gm.cut),
+		LatScale: float32((1 - gm.flattening) * (1 - gm.flattening)),
+		AffineX:  [3]float32{float32(gm.out.a), float32(gm.out.b), float32(gm.out.e)},
+		AffineY:  [3]float32{float32(gm.out.c), float32(gm.out.d), float32(gm.out.f)},
+	}
+	for n, v := range []float64{gm.i.X, gm.i.Y, gm.i.Z, gm.j.X, gm.j.Y, gm.j.Z, gm.k.X, gm.k.Y, gm.k.Z} {
+		u.Basis[n] = float32(v)
+	}
+	if gm.psiMax != 0 {
+		u.YMax = float32(yFromPsi(gm.psiMax))
+	}
+	return u
+}
+
+// GLSLSource is GLSL source code declaring the struct GMUniforms, which holds ShaderUniforms, and the functions
+//
+//	vec3 gm_point(GMUniforms gm, vec2 latlng)
+//	vec2 gm_latlng(GMUniforms gm, vec3 p)
+//	vec2 gm_project(GMUniforms gm, vec3 p)
+//	vec3 gm_unproject(GMUniforms gm, vec2 xy)
+//
+// which convert between latitude and longitude in radians and points on the unit sphere,
+// and project and unproject such points like ProjectPoint and UnprojectPoint.
+const GLSLSource = `struct GMUniforms {
+	mat3 basis;
+	float dinv;
+	float scale;
+	float x0;
+	float cut;
+	float ymax;
+	float latscale;
+	vec3 affinex;
+	vec3 affiney;
+};
+
+const float GM_PI = 3.14159265358979323846;
+
+vec3 gm_point(GMUniforms gm, vec2 latlng) {
+	float lat = atan(gm.latscale * sin(latlng.x), cos(latlng.x));
+	return vec3(cos(lat) * cos(latlng.y), cos(lat) * sin(latlng.y), sin(lat));
+}
+
+vec2 gm_latlng(GMUniforms gm, vec3 p) {
+	float lat = atan(p.z, length(p.xy));
+	return vec2(atan(sin(lat), gm.latscale * cos(lat)), atan(p.y, p.x));
+}
+
+vec2 gm_project(GMUniforms gm, vec3 p) {
+	vec3 v = p * gm.basis;
+	vec2 beta = normalize(vec2(1.0 - v.x * gm.dinv, v.z * gm.dinv));
+	float u = v.x * beta.x - v.z * beta.y;
+	float w = v.z * beta.x + v.x * beta.y;
+	float x = atan(v.y, u) - gm.x0 - gm.cut;
+	x = x - 2.0 * GM_PI * floor((x + GM_PI) / (2.0 * GM_PI)) + gm.cut;
+	float psi = atan(w, length(vec2(v.y, u)));
+	float y = asinh(tan(psi));
+	if (gm.ymax > 0.0) {
+		y = clamp(y, -gm.ymax, gm.ymax);
+	}
+	vec3 q = vec3(vec2(x, y) * gm.scale, 1.0);
+	return vec2(dot(gm.affinex, q), dot(gm.affiney, q));
+}
+
+vec3 gm_unproject(GMUniforms gm, vec2 xy) {
+	vec2 q = xy - vec2(gm.affinex.z, gm.affiney.z);
+	float det = gm.affinex.x * gm.affiney.y - gm.affinex.y * gm.affiney.x;
+	q = vec2(gm.affiney.y * q.x - gm.affinex.y * q.y, gm.affinex.x * q.y - gm.affiney.x * q.x) / (det * gm.scale);
+	float x = q.x + gm.x0;
+	float psi = atan(sinh(q.y));
+	float sinbeta = sin(psi) * gm.dinv;
+	float cosbeta = sqrt(1.0 - sinbeta * sinbeta);
+	float u = cos(psi) * cos(x);
+	return gm.basis * vec3(u * cosbeta + sin(psi) * sinbeta, cos(psi) * sin(x), sin(psi) * cosbeta - u * sinbeta);
+}
+`
+
+// WGSLSource is WGSL source code declaring the struct GMUniforms, which holds ShaderUniforms, and the functions
+//
+//	fn gm_point(gm: GMUniforms, latlng: vec2<f32>) -> vec3<f32>
+//	fn gm_latlng(gm: GMUniforms, p: vec3<f32>) -> vec2<f32>
+//	fn gm_project(gm: GMUniforms, p: vec3<f32>) -> vec2<f32>
+//	fn gm_unproject(gm: GMUniforms, xy: vec2<f32>) -> vec3<f32>
+//
+// which are equivalent to those of GLSLSource.
+const WGSLSource = `struct GMUniforms {
+	basis: mat3x3<f32>,
+	dinv: f32,
+	scale: f32,
+	x0: f32,
+	cut: f32,
+	ymax: f32,
+	latscale: f32,
+	affinex: vec3<f32>,
+	affiney: vec3<f32>,
+};
+
+const GM_PI: f32 = 3.14159265358979323846;
+
+fn gm_point(gm: GMUniforms, latlng: vec2<f32>) -> vec3<f32> {
+	let lat = atan2(gm.latscale * sin(latlng.x), cos(latlng.x));
+	return vec3<f32>(cos(lat) * cos(latlng.y), cos(lat) * sin(latlng.y), sin(lat));
+}
+
+fn gm_latlng(gm: GMUniforms, p: vec3<f32>) -> vec2<f32> {
+	let lat = atan2(p.z, length(p.xy));
+	return vec2<f32>(atan2(sin(lat), gm.latscale * cos(lat)), atan2(p.y, p.x));
+}
+
+fn gm_project(gm: GMUniforms, p: vec3<f32>) -> vec2<f32> {
+	let v = p * gm.basis;
+	let beta = normalize(vec2<f32>(1.0 - v.x * gm.dinv, v.z * gm.dinv));
+	let u = v.x * beta.x - v.z * beta.y;
+	let w = v.z * beta.x + v.x * beta.y;
+	var x = atan2(v.y, u) - gm.x0 - gm.cut;
+	x = x - 2.0 * GM_PI * floor((x + GM_PI) / (2.0 * GM_PI)) + gm.cut;
+	let psi = atan2(w, length(vec2<f32>(v.y, u)));
+	var y = asinh(tan(psi));
+	if (gm.ymax > 0.0) {
+		y = clamp(y, -gm.ymax, gm.ymax);
+	}
+	let q = vec3<f32>(vec2<f32>(x, y) * gm.scale, 1.0);
+	return vec2<f32>(dot(gm.affinex, q), dot(gm.affiney, q));
+}
+
+fn gm_unproject(gm: GMUniforms, xy: vec2<f32>) -> vec3<f32> {
+	var q = xy - vec2<f32>(gm.affinex.z, gm.affiney.z);
+	let det = gm.affinex.x * gm.affiney.y - gm.affinex.y * gm.affiney.x;
+	q = vec2<f32>(gm.affiney.y * q.x - gm.affinex.y * q.y, gm.affinex.x * q.y - gm.affiney.x * q.x) / (det * gm.scale);
+	let x = q.x + gm.x0;
+	let psi = atan(sinh(q.y));
+	let sinbeta = sin(psi) * gm.dinv;
+	let cosbeta = sqrt(1.0 - sinbeta * sinbeta);
+	let u = cos(psi) * cos(x);
+	return gm.basis * vec3<f32>(u * cosbeta + sin(psi) * sinbeta, cos(psi) * sin(x), sin(psi) * cosbeta - u * sinbeta);
+}
+`
@@ -0,0 +1,89 @@
+package gm
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s2"
+)
+
+// shaderProject and shaderUnproject follow the computations of gm_project and gm_unproject in the shader sources.
+func shaderProject(u ShaderUniforms, p r3.Vector) r2.Point {
+	b := func(n int) r3.Vector {
+		return r3.Vector{X: float64(u.Basis[3*n]), Y: float64(u.Basis[3*n+1]), Z: float64(u.Basis[3*n+2])}
+	}
+	dinv := float64(u.DInv)
+	a, bb, c := p.Dot(b(0)), p.Dot(b(1)), p.Dot(b(2))
+	beta := r2.Point{X: 1 - a*dinv, Y: c * dinv}.Normalize()
+	s, w := a*beta.X-c*beta.Y, c*beta.X+a*beta.Y
+	x := math.Atan2(bb, s) - float64(u.X0) - float64(u.Cut)
+	x = x - 2*math.Pi*math.Floor((x+math.Pi)/(2*math.Pi)) + float64(u.Cut)
+	y := math.Asinh(math.Tan(math.Atan2(w, math.Hypot(bb, s))))
+	if u.YMax > 0 {
+		y = math.Max(-float64(u.YMax), math.Min(y, float64(u.YMax)))
+	}
+	x, y = x*float64(u.Scale), y*float64(u.Scale)
+	return r2.Point{
+		X: float64(u.AffineX[0])*x + float64(u.AffineX[1])*y + float64(u.AffineX[2]),
+		Y: float64(u.AffineY[0])*x + float64(u.AffineY[1])*y + float64(u.AffineY[2]),
+	}
+}
+
+func shaderUnproject(u ShaderUniforms, p r2.Point) r3.Vector {
+	ax, ay := u.AffineX, u.AffineY
+	q := r2.Point{X: p.X - float64(ax[2]), Y: p.Y - float64(ay[2])}
+	det := float64(ax[0])*float64(ay[1]) - float64(ax[1])*float64(ay[0])
+	q = r2.Point{X: float64(ay[1])*q.X - float64(ax[1])*q.Y, Y: float64(ax[0])*q.Y - float64(ay[0])*q.X}.Mul(1 / (det * float64(u.Scale)))
+	x := q.X + float64(u.X0)
+	psi := math.Atan(math.Sinh(q.Y))
+	sinBeta := math.Sin(psi) * float64(u.DInv)
+	cosBeta := math.Sqrt(1 - sinBeta*sinBeta)
+	w := math.Cos(psi) * math.Cos(x)
+	v := [3]float64{w*cosBeta + math.Sin(psi)*sinBeta, math.Cos(psi) * math.Sin(x), math.Sin(psi)*cosBeta - w*sinBeta}
+	var r r3.Vector
+	for n := 0; n < 3; n++ {
+		r = r.Add(r3.Vector{X: float64(u.Basis[3*n]), Y: float64(u.Basis[3*n+1]), Z: float64(u.Basis[3*n+2])}.Mul(v[n]))
+	}
+	return r
+}
+
+func TestShaderUniforms(t *testing.T) {
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		for _, gm := range []*GeneralizedMercator{
+			New(pos, neg),
+			New(pos, neg, WithRadius(2), WithCentralLongitude(0.5), WithSeam(1), WithTruncation(1.2)),
+			New(pos, neg, WithAffine(0, -1, 1, 0, 2, 3)),
+		} {
+			u := gm.ShaderUniforms()
+			for lat := -75.0; lat <= 75; lat += 15 {
+				for lng := -165.0; lng <= 165; lng += 15 {
+					P := s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lng))
+					if P.Distance(s2.Point{gm.pos}) < 1e-3 || P.Distance(s2.Point{gm.neg}) < 1e-3 {
+						continue
+					}
+					want := gm.ProjectPoint(P)
+					if got := shaderProject(u, P.Vector); !(gm.WrapDestination(want, got).Sub(want).Norm() < 1e-5) {
+						t.Errorf("%v: shader projection of %v: got %v, want %v", gm, P, got, want)
+					}
+					if want := gm.UnprojectPoint(want); !(shaderUnproject(u, gm.ProjectPoint(P)).Sub(want.Vector).Norm() < 1e-5) {
+						t.Errorf("%v: shader unprojection of %v: got %v, want %v", gm, gm.ProjectPoint(P), shaderUnproject(u, gm.ProjectPoint(P)), want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestShaderSource(t *testing.T) {
+	for _, src := range []string{GLSLSource, WGSLSource} {
+		for _, name := range []string{"struct GMUniforms", "gm_point(", "gm_latlng(", "gm_project(", "gm_unproject("} {
+			if !strings.Contains(src, name) {
+				t.Errorf("shader source does not contain %q:\n%s", name, src)
+			}
+		}
+	}
+}
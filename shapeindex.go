@@ -0,0 +1,93 @@
+package gm
+
+import (
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A ProjectedShape is the projection of a shape in an s2.ShapeIndex, as produced by ProjectShapeIndex.
+// Only the field corresponding to the dimension of the shape is set.
+type ProjectedShape struct {
+	// ID is the ID of the shape in the index, and Shape is the shape itself.
+	ID    int32
+	Shape s2.Shape
+
+	// Dimension is the dimension of the shape: 0 for points, 1 for polylines, and 2 for polygons.
+	Dimension int
+
+	// Points are the projections of the points of a shape of dimension 0, in order.
+	Points []r2.Point
+
+	// Lines are the pieces of the chains of a shape of dimension 1, as produced by ProjectPolyline,
+	// in order of the chains.
+	Lines [][]r2.Point
+
+	// Rings are the rings of the interior of a shape of dimension 2, as produced by ProjectPolygon.
+	Rings [][]r2.Point
+}
+
+// ProjectShapeIndex projects every shape in index in order of increasing ID and calls f with each result,
+// stopping early if f returns false. The edges of polylines and polygons are densified so that each projected edge
+// deviates from the projection of the corresponding geodesic by at most maxErr, which must be positive, measured
+// as an angle on the reference sphere, and they are split along the seam. Projected y coordinates of polygons
+// are clamped to [-maxY, maxY] as by ProjectPolygon.
+//
+// The ProjectedShape passed to f and its Points are reused for the next shape, so f must copy any of them that it retains.
+func (gm *GeneralizedMercator) ProjectShapeIndex(index *s2.ShapeIndex, maxErr s1.Angle, maxY float64, f func(*ProjectedShape) bool) {
+	var (
+		ps    ProjectedShape
+		buf   []s2.Point   // the densified vertices of the current chain
+		rings [][]s2.Point // the densified chains of the current polygon
+		query = s2.NewContainsPointQuery(index, s2.VertexModelSemiOpen)
+	)
+	for id, n := int32(0), 0; n < index.Len(); id++ {
+		shape := index.Shape(id)
+		if shape == nil {
+			continue
+		}
+		n++
+		ps = ProjectedShape{ID: id, Shape: shape, Dimension: shape.Dimension(), Points: ps.Points[:0]}
+		switch ps.Dimension {
+		case 0:
+			for e := 0; e < shape.NumEdges(); e++ {
+				ps.Points = append(ps.Points, gm.ProjectPoint(shape.Edge(e).V0))
+			}
+		case 1:
+			for c := 0; c < shape.NumChains(); c++ {
+				chain := shape.Chain(c)
+				if chain.Length == 0 {
+					continue
+				}
+				buf = buf[:0]
+				for e := 0; e < chain.Length; e++ {
+					edge := shape.ChainEdge(c, e)
+					buf = gm.densifyEdge(buf, edge.V0, edge.V1, maxErr)
+				}
+				buf = append(buf, shape.ChainEdge(c, chain.Length-1).V1)
+				ps.Lines = append(ps.Lines, gm.ProjectPolyline(buf)...)
+			}
+		case 2:
+			rings = rings[:0]
+			for c := 0; c < shape.NumChains(); c++ {
+				chain := shape.Chain(c)
+				if chain.Length == 0 {
+					continue
+				}
+				var ring []s2.Point
+				for e := 0; e < chain.Length; e++ {
+					edge := shape.ChainEdge(c, e)
+					ring = gm.densifyEdge(ring, edge.V0, edge.V1, maxErr)
+				}
+				rings = append(rings, ring)
+			}
+			if len(rings) > 0 || shape.ReferencePoint().Contained {
+				contains := func(p s2.Point) bool { return query.ShapeContains(shape, p) }
+				ps.Rings = gm.outRings(gm.projectRings(rings, contains, maxY), 0)
+			}
+		}
+		if !f(&ps) {
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package gm
+
+import (
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s2"
+)
+
+func TestProjectShapeIndex(t *testing.T) {
+	const (
+		maxErr = 1e-6
+		maxY   = 3
+	)
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	deg := func(d float64) float64 { return d * pi / 180 }
+	index := s2.NewShapeIndex()
+	points := s2.PointVector{s2.PointFromLatLng(s2.LatLngFromDegrees(10, 20)), s2.PointFromLatLng(s2.LatLngFromDegrees(-30, 170))}
+	line := polyline([2]float64{40, 160}, [2]float64{40, -160})
+	polygon := s2.PolygonFromLoops([]*s2.Loop{loop([2]float64{-10, 170}, [2]float64{-10, -170}, [2]float64{10, -170}, [2]float64{10, 170})})
+	index.Add(&points)
+	index.Add(&line)
+	removed := index.Add(s2.PolygonFromLoops([]*s2.Loop{rectLoop(0, 0, 10, 10)}))
+	index.Add(polygon)
+	index.Remove(index.Shape(removed))
+
+	var ids []int32
+	gm.ProjectShapeIndex(index, maxErr, maxY, func(ps *ProjectedShape) bool {
+		ids = append(ids, ps.ID)
+		switch ps.ID {
+		case 0:
+			if ps.Dimension != 0 || len(ps.Points) != 2 || !ptApproxEqual(ps.Points[1], gm.ProjectPoint(points[1])) {
+				t.Errorf("ProjectShapeIndex: got points %v", ps.Points)
+			}
+		case 1:
+			if ps.Dimension != 1 || len(ps.Lines) != 2 {
+				t.Errorf("ProjectShapeIndex: got lines %v, want 2 pieces", ps.Lines)
+				break
+			}
+			// The geodesic from 160° to -160° at latitude 40° bulges poleward, unlike the straight chord.
+			mid := gm.Project(s2.LatLngFromDegrees(40, 175))
+			for _, piece := range ps.Lines {
+				for _, p := range piece {
+					if p.Y < mid.Y-1e-9 {
+						t.Errorf("ProjectShapeIndex: got line point %v below %v", p, mid)
+					}
+				}
+			}
+			if last := ps.Lines[1][len(ps.Lines[1])-1]; !ptApproxEqual(last, gm.Project(s2.LatLngFromDegrees(40, -160))) {
+				t.Errorf("ProjectShapeIndex: got line ending at %v", last)
+			}
+		case 3:
+			if ps.Dimension != 2 {
+				t.Errorf("ProjectShapeIndex: got dimension %d, want 2", ps.Dimension)
+			}
+			for _, test := range []struct {
+				p    r2.Point
+				want int
+			}{
+				{r2.Point{X: deg(175)}, 1},
+				{r2.Point{X: deg(-175)}, 1},
+				{r2.Point{}, 0},
+				{r2.Point{X: deg(175), Y: 1}, 0},
+			} {
+				if w := winding(ps.Rings, test.p); w != test.want {
+					t.Errorf("ProjectShapeIndex: got winding number %d around %v, want %d", w, test.p, test.want)
+				}
+			}
+		}
+		return true
+	})
+	if want := []int32{0, 1, 3}; len(ids) != len(want) || ids[0] != 0 || ids[1] != 1 || ids[2] != 3 {
+		t.Errorf("ProjectShapeIndex: visited shapes %v, want %v", ids, want)
+	}
+
+	// Returning false stops the walk.
+	var n int
+	gm.ProjectShapeIndex(index, maxErr, maxY, func(*ProjectedShape) bool { n++; return false })
+	if n != 1 {
+		t.Errorf("ProjectShapeIndex: visited %d shapes after stopping, want 1", n)
+	}
+
+	full := s2.NewShapeIndex()
+	full.Add(s2.FullPolygon())
+	gm.ProjectShapeIndex(full, maxErr, maxY, func(ps *ProjectedShape) bool {
+		if w := winding(ps.Rings, r2.Point{X: 1, Y: 2}); w != 1 {
+			t.Errorf("ProjectShapeIndex: got rings %v for the full polygon", ps.Rings)
+		}
+		return true
+	})
+}
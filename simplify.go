@@ -0,0 +1,80 @@
+package gm
+
+import (
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// Simplify returns a subsequence of the projected polyline pts, including its first and last points, that deviates
+// from it by at most tolerance, measured as an angle on the reference sphere: the unprojection of each omitted point
+// is within tolerance of the preimage of the straight segment that replaces it. Since the error is measured
+// on the sphere, the tolerance has the same physical meaning everywhere on the map; a distance d in the units
+// of the radius corresponds to a tolerance of d / Radius() radians. Simplify uses the Douglas–Peucker algorithm.
+// Points with infinite coordinates and their neighbors are always retained.
+func (gm *GeneralizedMercator) Simplify(pts []r2.Point, tolerance s1.Angle) []r2.Point {
+	if len(pts) <= 2 {
+		return append([]r2.Point(nil), pts...)
+	}
+	keep := make([]bool, len(pts))
+	keep[0], keep[len(pts)-1] = true, true
+	for n, p := range pts {
+		if !isFinite(p) {
+			// The segments to a point at infinity have no finite preimage to compare against,
+			// so the point and its neighbors are retained.
+			for m := n - 1; m <= n+1; m++ {
+				if m >= 0 && m < len(pts) {
+					keep[m] = true
+				}
+			}
+		}
+	}
+	for lo, hi := 0, 1; hi < len(pts); hi++ {
+		if !keep[hi] {
+			continue
+		}
+		gm.simplify(pts, keep, lo, hi, tolerance)
+		lo = hi
+	}
+	var out []r2.Point
+	for n, p := range pts {
+		if keep[n] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// simplify marks in keep the points of pts between the indexes lo and hi that must be retained.
+func (gm *GeneralizedMercator) simplify(pts []r2.Point, keep []bool, lo, hi int, tolerance s1.Angle) {
+	if hi-lo < 2 {
+		return
+	}
+	// The preimage of the segment is approximated closely enough that the approximation error
+	// does not affect which points are retained by more than a small fraction of the tolerance.
+	line := gm.UnprojectSegment(pts[lo], pts[hi], tolerance/16)
+	var max s1.Angle
+	far := -1
+	for n := lo + 1; n < hi; n++ {
+		if d := distanceFromPolyline(gm.UnprojectPoint(pts[n]), line); d > max {
+			max, far = d, n
+		}
+	}
+	if max <= tolerance {
+		return
+	}
+	keep[far] = true
+	gm.simplify(pts, keep, lo, far, tolerance)
+	gm.simplify(pts, keep, far, hi, tolerance)
+}
+
+// distanceFromPolyline returns the distance from p to the nearest point of line.
+func distanceFromPolyline(p s2.Point, line s2.Polyline) s1.Angle {
+	d := p.Distance(line[0])
+	for n := 1; n < len(line); n++ {
+		if e := s2.DistanceFromSegment(p, line[n-1], line[n]); e < d {
+			d = e
+		}
+	}
+	return d
+}
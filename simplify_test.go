@@ -0,0 +1,83 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestSimplify(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	for _, test := range []struct {
+		pts       []r2.Point
+		tolerance s1.Angle
+		want      []r2.Point
+	}{
+		{nil, 0.01, nil},
+		{[]r2.Point{{X: 0}, {X: 1}}, 0.01, []r2.Point{{X: 0}, {X: 1}}},
+		{[]r2.Point{{X: 0}, {X: 0.5}, {X: 1}}, 0.01, []r2.Point{{X: 0}, {X: 1}}},
+		{[]r2.Point{{X: 0}, {X: 0.5, Y: 0.005}, {X: 1}}, 0.01, []r2.Point{{X: 0}, {X: 1}}},
+		{[]r2.Point{{X: 0}, {X: 0.5, Y: 0.02}, {X: 1}}, 0.01, []r2.Point{{X: 0}, {X: 0.5, Y: 0.02}, {X: 1}}},
+		{
+			[]r2.Point{{X: 0}, {X: 0.1, Y: 0.001}, {X: 0.2, Y: 0.3}, {X: 0.3, Y: 0.001}, {X: 0.4}},
+			0.01,
+			[]r2.Point{{X: 0}, {X: 0.1, Y: 0.001}, {X: 0.2, Y: 0.3}, {X: 0.3, Y: 0.001}, {X: 0.4}},
+		},
+		{
+			[]r2.Point{{X: 0}, {X: 0.1, Y: 0.001}, {X: 0.2, Y: 0.3}, {X: 0.3, Y: 0.001}, {X: 0.4}},
+			0.1,
+			[]r2.Point{{X: 0}, {X: 0.2, Y: 0.3}, {X: 0.4}},
+		},
+		{
+			// Points at infinity are retained.
+			[]r2.Point{{X: 0}, {X: 0.05}, {X: 0.1}, {Y: math.Inf(1)}, {X: 0.3}, {X: 0.35}, {X: 0.4}},
+			0.01,
+			[]r2.Point{{X: 0}, {X: 0.1}, {Y: math.Inf(1)}, {X: 0.3}, {X: 0.4}},
+		},
+	} {
+		got := mercator.Simplify(test.pts, test.tolerance)
+		if len(got) != len(test.want) {
+			t.Errorf("Simplify(%v, %v): got %v, want %v", test.pts, test.tolerance, got, test.want)
+			continue
+		}
+		for n := range got {
+			if got[n] != test.want[n] {
+				t.Errorf("Simplify(%v, %v): got %v, want %v", test.pts, test.tolerance, got, test.want)
+				break
+			}
+		}
+	}
+
+	// The same displacement in the plane is a smaller error on the sphere at high latitudes.
+	high := []r2.Point{{X: 0, Y: 2}, {X: 0.5, Y: 2.02}, {X: 1, Y: 2}}
+	if got := mercator.Simplify(high, 0.01); len(got) != 2 {
+		t.Errorf("Simplify(%v, 0.01): got %v, want 2 points", high, got)
+	}
+
+	// Every omitted point is within the tolerance of the simplified polyline on the sphere.
+	for _, test := range projTests {
+		gm := New(test.gm.Poles())
+		var pts []r2.Point
+		for n := 0; n <= 100; n++ {
+			x := float64(n) / 50
+			pts = append(pts, r2.Point{X: x - 1, Y: 0.3 * math.Sin(5*x)})
+		}
+		const tolerance = 0.002
+		got := gm.Simplify(pts, tolerance)
+		if len(got) >= len(pts) || got[0] != pts[0] || got[len(got)-1] != pts[len(pts)-1] {
+			t.Errorf("Simplify(%v): got %d of %d points", gm, len(got), len(pts))
+		}
+		var line s2.Polyline
+		for n := 1; n < len(got); n++ {
+			line = append(line, gm.UnprojectSegment(got[n-1], got[n], tolerance/100)...)
+		}
+		for _, p := range pts {
+			if d := distanceFromPolyline(gm.UnprojectPoint(p), line); d > 1.1*tolerance {
+				t.Errorf("Simplify(%v): %v is %v from the simplified polyline", gm, p, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// maxSubdivisions limits the depth of adaptive subdivision, so that each edge is divided into at most 2^maxSubdivisions parts.
+const maxSubdivisions = 24
+
+// maxPoints limits the number of vertices that UnprojectSegment and ProjectEdge return for a single segment or edge.
+// Once it is reached, the remaining parts are not subdivided further.
+const maxPoints = 1 << 16
+
+// UnprojectSegment returns a polyline on the reference sphere that approximates the preimage of the straight segment
+// from a to b in the plane to within tolerance, which must be positive: every point of the preimage is within tolerance
+// of the polyline, and every point of the polyline is within tolerance of the preimage. The polyline begins
+// at the unprojection of a and ends at the unprojection of b, and its vertices are the unprojections of points
+// on the segment, chosen adaptively. It has at most maxPoints vertices; if that limit or the limit on the depth
+// of subdivision is reached first, the bound does not hold.
+func (gm *GeneralizedMercator) UnprojectSegment(a, b r2.Point, tolerance s1.Angle) s2.Polyline {
+	p, q := gm.unscaled(a), gm.unscaled(b)
+	f := func(t float64) s2.Point { return gm.UnprojectPoint(gm.Interpolate(t, a, b)) }
+	A, B := f(0), f(1)
+	line := gm.subdivideSegment(s2.Polyline{A}, f, p, q, 0, 1, A, B, tolerance, maxSubdivisions)
+	return append(line, B)
+}
+
+// subdivideSegment appends to line the interior vertices of the approximation of the preimage of the part
+// of the segment from p to q, before scaling, between the parameters t0 and t1, whose unprojections are A and B.
+func (gm *GeneralizedMercator) subdivideSegment(line s2.Polyline, f func(t float64) s2.Point, p, q r2.Point, t0, t1 float64, A, B s2.Point, tolerance s1.Angle, depth int) s2.Polyline {
+	if depth == 0 || len(line) >= maxPoints-1 {
+		return line
+	}
+	if gm.chordError(gm.Interpolate(t0, p, q), gm.Interpolate(t1, p, q), A, B) <= tolerance {
+		return line
+	}
+	tm := (t0 + t1) / 2
+	M := f(tm)
+	line = gm.subdivideSegment(line, f, p, q, t0, tm, A, M, tolerance, depth-1)
+	if len(line) >= maxPoints-1 {
+		return line
+	}
+	line = append(line, M)
+	return gm.subdivideSegment(line, f, p, q, tm, t1, M, B, tolerance, depth-1)
+}
+
+// chordError returns an upper bound on the distance between the unprojection of the straight segment from p to q,
+// whose endpoints are given before scaling and any output transformation, and the geodesic edge between
+// the unprojections P and Q of its endpoints, measured both ways, or π if no bound follows.
+func (gm *GeneralizedMercator) chordError(p, q r2.Point, P, Q s2.Point) s1.Angle {
+	// Let F(s) be the unprojection of p + s(q-p) and C(s) = (1-s)P + sQ, whose direction traces the edge PQ
+	// as s runs from 0 to 1. Since F(s) is a unit vector, the sine of its angle from C(s) is at most |F(s) - C(s)|,
+	// which by the error bound of linear interpolation is at most max|F''|/8, provided the angle is acute.
+	v := q.Sub(p)
+	if !isFinite(v) || P.Dot(Q.Vector) < 0 {
+		return math.Pi
+	}
+	e := gm.secondDerivative(v, minAbs(p.Y, q.Y)) / 8
+	// The angle is acute if e < |C(s)|, which is at least cos(π/4) since the edge PQ is no longer than π/2.
+	if !(e <= 0.5) {
+		return math.Pi
+	}
+	return s1.Angle(math.Asin(e))
+}
+
+// secondDerivative returns an upper bound on the magnitude of the second derivative in the direction v
+// of the unprojection to the reference sphere of points before scaling whose y coordinates have magnitude at least y.
+func (gm *GeneralizedMercator) secondDerivative(v r2.Point, y float64) float64 {
+	// As in fromParallel, the unprojection is F = R(β)w, where w = (cos(ψ)cos(x), cos(ψ)sin(x), sin(ψ)) and R(β)
+	// is the rotation by β around the j axis. With c = cos(ψ) = sech(y), so that ψ' = c and c' = -c sin(ψ),
+	// the partial derivatives of w have |w_x| = |w_y| = |w_xx| = |w_yy| = c and |w_xy| <= c. Differentiating
+	// sin(β) = sin(ψ)/d gives β' = c²/(d cos(β)) and |β''| <= c²(2/cos(β) + c²/(d² cos³(β)))/d, and since R is a rotation,
+	// |F_xx| <= c, |F_xy| <= c(1 + |β'|), and |F_yy| <= c(1 + 2|β'|) + β'² + |β''|.
+	// Each bound increases with c, which is greatest where |y| is least.
+	c := 1 / math.Cosh(y)
+	cosBeta := math.Sqrt(1 - gm.dinv*gm.dinv)
+	dBeta := gm.dinv * c * c / cosBeta
+	ddBeta := gm.dinv * c * c * (2/cosBeta + gm.dinv*gm.dinv*c*c/(cosBeta*cosBeta*cosBeta))
+	fxx, fxy, fyy := c, c*(1+dBeta), c*(1+2*dBeta)+dBeta*dBeta+ddBeta
+	return fxx*v.X*v.X + 2*fxy*math.Abs(v.X*v.Y) + fyy*v.Y*v.Y
+}
+
+// minAbs returns the least magnitude of the numbers between a and b.
+func minAbs(a, b float64) float64 {
+	if (a < 0) != (b < 0) {
+		return 0
+	}
+	return math.Min(math.Abs(a), math.Abs(b))
+}
+
+// unscaled returns the point p before scaling and any output transformation.
+func (gm *GeneralizedMercator) unscaled(p r2.Point) r2.Point {
+	return gm.out.invert(p).Mul(1 / (gm.radius * gm.k0))
+}
+
+// tessellate returns a polyline that approximates the curve f on the reference sphere between the parameters t0 and t1
+// to within tolerance, as tested at the midpoint and quarter points of each part of the curve. Its vertices are points of the curve, chosen by adaptive subdivision of the parameter interval.
+func tessellate(f func(t float64) s2.Point, t0, t1 float64, tolerance s1.Angle) s2.Polyline {
+	A, B := f(t0), f(t1)
+	line := s2.Polyline{A}
+	line = subdivide(line, f, t0, t1, A, B, tolerance, maxSubdivisions)
+	return append(line, B)
+}
+
+// subdivide appends to line the interior vertices of the approximation of the curve f
+// between the parameters t0 and t1, at which the curve passes through A and B.
+func subdivide(line s2.Polyline, f func(t float64) s2.Point, t0, t1 float64, A, B s2.Point, tolerance s1.Angle, depth int) s2.Polyline {
+	if depth == 0 || len(line) >= maxPoints-1 {
+		return line
+	}
+	tm := (t0 + t1) / 2
+	M := f(tm)
+	// The curve is approximated by the edge AB if its midpoint and quarter points are close enough to the edge.
+	if s2.DistanceFromSegment(M, A, B) <= tolerance &&
+		s2.DistanceFromSegment(f((t0+tm)/2), A, B) <= tolerance &&
+		s2.DistanceFromSegment(f((tm+t1)/2), A, B) <= tolerance {
+		return line
+	}
+	line = subdivide(line, f, t0, tm, A, M, tolerance, depth-1)
+	if len(line) >= maxPoints-1 {
+		return line
+	}
+	line = append(line, M)
+	return subdivide(line, f, tm, t1, M, B, tolerance, depth-1)
+}
+
+// ProjectEdge returns the projections of points along the geodesic edge from a to b, beginning with the projection of a
+// and ending with the projection of b. The points are chosen adaptively so that the polyline through them deviates
+// from the projection of the edge by at most maxErr, which must be positive, measured as an angle on the reference sphere:
+// every point of the polyline unprojects to within maxErr of the edge, and every point of the edge is within maxErr
+// of the unprojection of the polyline. At most maxPoints points are returned; if that limit or the limit on the depth
+// of subdivision is reached first, the bound does not hold. It also does not hold where a truncated projection
+// clamps the edge to its limiting latitude.
+// An endpoint at a pole projects to a point at infinity, toward which the edge is subdivided until
+// the last finite point is within maxErr of the pole.
+// The edge should not cross the seam; ProjectPolyline splits edges that do.
+func (gm *GeneralizedMercator) ProjectEdge(a, b s2.Point, maxErr s1.Angle) []r2.Point {
+	_, pts := gm.flattenEdge(a, b, func(t float64) r2.Point { return gm.ProjectPoint(s2.Interpolate(t, a, b)) }, maxErr)
+	return pts
+}
+
+// flattenEdge returns parameters t in [0, 1], beginning with 0 and ending with 1, and the points eval(t),
+// where eval is a projection of the point s2.Interpolate(t, a, b) on the geodesic edge from a to b.
+// The parameters are chosen adaptively, as by ProjectEdge, so that the unprojection of the polyline through the points
+// is within maxErr of the edge.
+func (gm *GeneralizedMercator) flattenEdge(a, b s2.Point, eval func(t float64) r2.Point, maxErr s1.Angle) ([]float64, []r2.Point) {
+	p0, p1 := eval(0), eval(1)
+	ts, pts := []float64{0}, []r2.Point{p0}
+	ts, pts = gm.subdivideEdge(ts, pts, a, b, eval, 0, 1, p0, p1, maxErr, maxSubdivisions)
+	return append(ts, 1), append(pts, p1)
+}
+
+// subdivideEdge appends to ts and pts the interior parameters and points of the approximation of the projection
+// of the part of the edge from a to b between the parameters t0 and t1, whose projections are p0 and p1.
+func (gm *GeneralizedMercator) subdivideEdge(ts []float64, pts []r2.Point, a, b s2.Point, eval func(t float64) r2.Point, t0, t1 float64, p0, p1 r2.Point, maxErr s1.Angle, depth int) ([]float64, []r2.Point) {
+	if depth == 0 || len(pts) >= maxPoints-1 {
+		return ts, pts
+	}
+	if inf0, inf1 := !isFinite(p0), !isFinite(p1); inf0 || inf1 {
+		// The chord to the projection of a pole is infinitely long. The part of the edge nearer the pole than maxErr
+		// is left to it, and the rest is subdivided as usual.
+		if inf0 != inf1 && s2.Interpolate(t0, a, b).Distance(s2.Interpolate(t1, a, b)) <= maxErr {
+			return ts, pts
+		}
+	} else if gm.chordError(gm.unscaled(p0), gm.unscaled(p1), s2.Interpolate(t0, a, b), s2.Interpolate(t1, a, b)) <= maxErr {
+		return ts, pts
+	}
+	tm := (t0 + t1) / 2
+	pm := eval(tm)
+	ts, pts = gm.subdivideEdge(ts, pts, a, b, eval, t0, tm, p0, pm, maxErr, depth-1)
+	if len(pts) >= maxPoints-1 {
+		return ts, pts
+	}
+	ts, pts = append(ts, tm), append(pts, pm)
+	return gm.subdivideEdge(ts, pts, a, b, eval, tm, t1, pm, p1, maxErr, depth-1)
+}
+
+// isFinite reports whether both coordinates of p are finite.
+func isFinite(p r2.Point) bool {
+	return !math.IsInf(p.X, 0) && !math.IsInf(p.Y, 0) && !math.IsNaN(p.X) && !math.IsNaN(p.Y)
+}
+
+// Interpolate returns the point obtained by interpolating the given fraction of the distance along the straight line
+// from a to b in the plane. Fractions less than 0 or greater than 1 result in extrapolation instead.
+// As in s2.Projection, edges in the plane are straight lines, so Interpolate can be used to subdivide them.
+func (gm *GeneralizedMercator) Interpolate(f float64, a, b r2.Point) r2.Point {
+	return a.Mul(1 - f).Add(b.Mul(f))
+}
+
+// Densify returns a copy of line with vertices inserted along its geodesic edges so that no projected edge
+// is longer than maxLen, which must be positive, in projected units. The length of an edge that crosses the seam
+// is measured the shorter way around the projection, as by WrapDestination. Edges with an endpoint at a pole,
+// whose projections are infinitely long, are not subdivided.
+func (gm *GeneralizedMercator) Densify(line s2.Polyline, maxLen float64) s2.Polyline {
+	if len(line) == 0 {
+		return nil
+	}
+	out := s2.Polyline{line[0]}
+	for n := 1; n < len(line); n++ {
+		a, b := line[n-1], line[n]
+		out = gm.densify(out, a, b, gm.ProjectPoint(a), gm.ProjectPoint(b), maxLen, maxSubdivisions)
+		out = append(out, b)
+	}
+	return out
+}
+
+// densify appends to line the vertices inserted between a and b, whose projections are pa and pb.
+func (gm *GeneralizedMercator) densify(line s2.Polyline, a, b s2.Point, pa, pb r2.Point, maxLen float64, depth int) s2.Polyline {
+	if depth == 0 || !isFinite(pa) || !isFinite(pb) || gm.WrapDestination(pa, pb).Sub(pa).Norm() <= maxLen {
+		return line
+	}
+	m := s2.Interpolate(0.5, a, b)
+	pm := gm.ProjectPoint(m)
+	line = gm.densify(line, a, m, pa, pm, maxLen, depth-1)
+	line = append(line, m)
+	return gm.densify(line, m, b, pm, pb, maxLen, depth-1)
+}
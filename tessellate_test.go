@@ -0,0 +1,187 @@
+package gm
+
+import (
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s2"
+)
+
+func TestUnprojectSegment(t *testing.T) {
+	const tolerance = 1e-4
+	for _, test := range projTests {
+		gm := New(test.gm.Poles())
+		for _, seg := range [][2]r2.Point{
+			{{X: -3, Y: -1}, {X: 3, Y: 1}},
+			{{X: 0, Y: -2}, {X: 0, Y: 2}},
+			{{X: -1, Y: 0.5}, {X: 2, Y: 0.5}},
+			{{X: 0.1, Y: 0.1}, {X: 0.1, Y: 0.1}},
+		} {
+			a, b := seg[0], seg[1]
+			line := gm.UnprojectSegment(a, b, tolerance)
+			if !line[0].ApproxEqual(gm.UnprojectPoint(a)) || !line[len(line)-1].ApproxEqual(gm.UnprojectPoint(b)) {
+				t.Errorf("UnprojectSegment(%v, %v, %v): got endpoints %v, %v", gm, a, b, line[0], line[len(line)-1])
+			}
+			// Every point of the segment, not only those at which it was subdivided, unprojects to near the polyline.
+			for n := 0; n <= 10000; n++ {
+				f := float64(n) / 10000
+				P := gm.UnprojectPoint(a.Mul(1 - f).Add(b.Mul(f)))
+				if d := distanceFromPolyline(P, line); d > tolerance {
+					t.Errorf("UnprojectSegment(%v, %v, %v): unprojection of point %v is %v from the polyline", gm, a, b, f, d)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestProjectEdge(t *testing.T) {
+	const maxErr = 1e-4
+	for _, test := range projTests {
+		gm := New(test.gm.Poles())
+		for _, e := range [][2]s2.LatLng{
+			{s2.LatLngFromDegrees(10, 20), s2.LatLngFromDegrees(-15, 60)},
+			{s2.LatLngFromDegrees(70, -30), s2.LatLngFromDegrees(65, 40)},
+			{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 0)},
+		} {
+			a, b := s2.PointFromLatLng(e[0]), s2.PointFromLatLng(e[1])
+			if _, ok := gm.seamCrossing(a, b); ok {
+				continue
+			}
+			pts := gm.ProjectEdge(a, b, maxErr)
+			if !ptApproxEqual(pts[0], gm.ProjectPoint(a)) || !ptApproxEqual(pts[len(pts)-1], gm.ProjectPoint(b)) {
+				t.Errorf("ProjectEdge(%v, %v, %v): got endpoints %v, %v", gm, e[0], e[1], pts[0], pts[len(pts)-1])
+			}
+			// Every point of the projected polyline, sampled densely along each chord, unprojects to near the edge.
+			for n := 1; n < len(pts); n++ {
+				for k := 0; k <= 64; k++ {
+					f := float64(k) / 64
+					p := pts[n-1].Mul(1 - f).Add(pts[n].Mul(f))
+					if d := s2.DistanceFromSegment(gm.UnprojectPoint(p), a, b); d > maxErr {
+						t.Errorf("ProjectEdge(%v, %v, %v): point %v is %v from the edge", gm, e[0], e[1], p, d)
+					}
+				}
+			}
+			// Every point of the edge is near the unprojection of the polyline, sampled as densely.
+			line := s2.Polyline{gm.UnprojectPoint(pts[0])}
+			for n := 1; n < len(pts); n++ {
+				for k := 1; k <= 64; k++ {
+					line = append(line, gm.UnprojectPoint(gm.Interpolate(float64(k)/64, pts[n-1], pts[n])))
+				}
+			}
+			for k := 0; k <= 1000; k++ {
+				P := s2.Interpolate(float64(k)/1000, a, b)
+				if d := distanceFromPolyline(P, line); d > maxErr {
+					t.Errorf("ProjectEdge(%v, %v, %v): edge point %v is %v from the polyline", gm, e[0], e[1], P, d)
+					break
+				}
+			}
+		}
+	}
+
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	a, b := s2.PointFromLatLng(s2.LatLngFromDegrees(-80, 30)), s2.PointFromLatLng(s2.LatLngFromDegrees(80, 30))
+
+	// An edge ending at a pole is subdivided toward it until the last finite point is within maxErr of the pole.
+	for _, gm := range []*GeneralizedMercator{gm, New(s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2))} {
+		pos, neg := gm.PolePoints()
+		for _, e := range [][2]s2.Point{{a, pos}, {neg, a}} {
+			pts := gm.ProjectEdge(e[0], e[1], maxErr)
+			var finite []r2.Point
+			for _, p := range pts {
+				if isFinite(p) {
+					finite = append(finite, p)
+				}
+			}
+			if len(finite) != len(pts)-1 || len(pts) > 1000 {
+				t.Errorf("ProjectEdge(%v, %v, %v): got %d points, %d of them finite", gm, e[0], e[1], len(pts), len(finite))
+				continue
+			}
+			pole, last := e[1], finite[len(finite)-1]
+			if isFinite(pts[0]) {
+				pole, last = e[0], finite[0]
+			}
+			if d := gm.UnprojectPoint(last).Distance(pole); d > maxErr {
+				t.Errorf("ProjectEdge(%v, %v, %v): last finite point %v is %v from the pole", gm, e[0], e[1], last, d)
+			}
+			for n := 1; n < len(finite); n++ {
+				p := finite[n-1].Add(finite[n]).Mul(0.5)
+				if d := s2.DistanceFromSegment(gm.UnprojectPoint(p), e[0], e[1]); d > 1.01*maxErr {
+					t.Errorf("ProjectEdge(%v, %v, %v): point %v is %v from the edge", gm, e[0], e[1], p, d)
+				}
+			}
+		}
+	}
+
+	// The number of points is limited however small maxErr is.
+	gm = New(s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2))
+	a, b = s2.PointFromLatLng(s2.LatLngFromDegrees(40, -60)), s2.PointFromLatLng(s2.LatLngFromDegrees(-20, 100))
+	if got := gm.ProjectEdge(a, b, 1e-300); len(got) > maxPoints {
+		t.Errorf("ProjectEdge(%v, %v, %v, 1e-300): got %d points, want at most %d", gm, a, b, len(got), maxPoints)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	gm := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	a, b := r2.Point{X: 1, Y: -2}, r2.Point{X: 3, Y: 4}
+	for _, test := range []struct {
+		f    float64
+		want r2.Point
+	}{
+		{0, a},
+		{1, b},
+		{0.5, r2.Point{X: 2, Y: 1}},
+		{0.25, r2.Point{X: 1.5, Y: -0.5}},
+		{-1, r2.Point{X: -1, Y: -8}},
+		{2, r2.Point{X: 5, Y: 10}},
+	} {
+		if got := gm.Interpolate(test.f, a, b); !ptApproxEqual(got, test.want) {
+			t.Errorf("Interpolate(%v, %v, %v): got %v, want %v", test.f, a, b, got, test.want)
+		}
+	}
+}
+
+func TestDensify(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	if got := mercator.Densify(nil, 0.1); got != nil {
+		t.Errorf("Densify(%v, nil): got %v, want nil", mercator, got)
+	}
+	for _, test := range projTests {
+		pos, neg := test.gm.Poles()
+		gm := New(pos, neg, WithRadius(2))
+		for _, line := range []s2.Polyline{
+			polyline([2]float64{10, 20}, [2]float64{-15, 60}, [2]float64{30, 100}),
+			polyline([2]float64{0, 170}, [2]float64{0, -170}),
+			polyline([2]float64{45, 45}),
+		} {
+			const maxLen = 0.05
+			got := gm.Densify(line, maxLen)
+			if len(got) < len(line) {
+				t.Errorf("Densify(%v, %v): got %v", gm, line, got)
+				continue
+			}
+			// The original vertices are retained in order, and the inserted vertices lie on the original edges.
+			m := 0
+			for n, v := range got {
+				if m < len(line) && v == line[m] {
+					m++
+					continue
+				}
+				if m == 0 || m == len(line) || s2.DistanceFromSegment(v, line[m-1], line[m]) > 1e-14 {
+					t.Errorf("Densify(%v, %v): vertex %d %v is not on an edge", gm, line, n, v)
+				}
+			}
+			if m != len(line) {
+				t.Errorf("Densify(%v, %v): got %v, missing original vertices", gm, line, got)
+			}
+			for n := 1; n < len(got); n++ {
+				pa, pb := gm.ProjectPoint(got[n-1]), gm.ProjectPoint(got[n])
+				if isFinite(pa) && isFinite(pb) {
+					if d := gm.WrapDestination(pa, pb).Sub(pa).Norm(); d > maxLen {
+						t.Errorf("Densify(%v, %v): projected edge %d has length %v", gm, line, n, d)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A transverseFrame relates the basis of a transverse projection, whose k axis lies on the Equator, to the
+// latitude φ and the longitude λ of a location, so that its generalized coordinates follow from the spherical
+// transverse Mercator formulas without first converting it to a point. Relative to the longitude λk of the k axis,
+// a location has coordinates cos(φ)sin(λ-λk) east, sin(φ) north, and cos(φ)cos(λ-λk) along the k axis,
+// and the i and j axes lie in the plane of the first two.
+type transverseFrame struct {
+	// ok reports whether the frame is in use.
+	ok bool
+
+	// lng is the longitude of the k axis.
+	lng float64
+
+	// ie and in are the east and north components of the i axis, and je and jn those of the j axis.
+	ie, in, je, jn float64
+
+	// tol is the tolerance of the projection, within which a location's east and north coordinates
+	// must both vanish for it to be taken to be a pole.
+	tol float64
+}
+
+// newTransverseFrame returns the frame for the basis (i, j, k), where k lies on the Equator,
+// of a projection with tolerance tol.
+func newTransverseFrame(i, j, k r3.Vector, tol float64) transverseFrame {
+	lng := math.Atan2(k.Y, k.X)
+	east := r3.Vector{X: -math.Sin(lng), Y: math.Cos(lng)}
+	return transverseFrame{ok: true, lng: lng, ie: i.Dot(east), in: i.Z, je: j.Dot(east), jn: j.Z, tol: tol}
+}
+
+// generalized returns the projective longitude x, measured from the i axis, and the generalized latitude ψ
+// of the location at latitude lat on the reference sphere and longitude lng.
+func (t transverseFrame) generalized(lat, lng s1.Angle) (x, psi float64) {
+	sinLat, cosLat := math.Sincos(float64(lat))
+	sinLng, cosLng := math.Sincos(float64(lng) - t.lng)
+	e, n := cosLat*sinLng, sinLat
+	if e == 0 && n == 0 || math.Abs(e) < t.tol && math.Abs(n) < t.tol {
+		// The location is a pole.
+		return 0, math.Copysign(math.Pi/2, cosLng)
+	}
+	a, b := t.ie*e+t.in*n, t.je*e+t.jn*n
+	return math.Atan2(b, a), math.Atan2(cosLat*cosLng, math.Hypot(a, b))
+}
+
+// fromParallel returns the location on the reference sphere with projective longitude x, measured from the i axis,
+// on the parallel pl. It is the inverse of generalized.
+func (t transverseFrame) fromParallel(pl parallel, x float64) s2.LatLng {
+	sinX, cosX := math.Sincos(x)
+	a, b := pl.cosPsi*cosX, pl.cosPsi*sinX
+	e, n := t.ie*a+t.je*b, t.in*a+t.jn*b
+	return s2.LatLng{
+		Lat: s1.Angle(math.Atan2(n, math.Hypot(e, pl.sinPsi))),
+		Lng: s1.Angle(math.Remainder(t.lng+math.Atan2(e, pl.sinPsi), 2*math.Pi)),
+	}
+}
@@ -0,0 +1,73 @@
+package gm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s2"
+)
+
+// A location is valid if its latitude is in [-π/2, π/2] and its longitude is in [-π, π], as reported by
+// s2.LatLng.IsValid. The projection operations take the latitude and longitude of an invalid location to be angles
+// like any others, by default: longitudes differing by a multiple of 2π are the same, and a latitude beyond a pole
+// continues over it, so that (100°, 0°) is the location (80°, 180°). This differs from s2.LatLng.Normalized,
+// which clamps the latitude to the pole, so a LatLngPolicy set by WithLatLngPolicy can select that interpretation
+// instead, or reject invalid locations.
+
+// A LatLngPolicy determines how the projection operations treat invalid locations.
+type LatLngPolicy int
+
+const (
+	// LatLngAsGiven takes the latitude and longitude of an invalid location as given, in every aspect
+	// of the projection: a latitude beyond a pole continues over it, so that (100°, 0°) projects as (80°, 180°) does.
+	LatLngAsGiven LatLngPolicy = iota
+
+	// LatLngNormalized normalizes invalid locations as s2.LatLng.Normalized does, clamping the latitude
+	// to [-π/2, π/2] and reducing the longitude to [-π, π].
+	LatLngNormalized
+
+	// LatLngRejected rejects invalid locations: projection operations return NaN coordinates for them,
+	// TryProject returns ErrInvalidLatLng, and constructors return or panic with ErrInvalidLatLng
+	// if a location that defines the projection is invalid.
+	LatLngRejected
+)
+
+// ErrInvalidLatLng is returned for locations that are rejected by the policy LatLngRejected.
+var ErrInvalidLatLng = errors.New("gm: invalid location")
+
+// location applies the LatLngPolicy of gm to ll, returning false if the policy rejects it.
+func (gm *GeneralizedMercator) location(ll s2.LatLng) (s2.LatLng, bool) {
+	switch gm.policy {
+	case LatLngNormalized:
+		if !ll.IsValid() {
+			return ll.Normalized(), true
+		}
+	case LatLngRejected:
+		return ll, ll.IsValid()
+	}
+	return ll, true
+}
+
+// checkLocations returns an error wrapping ErrInvalidLatLng if the LatLngPolicy of gm rejects any of lls.
+func (gm *GeneralizedMercator) checkLocations(lls ...s2.LatLng) error {
+	for _, ll := range lls {
+		if _, ok := gm.location(ll); !ok {
+			return fmt.Errorf("%w %v", ErrInvalidLatLng, ll)
+		}
+	}
+	return nil
+}
+
+// nanPoint is the projection of a rejected location.
+var nanPoint = r2.Point{X: math.NaN(), Y: math.NaN()}
+
+// TryProject is like Project, but returns an error wrapping ErrInvalidLatLng if the LatLngPolicy of gm
+// rejects ll, instead of a point with NaN coordinates.
+func (gm *GeneralizedMercator) TryProject(ll s2.LatLng) (r2.Point, error) {
+	if err := gm.checkLocations(ll); err != nil {
+		return r2.Point{}, err
+	}
+	return gm.Project(ll), nil
+}
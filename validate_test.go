@@ -0,0 +1,78 @@
+package gm
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestLatLngPolicy(t *testing.T) {
+	pos, neg := s2.LatLngFromDegrees(48.2, 16.4), s2.LatLngFromDegrees(-33.9, 151.2)
+	for _, test := range []struct {
+		ll      s2.LatLng
+		asGiven s2.LatLng // the location that ll is taken to be as given
+		normal  s2.LatLng // the location that ll is taken to be once normalized
+	}{
+		{s2.LatLngFromDegrees(100, 20), s2.LatLngFromDegrees(80, -160), s2.LatLngFromDegrees(90, 20)},
+		{s2.LatLngFromDegrees(-95, -30), s2.LatLngFromDegrees(-85, 150), s2.LatLngFromDegrees(-90, -30)},
+		{s2.LatLngFromDegrees(10, 370), s2.LatLngFromDegrees(10, 10), s2.LatLngFromDegrees(10, 10)},
+		{s2.LatLngFromDegrees(120, -200), s2.LatLngFromDegrees(60, -20), s2.LatLngFromDegrees(90, 160)},
+	} {
+		// The normal and transverse aspects of the projection are computed separately from the oblique aspect.
+		for _, poles := range [][2]s2.LatLng{
+			{pos, neg},
+			{s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0)},
+			{s2.LatLngFromDegrees(0, 30), s2.LatLngFromDegrees(0, -150)},
+		} {
+			for _, c := range []struct {
+				policy LatLngPolicy
+				want   s2.LatLng
+			}{
+				{LatLngAsGiven, test.asGiven},
+				{LatLngNormalized, test.normal},
+			} {
+				gm := New(poles[0], poles[1], WithLatLngPolicy(c.policy))
+				got, want := gm.Project(test.ll), gm.Project(c.want)
+				if !(math.Abs(got.X-want.X) <= 1e-12 && math.Abs(got.Y-want.Y) <= 1e-12) && got != want {
+					t.Errorf("Project(%v, %v): got %v, want %v", gm, test.ll, got, want)
+				}
+				if p, err := gm.TryProject(test.ll); err != nil || p != got {
+					t.Errorf("TryProject(%v, %v): got %v, %v, want %v, nil", gm, test.ll, p, err, got)
+				}
+			}
+		}
+
+		gm := New(pos, neg, WithLatLngPolicy(LatLngRejected))
+		if got := gm.Project(test.ll); !math.IsNaN(got.X) || !math.IsNaN(got.Y) {
+			t.Errorf("Project(%v, %v): got %v, want NaN", gm, test.ll, got)
+		}
+		if _, err := gm.TryProject(test.ll); !errors.Is(err, ErrInvalidLatLng) {
+			t.Errorf("TryProject(%v, %v): got error %v, want %v", gm, test.ll, err, ErrInvalidLatLng)
+		}
+		if got := gm.AreaScale(test.ll); !math.IsNaN(got) {
+			t.Errorf("AreaScale(%v, %v): got %v, want NaN", gm, test.ll, got)
+		}
+		if _, err := TryNew(test.ll, neg, WithLatLngPolicy(LatLngRejected)); !errors.Is(err, ErrInvalidLatLng) {
+			t.Errorf("TryNew(%v, %v, WithLatLngPolicy(LatLngRejected)): got error %v, want %v", test.ll, neg, err, ErrInvalidLatLng)
+		}
+		if _, err := TryNew(test.ll, neg); err != nil {
+			t.Errorf("TryNew(%v, %v): got error %v", test.ll, neg, err)
+		}
+	}
+
+	// Valid locations are unaffected by the policy.
+	ll := s2.LatLngFromDegrees(10, 20)
+	for _, policy := range []LatLngPolicy{LatLngNormalized, LatLngRejected} {
+		if got, want := New(pos, neg, WithLatLngPolicy(policy)).Project(ll), New(pos, neg).Project(ll); got != want {
+			t.Errorf("Project(%v) with policy %v: got %v, want %v", ll, policy, got, want)
+		}
+	}
+
+	for _, policy := range []LatLngPolicy{-1, 3} {
+		if _, err := TryNew(pos, neg, WithLatLngPolicy(policy)); err == nil {
+			t.Errorf("WithLatLngPolicy(%v): got nil error", policy)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package gm
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// A Viewport is a view of a projected map on a screen: the rectangle of the plane that is displayed
+// and the dimensions in pixels of the image that displays it. Pixel coordinates are measured from
+// the top left corner of the image, with y increasing downward.
+type Viewport struct {
+	Rect          r2.Rect
+	Width, Height float64
+}
+
+// Pixel returns the pixel coordinates at which v displays the projected point p.
+func (v Viewport) Pixel(p r2.Point) r2.Point {
+	return r2.Point{
+		X: (p.X - v.Rect.X.Lo) / v.Rect.X.Length() * v.Width,
+		Y: (v.Rect.Y.Hi - p.Y) / v.Rect.Y.Length() * v.Height,
+	}
+}
+
+// Point returns the projected point that v displays at the pixel coordinates px.
+func (v Viewport) Point(px r2.Point) r2.Point {
+	return r2.Point{
+		X: v.Rect.X.Lo + px.X/v.Width*v.Rect.X.Length(),
+		Y: v.Rect.Y.Hi - px.Y/v.Height*v.Rect.Y.Length(),
+	}
+}
+
+// pixelDirections is the number of directions in which PixelAngle and AnglePixels measure distances.
+const pixelDirections = 16
+
+// PixelAngle returns the greatest angular distance on the reference sphere from the location displayed at
+// the pixel coordinates px in v to any of the locations displayed at a distance of pixels from px,
+// so that a click within pixels of the display of a location is within the returned angle of the location.
+// The distances are measured in 16 directions from px. Since the scale of the projection varies,
+// the angle depends on px. PixelAngle returns +Inf if px displays a pole.
+func (gm *GeneralizedMercator) PixelAngle(v Viewport, px r2.Point, pixels float64) s1.Angle {
+	P := gm.UnprojectPoint(v.Point(px))
+	if gm.near(P.Vector, gm.pos) || gm.near(P.Vector, gm.neg) {
+		return s1.InfAngle()
+	}
+	var max s1.Angle
+	for n := 0; n < pixelDirections; n++ {
+		sin, cos := math.Sincos(2 * math.Pi * float64(n) / pixelDirections)
+		Q := gm.UnprojectPoint(v.Point(r2.Point{X: px.X + pixels*cos, Y: px.Y + pixels*sin}))
+		if a := P.Distance(Q); a > max {
+			max = a
+		}
+	}
+	return max
+}
+
+// AnglePixels returns the least distance in pixels between the display in v of the location at the pixel
+// coordinates px and the displays of the locations at angular distance angle from it on the reference sphere,
+// measured in 16 directions, so that a location displayed within the returned distance of px is within angle
+// of the location at px. It is the inverse of PixelAngle where the projection is conformal and the viewport's
+// pixels are square, so that the scale of the map at px is the same in every direction.
+// AnglePixels returns 0 if px displays a pole.
+func (gm *GeneralizedMercator) AnglePixels(v Viewport, px r2.Point, angle s1.Angle) float64 {
+	P := gm.UnprojectPoint(v.Point(px))
+	if gm.near(P.Vector, gm.pos) || gm.near(P.Vector, gm.neg) {
+		return 0
+	}
+	u := P.Ortho()
+	w := P.Cross(u)
+	sinA, cosA := math.Sincos(float64(angle))
+	min := math.Inf(1)
+	for n := 0; n < pixelDirections; n++ {
+		sin, cos := math.Sincos(2 * math.Pi * float64(n) / pixelDirections)
+		Q := s2.Point{P.Mul(cosA).Add(u.Mul(cos * sinA)).Add(w.Mul(sin * sinA)).Normalize()}
+		q := v.Pixel(gm.WrapDestination(v.Point(px), gm.ProjectPoint(Q)))
+		if d := q.Sub(px).Norm(); d < min {
+			min = d
+		}
+	}
+	return min
+}
@@ -0,0 +1,69 @@
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+func TestViewport(t *testing.T) {
+	v := Viewport{Rect: rect(-2, -1, 2, 3), Width: 800, Height: 400}
+	for _, test := range []struct {
+		p, px r2.Point
+	}{
+		{r2.Point{X: -2, Y: 3}, r2.Point{X: 0, Y: 0}},
+		{r2.Point{X: 2, Y: -1}, r2.Point{X: 800, Y: 400}},
+		{r2.Point{X: 0, Y: 1}, r2.Point{X: 400, Y: 200}},
+	} {
+		if got := v.Pixel(test.p); !ptApproxEqual(got, test.px) {
+			t.Errorf("Pixel(%v, %v): got %v, want %v", v, test.p, got, test.px)
+		}
+		if got := v.Point(test.px); !ptApproxEqual(got, test.p) {
+			t.Errorf("Point(%v, %v): got %v, want %v", v, test.px, got, test.p)
+		}
+	}
+}
+
+func TestPixelAngle(t *testing.T) {
+	mercator := New(s2.LatLngFromDegrees(90, 0), s2.LatLngFromDegrees(-90, 0))
+	v := Viewport{Rect: rect(-pi, -pi, pi, pi), Width: 100000, Height: 100000}
+	for _, test := range []struct {
+		px     r2.Point
+		pixels float64
+	}{
+		{r2.Point{X: 50000, Y: 50000}, 5},
+		{r2.Point{X: 30000, Y: 20000}, 5},
+		{r2.Point{X: 99999, Y: 90000}, 2},
+		{r2.Point{X: 0, Y: 50000}, 2},
+	} {
+		// The scale of the Mercator projection at latitude φ is sec φ.
+		lat := mercator.Unproject(v.Point(test.px)).Lat
+		want := s1.Angle(test.pixels * 2 * pi / 100000 * math.Cos(float64(lat)))
+		got := mercator.PixelAngle(v, test.px, test.pixels)
+		if math.Abs(float64(got-want)) > 1e-3*float64(want) {
+			t.Errorf("PixelAngle(%v, %v, %v): got %v, want %v", v, test.px, test.pixels, got, want)
+		}
+		if px := mercator.AnglePixels(v, test.px, got); math.Abs(px-test.pixels) > 1e-2*test.pixels {
+			t.Errorf("AnglePixels(%v, %v, %v): got %v, want %v", v, test.px, got, px, test.pixels)
+		}
+	}
+
+	// In a projection that is not conformal, PixelAngle bounds the distance to every location displayed within
+	// the given number of pixels, apart from the variation of the scale between the directions that it samples.
+	v = Viewport{Rect: rect(-pi, -pi, pi, pi), Width: 1000, Height: 1000}
+	gm := New(s2.LatLngFromDegrees(40, 20), s2.LatLngFromDegrees(-10, 150))
+	px := r2.Point{X: 600, Y: 450}
+	P := gm.UnprojectPoint(v.Point(px))
+	a := gm.PixelAngle(v, px, 10)
+	for n := 0; n < 100; n++ {
+		sin, cos := math.Sincos(2 * pi * float64(n) / 100)
+		Q := gm.UnprojectPoint(v.Point(r2.Point{X: px.X + 10*cos, Y: px.Y + 10*sin}))
+		if got := P.Distance(Q); got > a*1.01 {
+			t.Errorf("PixelAngle(%v, %v, 10): got %v, but a displayed point is at distance %v", v, px, a, got)
+		}
+	}
+
+}